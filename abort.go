@@ -0,0 +1,96 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrAborted is the cause of the context of a run aborted with AbortRun or WithAbortSignal.
+var ErrAborted = errors.New("scenario run aborted")
+
+// inFlightRun is a run registered for aborting.
+type inFlightRun struct {
+	scenarioID string
+	cancel     context.CancelCauseFunc
+}
+
+var (
+	inFlightRunsMu sync.Mutex
+	inFlightRuns   = map[string]inFlightRun{}
+)
+
+// registerRun registers the run for aborting and returns a context cancelled when it is
+// aborted, and a function to unregister the run once it is done.
+func registerRun(ctx context.Context, runID, scenarioID string, signal <-chan struct{}) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	inFlightRunsMu.Lock()
+	inFlightRuns[runID] = inFlightRun{scenarioID: scenarioID, cancel: cancel}
+	inFlightRunsMu.Unlock()
+	stats.Add("active_runs", 1)
+
+	if signal != nil {
+		go func() {
+			select {
+			case <-signal:
+				cancel(ErrAborted)
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return ctx, func() {
+		inFlightRunsMu.Lock()
+		delete(inFlightRuns, runID)
+		inFlightRunsMu.Unlock()
+		stats.Add("active_runs", -1)
+		cancel(nil)
+	}
+}
+
+// InFlightRuns returns the scenario IDs of the runs in flight in the process, keyed by run ID.
+func InFlightRuns() map[string]string {
+	inFlightRunsMu.Lock()
+	defer inFlightRunsMu.Unlock()
+
+	runs := make(map[string]string, len(inFlightRuns))
+	for runID, run := range inFlightRuns {
+		runs[runID] = run.scenarioID
+	}
+	return runs
+}
+
+// AbortRun aborts the in-flight run with the given run ID, e.g. from another goroutine of a
+// service embedding the runner. The run stops at the next step, cancelling the context of the
+// agent and testing agent calls, and returns its partial result with Aborted set. It returns
+// false when no run with the ID is in flight.
+func AbortRun(runID string) bool {
+	inFlightRunsMu.Lock()
+	run, ok := inFlightRuns[runID]
+	inFlightRunsMu.Unlock()
+
+	if ok {
+		run.cancel(ErrAborted)
+	}
+	return ok
+}
+
+// aborted reports whether the run of the context was aborted.
+func aborted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrAborted)
+}
+
+// abortedResult returns the partial result of an aborted run.
+func abortedResult(conversation []Message, turn int) *Result {
+	return &Result{
+		Success:           false,
+		Aborted:           true,
+		Conversation:      conversation,
+		Reasoning:         fmt.Sprintf("The run was aborted at turn %d.", turn),
+		MetCriteria:       []string{},
+		UnmetCriteria:     []string{},
+		TriggeredFailures: []string{},
+	}
+}
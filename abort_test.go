@@ -0,0 +1,80 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_AbortRun(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			runs := InFlightRuns()
+			require.Len(t, runs, 1)
+			for runID, scenarioID := range runs {
+				assert.Equal(t, "abort-run", scenarioID)
+				assert.True(t, AbortRun(runID))
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	mockTestingAgentInst := &mockTestingAgent{}
+
+	s := NewScenario(
+		WithID("abort-run"),
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.True(t, result.Aborted)
+	assert.NotEmpty(t, result.RunID)
+	assert.Len(t, result.Conversation, 1, "the partial conversation is kept")
+	assert.Empty(t, InFlightRuns(), "the run is unregistered once done")
+	assert.False(t, AbortRun(result.RunID))
+}
+
+func TestScenario_Run_WithAbortSignal(t *testing.T) {
+	ctx := context.Background()
+	signal := make(chan struct{})
+	turn := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			turn++
+			return []Message{{Role: MessageRoleAssistant, Content: "Hello"}}, nil
+		},
+	}
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if turn == 2 {
+				close(signal)
+				<-ctx.Done()
+				return nil, nil, ctx.Err()
+			}
+			msg := "hi"
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithAbortSignal(signal),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, result.Aborted)
+	assert.Equal(t, 2, turn)
+	assert.Len(t, result.Conversation, 4)
+	assert.Contains(t, result.Reasoning, "aborted at turn 2")
+}
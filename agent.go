@@ -7,3 +7,71 @@ type Agent interface {
 	// Run runs the agent.
 	Run(ctx context.Context, message string) ([]Message, error)
 }
+
+// EventAgent is an optional interface an Agent can implement to receive out-of-band
+// events (system notifications, webhooks, hand-offs) injected between turns with WithEventAt.
+type EventAgent interface {
+	Agent
+
+	// HandleEvent delivers an out-of-band event to the agent.
+	HandleEvent(ctx context.Context, event Message) error
+}
+
+// ResettableAgent is an optional interface for stateful agents. Reset is called before
+// every scenario run so state (e.g. conversation history) does not leak across runs.
+type ResettableAgent interface {
+	Agent
+
+	// Reset clears any state the agent accumulated during a previous run.
+	Reset(ctx context.Context) error
+}
+
+// FixtureLoader is an optional interface for agents whose state (user account, order history)
+// is seeded before a run. LoadFixtures is called after Reset with the fixtures declared with
+// WithFixture.
+type FixtureLoader interface {
+	Agent
+
+	// LoadFixtures seeds the agent with the fixtures, keyed by name. Values are plain data
+	// (maps, slices, strings, numbers and booleans) so they can be declared in any format.
+	LoadFixtures(ctx context.Context, fixtures map[string]any) error
+}
+
+// HistoryAgent is an optional interface for agents that can resume a conversation. LoadHistory
+// is called before the first turn with the conversation sampled with WithConversationSeed.
+type HistoryAgent interface {
+	Agent
+
+	// LoadHistory sets the conversation the agent continues from.
+	LoadHistory(ctx context.Context, history []Message) error
+}
+
+// HealthCheckAgent is an optional interface an Agent can implement to be checked by Preflight
+// before scenarios are run, e.g. by pinging the endpoint it talks to.
+type HealthCheckAgent interface {
+	Agent
+
+	// HealthCheck returns an error if the agent is not able to serve requests.
+	HealthCheck(ctx context.Context) error
+}
+
+// ArtifactProducer is an optional interface for agents with side effects (screenshots,
+// generated files, emails sent). Artifacts is called after every turn and the artifacts are
+// referenced from the transcript for judging and kept in Result.Artifacts for review.
+type ArtifactProducer interface {
+	Agent
+
+	// Artifacts returns the artifacts produced during the turn.
+	Artifacts(ctx context.Context, turn int) ([]Artifact, error)
+}
+
+// StreamingAgent is an optional interface for agents exposing a streaming API. When
+// implemented, the runner calls RunStream instead of Run, accumulates the chunks into the
+// messages of the turn and records the time to first token in Result.Turns.
+type StreamingAgent interface {
+	Agent
+
+	// RunStream runs the agent, streaming its response. The channel must be closed once the
+	// response is complete.
+	RunStream(ctx context.Context, message string) (<-chan MessageChunk, error)
+}
@@ -0,0 +1,115 @@
+package scenario
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// AggregateSummary is a summary of the results aggregated so far.
+type AggregateSummary struct {
+	// Total is the number of results expected, 0 if unknown.
+	Total int
+
+	// Completed is the number of results aggregated so far.
+	Completed int
+
+	// Passed is the number of successful results so far.
+	Passed int
+
+	// Failed is the number of unsuccessful results so far.
+	Failed int
+
+	// Elapsed is the time since the aggregator was created.
+	Elapsed time.Duration
+
+	// ETA is the estimated time until all the results are aggregated, 0 if unknown.
+	ETA time.Duration
+}
+
+// PassRate returns the ratio of successful results so far.
+func (s AggregateSummary) PassRate() float64 {
+	if s.Completed == 0 {
+		return 0
+	}
+	return float64(s.Passed) / float64(s.Completed)
+}
+
+// Aggregator aggregates results as they complete, e.g. from scenarios running in parallel,
+// serving intermediate summaries mid-run. It is safe for concurrent use.
+type Aggregator struct {
+	mu          sync.Mutex
+	total       int
+	start       time.Time
+	now         func() time.Time
+	results     []*Result
+	passed      int
+	subscribers []func(AggregateSummary)
+}
+
+// NewAggregator creates an aggregator expecting total results, 0 if unknown.
+func NewAggregator(total int) *Aggregator {
+	return &Aggregator{
+		total: total,
+		start: time.Now(),
+		now:   time.Now,
+	}
+}
+
+// Subscribe calls fn with the updated summary every time a result is added, e.g. to report
+// progress. fn is called from the goroutine adding the result, without holding the lock of the
+// aggregator, so it can call its methods, and must be safe for concurrent use when results are
+// added concurrently.
+func (a *Aggregator) Subscribe(fn func(AggregateSummary)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.subscribers = append(a.subscribers, fn)
+}
+
+// Add aggregates a result.
+func (a *Aggregator) Add(result *Result) {
+	a.mu.Lock()
+	a.results = append(a.results, result)
+	if result.Success {
+		a.passed++
+	}
+	summary := a.summary()
+	subscribers := slices.Clone(a.subscribers)
+	a.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(summary)
+	}
+}
+
+// Summary returns the summary of the results aggregated so far.
+func (a *Aggregator) Summary() AggregateSummary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.summary()
+}
+
+// Results returns the results aggregated so far, in completion order.
+func (a *Aggregator) Results() []*Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]*Result{}, a.results...)
+}
+
+func (a *Aggregator) summary() AggregateSummary {
+	summary := AggregateSummary{
+		Total:     a.total,
+		Completed: len(a.results),
+		Passed:    a.passed,
+		Failed:    len(a.results) - a.passed,
+		Elapsed:   a.now().Sub(a.start),
+	}
+	if summary.Completed > 0 && summary.Total > summary.Completed {
+		summary.ETA = summary.Elapsed / time.Duration(summary.Completed) * time.Duration(summary.Total-summary.Completed)
+	}
+
+	return summary
+}
@@ -0,0 +1,74 @@
+package scenario
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregator(t *testing.T) {
+	aggregator := NewAggregator(4)
+	now := aggregator.start
+	aggregator.now = func() time.Time { return now }
+
+	var summaries []AggregateSummary
+	aggregator.Subscribe(func(summary AggregateSummary) {
+		summaries = append(summaries, summary)
+	})
+
+	now = now.Add(10 * time.Second)
+	aggregator.Add(&Result{Success: true})
+	now = now.Add(10 * time.Second)
+	aggregator.Add(&Result{Success: false})
+
+	assert.Equal(t, []AggregateSummary{
+		{Total: 4, Completed: 1, Passed: 1, Elapsed: 10 * time.Second, ETA: 30 * time.Second},
+		{Total: 4, Completed: 2, Passed: 1, Failed: 1, Elapsed: 20 * time.Second, ETA: 20 * time.Second},
+	}, summaries)
+	assert.Equal(t, 0.5, aggregator.Summary().PassRate())
+	assert.Len(t, aggregator.Results(), 2)
+}
+
+func TestAggregator_Concurrent(t *testing.T) {
+	aggregator := NewAggregator(0)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			aggregator.Add(&Result{Success: i%2 == 0})
+			_ = aggregator.Summary()
+		}()
+	}
+	wg.Wait()
+
+	summary := aggregator.Summary()
+	assert.Equal(t, 50, summary.Completed)
+	assert.Equal(t, 25, summary.Passed)
+	assert.Zero(t, summary.ETA, "the ETA is unknown without a total")
+}
+
+func TestAggregator_SubscriberCallsAggregator(t *testing.T) {
+	aggregator := NewAggregator(2)
+
+	var completed []int
+	aggregator.Subscribe(func(summary AggregateSummary) {
+		completed = append(completed, aggregator.Summary().Completed)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		aggregator.Add(&Result{Success: true})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("subscriber calling the aggregator deadlocked")
+	}
+
+	assert.Equal(t, []int{1}, completed)
+}
@@ -0,0 +1,95 @@
+package scenario
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultArtifactTextLimit is the default number of bytes of each text artifact shown to the
+// testing agent, see WithArtifactTextLimit.
+const defaultArtifactTextLimit = 2000
+
+// Artifact is a side effect of the agent captured during a turn, see ArtifactProducer.
+type Artifact struct {
+	// Turn is the zero-based turn the artifact was produced at, set by the runner.
+	Turn int `json:"turn"`
+
+	// Name is the file name of the artifact, e.g. "checkout.png".
+	Name string `json:"name"`
+
+	// ContentType is the media type of the artifact, e.g. "image/png".
+	ContentType string `json:"content_type"`
+
+	// Data is the content of the artifact.
+	Data []byte `json:"data"`
+}
+
+// Path returns the path of the artifact relative to the run directory, see Result.WriteArtifacts.
+func (a Artifact) Path() string {
+	return filepath.Join("artifacts", fmt.Sprintf("turn-%d-%s", a.Turn, filepath.Base(a.Name)))
+}
+
+// artifactsMessage returns the message referencing the artifacts of a turn in the transcript.
+// The text content of text artifacts is inlined up to limit bytes so the testing agent can
+// judge criteria about them.
+func artifactsMessage(turn int, artifacts []Artifact, limit int) Message {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The agent produced %d artifacts during turn %d:", len(artifacts), turn)
+	for _, artifact := range artifacts {
+		fmt.Fprintf(&sb, "\n- %s (%s, %d bytes)", filepath.ToSlash(artifact.Path()), artifact.ContentType, len(artifact.Data))
+		if limit <= 0 || !isTextArtifact(artifact) {
+			continue
+		}
+
+		text := artifact.Data
+		truncated := len(text) > limit
+		if truncated {
+			text = text[:limit]
+			for len(text) > 0 && !utf8.Valid(text) {
+				text = text[:len(text)-1]
+			}
+		}
+		fmt.Fprintf(&sb, ":\n%s", text)
+		if truncated {
+			fmt.Fprintf(&sb, "\n[%d bytes truncated]", len(artifact.Data)-len(text))
+		}
+	}
+
+	return Message{Role: MessageRoleDeveloper, Content: sb.String()}
+}
+
+// isTextArtifact reports whether the artifact is text the testing agent can read, such as
+// plain text, HTML, JSON or an email.
+func isTextArtifact(artifact Artifact) bool {
+	contentType, _, _ := strings.Cut(artifact.ContentType, ";")
+	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	textual := strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/json" || strings.HasSuffix(contentType, "+json") ||
+		contentType == "application/xml" || strings.HasSuffix(contentType, "+xml") ||
+		contentType == "message/rfc822"
+
+	return textual && utf8.Valid(artifact.Data)
+}
+
+// WriteArtifacts writes the artifacts of the result to the run directory under base, at the
+// paths referenced from the transcript.
+func (r *Result) WriteArtifacts(base string) error {
+	return r.StoreArtifacts(NewDirBlobStore(base))
+}
+
+// StoreArtifacts stores the artifacts of the result in the blob store, at
+// <scenario id>/<run id>/<path> keys laid out like WriteArtifacts, e.g. to keep them in object
+// storage.
+func (r *Result) StoreArtifacts(store BlobStore) error {
+	for _, artifact := range r.Artifacts {
+		key := path.Join(r.ScenarioID, r.RunID, filepath.ToSlash(artifact.Path()))
+		if err := store.Put(key, artifact.Data); err != nil {
+			return fmt.Errorf("failed to write artifact %s: %w", artifact.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockArtifactProducer is a mock implementation of the ArtifactProducer interface.
+type mockArtifactProducer struct {
+	mockAgent
+	artifacts map[int][]Artifact
+}
+
+func (m *mockArtifactProducer) Artifacts(ctx context.Context, turn int) ([]Artifact, error) {
+	return m.artifacts[turn], nil
+}
+
+func TestScenario_Run_Artifacts(t *testing.T) {
+	ctx := context.Background()
+	agent := &mockArtifactProducer{artifacts: map[int][]Artifact{
+		1: {
+			{Name: "checkout.png", ContentType: "image/png", Data: []byte{0x89, 'P', 'N', 'G'}},
+			{Name: "confirmation.txt", ContentType: "text/plain", Data: []byte("Your order A-42 is confirmed.")},
+		},
+	}}
+	var judged []Message
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			judged = conversation
+			msg := "next"
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithID("artifacts"),
+		WithAgent(agent),
+		WithTestingAgent(mockTestingAgentInst),
+		WithMaxTurns(2),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, result.Artifacts, 2)
+	assert.Equal(t, 1, result.Artifacts[0].Turn)
+	assert.Equal(t, filepath.Join("artifacts", "turn-1-checkout.png"), result.Artifacts[0].Path())
+	require.Len(t, judged, 5, "the artifacts are referenced after the agent messages of their turn")
+	assert.Equal(t, MessageRoleDeveloper, judged[4].Role)
+	assert.Equal(t, "The agent produced 2 artifacts during turn 1:\n"+
+		"- artifacts/turn-1-checkout.png (image/png, 4 bytes)\n"+
+		"- artifacts/turn-1-confirmation.txt (text/plain, 29 bytes):\nYour order A-42 is confirmed.", judged[4].Content)
+
+	base := t.TempDir()
+	require.NoError(t, result.WriteArtifacts(base))
+	data, err := os.ReadFile(filepath.Join(result.RunDir(base), "artifacts", "turn-1-confirmation.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "Your order A-42 is confirmed.", string(data))
+}
+
+func TestArtifactsMessage(t *testing.T) {
+	artifacts := []Artifact{
+		{Turn: 0, Name: "email.eml", ContentType: "message/rfc822", Data: []byte("Subject: Refund\n\nWe refunded 42.00 EUR.")},
+		{Turn: 0, Name: "receipt.json", ContentType: "application/json; charset=utf-8", Data: []byte(`{"amount": 42}`)},
+		{Turn: 0, Name: "photo.jpg", ContentType: "image/jpeg", Data: []byte{0xff, 0xd8}},
+	}
+
+	assert.Equal(t, "The agent produced 3 artifacts during turn 0:\n"+
+		"- artifacts/turn-0-email.eml (message/rfc822, 39 bytes):\nSubject: Refund\n[24 bytes truncated]\n"+
+		"- artifacts/turn-0-receipt.json (application/json; charset=utf-8, 14 bytes):\n{\"amount\": 42}\n"+
+		"- artifacts/turn-0-photo.jpg (image/jpeg, 2 bytes)", artifactsMessage(0, artifacts, 15).Content)
+	assert.Equal(t, "The agent produced 1 artifacts during turn 0:\n"+
+		"- artifacts/turn-0-receipt.json (application/json; charset=utf-8, 14 bytes)", artifactsMessage(0, artifacts[1:2], 0).Content)
+}
@@ -0,0 +1,147 @@
+package scenario
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// AuditEntry is the record of an outbound call made during a scenario run.
+type AuditEntry struct {
+	// Time is when the call started.
+	Time time.Time `json:"time"`
+
+	// Provider is the provider the call was made to, e.g. "openai".
+	Provider string `json:"provider"`
+
+	// Endpoint is the endpoint the call was made to.
+	Endpoint string `json:"endpoint"`
+
+	// Model is the model requested in the call.
+	Model string `json:"model"`
+
+	// PromptTokens is the number of tokens in the prompt.
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CompletionTokens is the number of tokens in the completion.
+	CompletionTokens int64 `json:"completion_tokens"`
+
+	// Duration is the duration of the call.
+	Duration time.Duration `json:"duration_ns"`
+
+	// PayloadSHA256 is the hex encoded SHA-256 hash of the request payload.
+	PayloadSHA256 string `json:"payload_sha256"`
+
+	// Error is the error returned by the call, if any.
+	Error string `json:"error,omitempty"`
+
+	// Component is the component of the run the call was made for, set from the context.
+	Component Component `json:"component,omitempty"`
+
+	// Turn is the turn of the run the call was made at, set from the context.
+	Turn int `json:"turn"`
+}
+
+// Component is a component of a scenario run making outbound calls, used to attribute usage.
+type Component string
+
+const (
+	// ComponentAgent is the agent under test, when it records its calls.
+	ComponentAgent Component = "agent"
+
+	// ComponentSimulator is the testing agent generating the messages of the simulated user.
+	ComponentSimulator Component = "simulator"
+
+	// ComponentJudge is the testing agent giving a verdict.
+	ComponentJudge Component = "judge"
+
+	// ComponentSecondOpinion is the judge asked for a second opinion with WithSecondOpinion.
+	ComponentSecondOpinion Component = "second_opinion"
+
+	// ComponentCanary is the canary judge compared to the testing agent with WithCanaryJudge.
+	ComponentCanary Component = "canary"
+
+	// ComponentSummarizer is the model keeping the running summary of WithRunningSummary.
+	ComponentSummarizer Component = "summarizer"
+
+	// ComponentGuardrail is a per-turn check, such as the topic guard of WithAllowedTopics,
+	// guardrails implemented outside the package attributing their calls with
+	// ContextWithComponent.
+	ComponentGuardrail Component = "guardrail"
+)
+
+// auditScope is the component and turn the calls made with a context are attributed to.
+type auditScope struct {
+	component Component
+	turn      int
+}
+
+type auditScopeContextKey struct{}
+
+// withAuditScope returns a context attributing the calls made with it to the component at the turn.
+func withAuditScope(ctx context.Context, component Component, turn int) context.Context {
+	return context.WithValue(ctx, auditScopeContextKey{}, auditScope{component: component, turn: turn})
+}
+
+// ContextWithComponent returns a context attributing the calls recorded with it to the
+// component, at the turn the context was attributed to, if any.
+func ContextWithComponent(ctx context.Context, component Component) context.Context {
+	scope, _ := ctx.Value(auditScopeContextKey{}).(auditScope)
+	return withAuditScope(ctx, component, scope.turn)
+}
+
+// auditLog is an append-only log of the outbound calls made during a scenario run.
+type auditLog struct {
+	observe func(ctx context.Context, entry AuditEntry)
+
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+type auditLogContextKey struct{}
+
+// withAuditLog returns a context carrying a new audit log, calling observe, if any, for every
+// entry as it is recorded.
+func withAuditLog(ctx context.Context, observe func(ctx context.Context, entry AuditEntry)) (context.Context, *auditLog) {
+	log := &auditLog{observe: observe}
+	return context.WithValue(ctx, auditLogContextKey{}, log), log
+}
+
+// RecordAuditEntry appends an entry to the audit log of the scenario run in the context. It
+// is a no-op outside of a scenario run. LLMCompletion implementations should call it for
+// every outbound request so it shows up in Result.AuditLog.
+func RecordAuditEntry(ctx context.Context, entry AuditEntry) {
+	log, ok := ctx.Value(auditLogContextKey{}).(*auditLog)
+	if !ok {
+		return
+	}
+
+	if scope, ok := ctx.Value(auditScopeContextKey{}).(auditScope); ok && entry.Component == "" {
+		entry.Component = scope.component
+		entry.Turn = scope.turn
+	}
+	recordCallStats(entry)
+
+	log.mu.Lock()
+	log.entries = append(log.entries, entry)
+	log.mu.Unlock()
+
+	if log.observe != nil {
+		log.observe(ctx, entry)
+	}
+}
+
+// Entries returns a copy of the entries in the audit log.
+func (l *auditLog) Entries() []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AuditEntry{}, l.entries...)
+}
+
+// hashPayload returns the hex encoded SHA-256 hash of a request payload.
+func hashPayload(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAuditEntry(t *testing.T) {
+	// Outside of a scenario run recording is a no-op
+	assert.NotPanics(t, func() {
+		RecordAuditEntry(context.Background(), AuditEntry{Provider: "openai"})
+	})
+
+	ctx, log := withAuditLog(context.Background(), nil)
+	RecordAuditEntry(ctx, AuditEntry{Provider: "openai", Model: "gpt-4o-mini"})
+	RecordAuditEntry(ctx, AuditEntry{Provider: "openai", Model: "gpt-4o"})
+
+	entries := log.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "gpt-4o-mini", entries[0].Model)
+	assert.Equal(t, "gpt-4o", entries[1].Model)
+}
+
+func TestScenario_Run_AuditLog(t *testing.T) {
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			RecordAuditEntry(ctx, AuditEntry{Provider: "mock", PayloadSHA256: hashPayload([]byte("payload"))})
+			if len(messages) == 2 {
+				return &LLMCompletionResponse{
+					Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "hi"}}},
+				}, nil
+			}
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{
+					ToolCalls: []ToolCall{{
+						Type: ToolTypeFunction,
+						Function: &ToolCallFunction{
+							Name:      "finish_test",
+							Arguments: map[string]any{"verdict": "success", "reasoning": "done", "details": map[string]any{"met_criteria": nil, "unmet_criteria": nil, "triggered_failures": nil}},
+						},
+					}},
+				}}},
+			}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(NewTestingAgent(mockLLM)),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, result.AuditLog, 2)
+	assert.Equal(t, "mock", result.AuditLog[0].Provider)
+	assert.Len(t, result.AuditLog[0].PayloadSHA256, 64)
+}
+
+func TestScenario_Run_UsageByComponent(t *testing.T) {
+	calls := 0
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			calls++
+			RecordAuditEntry(ctx, AuditEntry{Provider: "mock", PromptTokens: 100, CompletionTokens: 10})
+			if toolChoice == nil {
+				return &LLMCompletionResponse{
+					Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "hi"}}},
+				}, nil
+			}
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{
+					ToolCalls: []ToolCall{{
+						Type: ToolTypeFunction,
+						Function: &ToolCallFunction{
+							Name:      "finish_test",
+							Arguments: map[string]any{"verdict": "success", "reasoning": "done", "details": map[string]any{"met_criteria": nil, "unmet_criteria": nil, "triggered_failures": nil}},
+						},
+					}},
+				}}},
+			}, nil
+		},
+	}
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			RecordAuditEntry(ctx, AuditEntry{Provider: "mock", PromptTokens: 50, CompletionTokens: 5})
+			RecordAuditEntry(ContextWithComponent(ctx, ComponentGuardrail), AuditEntry{Provider: "mock", PromptTokens: 20, CompletionTokens: 1})
+			return []Message{{Role: MessageRoleAssistant, Content: "Hello"}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(NewTestingAgent(mockLLM)),
+		WithMaxTurns(2),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[Component]ComponentUsage{
+		ComponentSimulator: {Calls: 2, PromptTokens: 200, CompletionTokens: 20},
+		ComponentJudge:     {Calls: 1, PromptTokens: 100, CompletionTokens: 10},
+		ComponentAgent:     {Calls: 2, PromptTokens: 100, CompletionTokens: 10},
+		ComponentGuardrail: {Calls: 2, PromptTokens: 40, CompletionTokens: 2},
+	}, result.UsageByComponent())
+	last := result.AuditLog[len(result.AuditLog)-1]
+	assert.Equal(t, ComponentJudge, last.Component)
+	assert.Equal(t, 2, last.Turn, "the verdict is asked for after the second turn")
+}
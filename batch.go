@@ -0,0 +1,274 @@
+package scenario
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// errBatchDeferred is returned by the OpenAI adapter when it captures a request for a batch
+// instead of sending it.
+var errBatchDeferred = errors.New("request deferred to a batch")
+
+// batchRequest captures the chat completion request of a judge-only evaluation for a batch,
+// then replays its response once the batch is complete.
+type batchRequest struct {
+	params   *openai.ChatCompletionNewParams
+	response *openai.ChatCompletion
+}
+
+type batchRequestContextKey struct{}
+
+// withBatchRequest returns a context making the OpenAI adapter capture or replay the request.
+func withBatchRequest(ctx context.Context, request *batchRequest) context.Context {
+	return context.WithValue(ctx, batchRequestContextKey{}, request)
+}
+
+// complete captures the request when it has no response yet, or replays the response.
+func (b *batchRequest) complete(ctx context.Context, model string, params openai.ChatCompletionNewParams) (*LLMCompletionResponse, error) {
+	if b.response == nil {
+		if b.params != nil {
+			return nil, errors.New("only one request per evaluation can be batched")
+		}
+		b.params = &params
+		return nil, errBatchDeferred
+	}
+
+	response := b.response
+	b.response = nil
+	RecordAuditEntry(ctx, AuditEntry{
+		Time:             time.Now(),
+		Provider:         "openai",
+		Endpoint:         "batches",
+		Model:            model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+	})
+	return completionResponse(response)
+}
+
+// BatchJob is a judge-only evaluation of stored results submitted to the OpenAI Batch API.
+type BatchJob struct {
+	// ID is the ID of the OpenAI batch. Persist it to collect the job from another process.
+	ID string
+}
+
+// BatchProgress is the progress of a batch job.
+type BatchProgress struct {
+	// Status is the status of the OpenAI batch, e.g. "in_progress" or "completed".
+	Status string
+
+	// Total is the number of requests in the batch.
+	Total int64
+
+	// Completed is the number of requests completed.
+	Completed int64
+
+	// Failed is the number of requests that failed.
+	Failed int64
+}
+
+// Done reports whether the batch reached a final status.
+func (p BatchProgress) Done() bool {
+	switch openai.BatchStatus(p.Status) {
+	case openai.BatchStatusCompleted, openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// OpenAIBatchJudge rejudges stored results through the OpenAI Batch API, at about half the
+// cost of synchronous requests, with verdicts available within 24 hours. The testing agent of
+// the scenario must be created with NewTestingAgent over an OpenAI completion.
+type OpenAIBatchJudge struct {
+	client openai.Client
+}
+
+// NewOpenAIBatchJudge creates a new OpenAI batch judge submitting batches with the client.
+func NewOpenAIBatchJudge(client openai.Client) *OpenAIBatchJudge {
+	return &OpenAIBatchJudge{client: client}
+}
+
+// Submit submits the judging of the conversations of the results to a new batch, as Rejudge
+// would judge them.
+func (b *OpenAIBatchJudge) Submit(ctx context.Context, sc Scenario, results []*Result) (*BatchJob, error) {
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for i, result := range results {
+		request := &batchRequest{}
+		_, err := Rejudge(withBatchRequest(ctx, request), sc, result)
+		if !errors.Is(err, errBatchDeferred) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to prepare result %d: %w", i, err)
+			}
+			return nil, errors.New("the testing agent of the scenario does not use an OpenAI completion")
+		}
+
+		if err := encoder.Encode(map[string]any{
+			"custom_id": batchCustomID(i),
+			"method":    "POST",
+			"url":       string(openai.BatchNewParamsEndpointV1ChatCompletions),
+			"body":      request.params,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to encode request %d: %w", i, err)
+		}
+	}
+
+	file, err := b.client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(&input, "scenario-batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload batch input: %w", err)
+	}
+
+	batch, err := b.client.Batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	return &BatchJob{ID: batch.ID}, nil
+}
+
+// Progress returns the progress of the batch job.
+func (b *OpenAIBatchJudge) Progress(ctx context.Context, job *BatchJob) (BatchProgress, error) {
+	batch, err := b.client.Batches.Get(ctx, job.ID)
+	if err != nil {
+		return BatchProgress{}, fmt.Errorf("failed to get batch: %w", err)
+	}
+
+	return BatchProgress{
+		Status:    string(batch.Status),
+		Total:     batch.RequestCounts.Total,
+		Completed: batch.RequestCounts.Completed,
+		Failed:    batch.RequestCounts.Failed,
+	}, nil
+}
+
+// Wait polls the progress of the batch job every interval until it is done.
+func (b *OpenAIBatchJudge) Wait(ctx context.Context, job *BatchJob, interval time.Duration) (BatchProgress, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		progress, err := b.Progress(ctx, job)
+		if err != nil || progress.Done() {
+			return progress, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return progress, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect returns the results rejudged by the completed batch job, in the order of the results
+// it was submitted with, which must be passed again. The results of failed requests are nil
+// and their errors are joined in the returned error.
+func (b *OpenAIBatchJudge) Collect(ctx context.Context, sc Scenario, job *BatchJob, results []*Result) ([]*Result, error) {
+	batch, err := b.client.Batches.Get(ctx, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch: %w", err)
+	}
+	if batch.Status != openai.BatchStatusCompleted {
+		return nil, fmt.Errorf("batch %s is %s, not completed", job.ID, batch.Status)
+	}
+
+	responses := map[string]*openai.ChatCompletion{}
+	failures := map[string]string{}
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		if err := b.readOutput(ctx, fileID, responses, failures); err != nil {
+			return nil, err
+		}
+	}
+
+	rejudged := make([]*Result, len(results))
+	var errs []error
+	for i, result := range results {
+		response, ok := responses[batchCustomID(i)]
+		if !ok {
+			failure, ok := failures[batchCustomID(i)]
+			if !ok {
+				failure = "no response in the batch output"
+			}
+			errs = append(errs, fmt.Errorf("result %d: %s", i, failure))
+			continue
+		}
+
+		rejudged[i], err = Rejudge(withBatchRequest(ctx, &batchRequest{response: response}), sc, result)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("result %d: %w", i, err))
+		}
+	}
+
+	return rejudged, errors.Join(errs...)
+}
+
+// readOutput reads a batch output file, collecting the chat completions of the successful
+// requests and the errors of the failed ones by custom ID.
+func (b *OpenAIBatchJudge) readOutput(ctx context.Context, fileID string, responses map[string]*openai.ChatCompletion, failures map[string]string) error {
+	content, err := b.client.Files.Content(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("failed to download batch output %s: %w", fileID, err)
+	}
+	defer content.Body.Close()
+
+	scanner := bufio.NewScanner(content.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var line struct {
+			CustomID string `json:"custom_id"`
+			Response *struct {
+				StatusCode int             `json:"status_code"`
+				Body       json.RawMessage `json:"body"`
+			} `json:"response"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("failed to parse batch output %s: %w", fileID, err)
+		}
+
+		switch {
+		case line.Error != nil:
+			failures[line.CustomID] = line.Error.Message
+		case line.Response == nil:
+			failures[line.CustomID] = "no response in the batch output"
+		case line.Response.StatusCode != 200:
+			failures[line.CustomID] = fmt.Sprintf("status %d: %s", line.Response.StatusCode, line.Response.Body)
+		default:
+			var chatCompletion openai.ChatCompletion
+			if err := json.Unmarshal(line.Response.Body, &chatCompletion); err != nil {
+				return fmt.Errorf("failed to parse chat completion of %s: %w", line.CustomID, err)
+			}
+			responses[line.CustomID] = &chatCompletion
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read batch output %s: %w", fileID, err)
+	}
+
+	return nil
+}
+
+// batchCustomID returns the custom ID of the request judging the result at the index.
+func batchCustomID(index int) string {
+	return fmt.Sprintf("result-%d", index)
+}
@@ -0,0 +1,112 @@
+package scenario
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIBatchJudge(t *testing.T) {
+	var customIDs []string
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, _, err := r.FormFile("file")
+			require.NoError(t, err)
+			assert.Equal(t, "batch", r.FormValue("purpose"))
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var line struct {
+					CustomID string `json:"custom_id"`
+					URL      string `json:"url"`
+					Body     struct {
+						Model string `json:"model"`
+					} `json:"body"`
+				}
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				assert.Equal(t, "/v1/chat/completions", line.URL)
+				assert.Equal(t, "gpt-4o-mini", line.Body.Model)
+				customIDs = append(customIDs, line.CustomID)
+			}
+			_, _ = w.Write([]byte(`{"id": "file-input", "object": "file", "purpose": "batch"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			body, _ := io.ReadAll(r.Body)
+			assert.Contains(t, string(body), `"input_file_id":"file-input"`)
+			_, _ = w.Write([]byte(`{"id": "batch-1", "object": "batch", "status": "validating"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/batches/batch-1":
+			polls++
+			status := "in_progress"
+			if polls > 1 {
+				status = "completed"
+			}
+			fmt.Fprintf(w, `{"id": "batch-1", "object": "batch", "status": %q, "output_file_id": "file-output", "error_file_id": "file-errors", "request_counts": {"total": 2, "completed": 1, "failed": 1}}`, status)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-output/content":
+			verdict := `{"verdict": "success", "reasoning": "the agent helped", "details": {"met_criteria": ["Agent helps"], "unmet_criteria": [], "triggered_failures": []}}`
+			fmt.Fprintf(w, `{"custom_id": "result-0", "response": {"status_code": 200, "body": {"choices": [{"index": 0, "message": {"role": "assistant", "content": "", "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "finish_test", "arguments": %q}}]}}], "usage": {"prompt_tokens": 100, "completion_tokens": 20}}}}`+"\n", verdict)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-errors/content":
+			_, _ = w.Write([]byte(`{"custom_id": "result-1", "response": null, "error": {"code": "server_error", "message": "internal error"}}` + "\n"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"), option.WithMaxRetries(0))
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(NewTestingAgent(NewOpenAICompletionWithClient("gpt-4o-mini", client))),
+		WithSuccessCriteria("Agent helps"),
+	)
+	results := []*Result{
+		{Seed: 1, Conversation: []Message{{Role: MessageRoleUser, Content: "help"}, {Role: MessageRoleAssistant, Content: "sure"}}},
+		{Seed: 2, Conversation: []Message{{Role: MessageRoleUser, Content: "help"}, {Role: MessageRoleAssistant, Content: "no"}}},
+	}
+	judge := NewOpenAIBatchJudge(client)
+	ctx := context.Background()
+
+	job, err := judge.Submit(ctx, s, results)
+	require.NoError(t, err)
+	assert.Equal(t, "batch-1", job.ID)
+	assert.Equal(t, []string{"result-0", "result-1"}, customIDs)
+
+	progress, err := judge.Wait(ctx, job, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, BatchProgress{Status: "completed", Total: 2, Completed: 1, Failed: 1}, progress)
+	assert.True(t, progress.Done())
+
+	rejudged, err := judge.Collect(ctx, s, job, results)
+	require.ErrorContains(t, err, "result 1: internal error")
+	require.Len(t, rejudged, 2)
+	require.NotNil(t, rejudged[0])
+	assert.True(t, rejudged[0].Success)
+	assert.Equal(t, []string{"Agent helps"}, rejudged[0].MetCriteria)
+	assert.Equal(t, int64(1), rejudged[0].Seed)
+	assert.Equal(t, results[0].Conversation, rejudged[0].Conversation)
+	assert.Nil(t, rejudged[1])
+}
+
+func TestOpenAIBatchJudge_RequiresOpenAICompletion(t *testing.T) {
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+	results := []*Result{{Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}}}}
+
+	_, err := NewOpenAIBatchJudge(openai.NewClient()).Submit(context.Background(), s, results)
+
+	assert.ErrorContains(t, err, "does not use an OpenAI completion")
+}
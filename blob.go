@@ -0,0 +1,89 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// BlobStore stores blobs by key, the storage the result cache, the completion and persona
+// memory stores and the artifacts of results sit on, e.g. to keep them in an object storage
+// bucket when CI runners are ephemeral. Keys are slash-separated paths such as
+// "refund/v1/42.json". Object storage backends implement it with the client of the provider,
+// retention being left to the storage, e.g. the lifecycle rules of the bucket.
+// Implementations must be safe for concurrent use.
+type BlobStore interface {
+	// Get returns the blob stored at the key, or an error wrapping fs.ErrNotExist if there is
+	// none.
+	Get(key string) ([]byte, error)
+
+	// Put stores the blob at the key, replacing any previous one.
+	Put(key string, data []byte) error
+
+	// List returns the keys of the blobs directly under the prefix, a slash-separated
+	// directory, in lexical order.
+	List(prefix string) ([]string, error)
+}
+
+// dirBlobStore is a BlobStore storing the blobs as files in a directory.
+type dirBlobStore struct {
+	dir string
+}
+
+// NewDirBlobStore creates a blob store storing the blobs as files in the directory, laid out
+// as dir/<key>.
+func NewDirBlobStore(dir string) BlobStore {
+	return &dirBlobStore{dir: dir}
+}
+
+// path returns the path of the file of the key, rejecting keys outside of the directory.
+func (d *dirBlobStore) path(key string) (string, error) {
+	local := filepath.FromSlash(key)
+	if key != "" && !filepath.IsLocal(local) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(d.dir, local), nil
+}
+
+func (d *dirBlobStore) Get(key string) ([]byte, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+func (d *dirBlobStore) Put(key string, data []byte) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (d *dirBlobStore) List(prefix string) ([]string, error) {
+	dir, err := d.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			keys = append(keys, path.Join(prefix, entry.Name()))
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,109 @@
+package scenario
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapBlobStore is a BlobStore keeping the blobs in a map, like an object storage bucket.
+type mapBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (m *mapBlobStore) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.blobs[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return data, nil
+}
+
+func (m *mapBlobStore) Put(key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.blobs == nil {
+		m.blobs = map[string][]byte{}
+	}
+	m.blobs[key] = data
+	return nil
+}
+
+func (m *mapBlobStore) List(prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for key := range m.blobs {
+		if path.Dir(key) == prefix {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+func TestDirBlobStore(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+
+	_, err := store.Get("refund/42.json")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	keys, err := store.List("refund")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	require.NoError(t, store.Put("refund/b.json", []byte("b")))
+	require.NoError(t, store.Put("refund/a.json", []byte("a")))
+	require.NoError(t, store.Put("refund/nested/c.json", []byte("c")))
+
+	data, err := store.Get("refund/a.json")
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+	keys, err = store.List("refund")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refund/a.json", "refund/b.json"}, keys)
+
+	assert.ErrorContains(t, store.Put("../outside.json", []byte("x")), `invalid blob key "../outside.json"`)
+	_, err = store.Get("/etc/passwd")
+	assert.ErrorContains(t, err, "invalid blob key")
+}
+
+func TestBlobStores(t *testing.T) {
+	store := &mapBlobStore{}
+
+	cache := NewBlobResultCache(store)
+	key := CacheKey{ScenarioID: "refund", AgentVersion: "v1", Seed: 42}
+	require.NoError(t, cache.Store(key, &Result{Success: true}))
+	cached, ok, err := cache.Load(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, cached.Success)
+
+	memories := NewBlobPersonaMemoryStore(store)
+	require.NoError(t, memories.Remember("alice", PersonaMemory{ScenarioID: "refund", RunID: "r1"}))
+	recalled, err := memories.Recall("alice")
+	require.NoError(t, err)
+	require.Len(t, recalled, 1)
+	assert.Equal(t, "refund", recalled[0].ScenarioID)
+
+	result := &Result{ScenarioID: "refund", RunID: "r1", Artifacts: []Artifact{{Turn: 1, Name: "receipt.txt", Data: []byte("42 EUR")}}}
+	require.NoError(t, result.StoreArtifacts(store))
+	data, err := store.Get("refund/r1/artifacts/turn-1-receipt.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "42 EUR", string(data))
+
+	var stored []string
+	for key := range store.blobs {
+		stored = append(stored, key)
+	}
+	slices.Sort(stored)
+	assert.Equal(t, "alice/r1.json,refund/r1/artifacts/turn-1-receipt.txt,refund/v1/42.json", strings.Join(stored, ","))
+}
@@ -0,0 +1,187 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreaker while it is open, without calling the
+// wrapped LLMCompletion.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState string
+
+const (
+	// BreakerClosed lets the calls through.
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen fails the calls fast with ErrCircuitOpen until the cooldown has elapsed.
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen lets a single trial call through once the cooldown has elapsed, closing
+	// the breaker if it succeeds and opening it again otherwise.
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// AdapterHealth is the health of the LLMCompletion wrapped by a CircuitBreaker.
+type AdapterHealth struct {
+	// State is the state of the breaker.
+	State BreakerState
+
+	// ConsecutiveFailures is the number of calls that failed in a row.
+	ConsecutiveFailures int
+
+	// OpenUntil is when the breaker lets a trial call through, zero when it is not open.
+	OpenUntil time.Time
+}
+
+// CircuitBreaker is an LLMCompletion failing fast once the LLMCompletion it wraps failed
+// repeatedly, so a degraded provider does not slowly time out every scenario. It is safe for
+// concurrent use, share it between the testing agents of a suite.
+type CircuitBreaker struct {
+	inner         LLMCompletion
+	threshold     int
+	cooldown      time.Duration
+	onStateChange func(from, to BreakerState)
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+// CircuitBreakerOption configures a CircuitBreaker created with NewCircuitBreaker.
+type CircuitBreakerOption func(*CircuitBreaker)
+
+// WithBreakerStateChange calls fn every time the state of the breaker changes, e.g. to report
+// a degraded provider. It is called with the breaker locked and must not call it.
+func WithBreakerStateChange(fn func(from, to BreakerState)) CircuitBreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onStateChange = fn
+	}
+}
+
+// NewCircuitBreaker wraps an LLMCompletion with a circuit breaker opening after threshold
+// consecutive failed calls. While open, calls fail with ErrCircuitOpen for the cooldown, then
+// a trial call decides whether to close the breaker. Only transient errors, see
+// IsTransientError, count as failures: calls cancelled by their context and client errors,
+// such as a malformed request or a rejected key, do not.
+func NewCircuitBreaker(inner LLMCompletion, threshold int, cooldown time.Duration, opts ...CircuitBreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		inner:     inner,
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *CircuitBreaker) Completion(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+	if err := b.allow(); err != nil {
+		RecordDiagnostic(ctx, Diagnostic{
+			Code:      DiagnosticCircuitOpen,
+			Message:   err.Error(),
+			Retryable: true,
+		})
+		return nil, err
+	}
+
+	response, err := b.inner.Completion(ctx, messages, temperature, maxTokens, tools, toolChoice)
+	b.record(ctx, err)
+
+	return response, err
+}
+
+// Model returns the model of the wrapped LLMCompletion, empty when it does not implement
+// ModelNamer.
+func (b *CircuitBreaker) Model() string {
+	return completionModel(b.inner)
+}
+
+// Health returns the health of the wrapped LLMCompletion.
+func (b *CircuitBreaker) Health() AdapterHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	health := AdapterHealth{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state == BreakerOpen {
+		health.OpenUntil = b.openUntil
+	}
+	return health
+}
+
+// allow returns ErrCircuitOpen if the call must fail fast, moving an open breaker whose
+// cooldown has elapsed to half open for a trial call.
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Now().Before(b.openUntil) {
+			return fmt.Errorf("%w until %s after %d consecutive failures", ErrCircuitOpen, b.openUntil.Format(time.RFC3339), b.failures)
+		}
+		b.setState(BreakerHalfOpen)
+		b.trial = true
+	case BreakerHalfOpen:
+		if b.trial {
+			return fmt.Errorf("%w: trial call in progress", ErrCircuitOpen)
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a call.
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if err != nil && ctx.Err() != nil {
+		// The caller gave up, the provider is not to blame
+		return
+	}
+	if errors.Is(err, errBatchDeferred) {
+		// The request was captured for a batch, it was not sent
+		return
+	}
+	if err != nil && !IsTransientError(err) {
+		// Client errors, such as a malformed request, are not a failure of the provider
+		return
+	}
+	if err == nil {
+		b.failures = 0
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		b.setState(BreakerOpen)
+	}
+}
+
+// setState moves the breaker to the state, reporting the change.
+func (b *CircuitBreaker) setState(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if b.onStateChange != nil {
+		b.onStateChange(from, state)
+	}
+}
@@ -0,0 +1,123 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	errProvider := &AnthropicError{StatusCode: http.StatusServiceUnavailable, Type: "overloaded_error"}
+
+	calls := 0
+	failing := true
+	inner := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			calls++
+			if failing {
+				return nil, errProvider
+			}
+			return &LLMCompletionResponse{}, nil
+		},
+	}
+	var transitions []string
+	breaker := NewCircuitBreaker(inner, 2, 20*time.Millisecond, WithBreakerStateChange(func(from, to BreakerState) {
+		transitions = append(transitions, string(from)+" -> "+string(to))
+	}))
+
+	_, err := breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, err, errProvider)
+	assert.Equal(t, AdapterHealth{State: BreakerClosed, ConsecutiveFailures: 1}, breaker.Health())
+
+	_, err = breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, err, errProvider)
+	assert.Equal(t, BreakerOpen, breaker.Health().State)
+	assert.False(t, breaker.Health().OpenUntil.IsZero())
+
+	_, err = breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, calls)
+
+	// A failed trial call opens the breaker again
+	time.Sleep(25 * time.Millisecond)
+	_, err = breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, err, errProvider)
+	assert.Equal(t, BreakerOpen, breaker.Health().State)
+
+	time.Sleep(25 * time.Millisecond)
+	failing = false
+	_, err = breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, AdapterHealth{State: BreakerClosed}, breaker.Health())
+
+	assert.Equal(t, []string{
+		"closed -> open",
+		"open -> half_open",
+		"half_open -> open",
+		"open -> half_open",
+		"half_open -> closed",
+	}, transitions)
+}
+
+func TestCircuitBreaker_CancelledCalls(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	breaker := NewCircuitBreaker(&mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			return nil, ctx.Err()
+		},
+	}, 1, time.Minute)
+
+	_, err := breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, AdapterHealth{State: BreakerClosed}, breaker.Health())
+}
+
+func TestCircuitBreaker_Diagnostic(t *testing.T) {
+	breaker := NewCircuitBreaker(&mockNamedLLMCompletion{
+		mockLLMCompletion: mockLLMCompletion{
+			completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+				return nil, &AnthropicError{StatusCode: http.StatusServiceUnavailable, Type: "overloaded_error"}
+			},
+		},
+		model: "gpt-4o",
+	}, 1, time.Minute)
+	assert.Equal(t, "gpt-4o", breaker.Model())
+
+	ctx, diagnostics := withDiagnostics(withAuditScope(context.Background(), ComponentJudge, 3))
+	_, _ = breaker.Completion(ctx, nil, nil, nil, nil, nil)
+	_, err := breaker.Completion(ctx, nil, nil, nil, nil, nil)
+
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	require.Len(t, diagnostics.Diagnostics(), 1)
+	assert.Equal(t, DiagnosticCircuitOpen, diagnostics.Diagnostics()[0].Code)
+	assert.Equal(t, ComponentJudge, diagnostics.Diagnostics()[0].Component)
+	assert.Equal(t, 3, diagnostics.Diagnostics()[0].Turn)
+}
+
+func TestCircuitBreaker_IgnoredErrors(t *testing.T) {
+	for _, err := range []error{
+		errBatchDeferred,
+		&AnthropicError{StatusCode: http.StatusBadRequest, Type: "invalid_request_error"},
+		&AnthropicError{StatusCode: http.StatusUnauthorized, Type: "authentication_error"},
+		errors.New("malformed prompt"),
+	} {
+		breaker := NewCircuitBreaker(&mockLLMCompletion{
+			completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+				return nil, err
+			},
+		}, 1, time.Minute)
+
+		_, _ = breaker.Completion(context.Background(), nil, nil, nil, nil, nil)
+		_, got := breaker.Completion(context.Background(), nil, nil, nil, nil, nil)
+
+		require.ErrorIs(t, got, err)
+		assert.Equal(t, AdapterHealth{State: BreakerClosed}, breaker.Health(), "%v does not trip the breaker", err)
+	}
+}
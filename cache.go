@@ -0,0 +1,101 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strconv"
+)
+
+// CacheKey identifies the runs of a deterministic agent expected to produce the same result.
+type CacheKey struct {
+	// ScenarioID is the identifier of the scenario, see Result.ScenarioID.
+	ScenarioID string
+
+	// AgentVersion is the version of the agent under test.
+	AgentVersion string
+
+	// Seed is the seed of the run.
+	Seed int64
+}
+
+// ResultCache stores the results of runs to skip running them again, see WithResultCache.
+type ResultCache interface {
+	// Load returns the result stored for the key, or false if there is none.
+	Load(key CacheKey) (*Result, bool, error)
+
+	// Store stores the result for the key.
+	Store(key CacheKey, result *Result) error
+}
+
+// blobResultCache is a ResultCache storing the results as JSON blobs in a blob store.
+type blobResultCache struct {
+	store BlobStore
+}
+
+// NewBlobResultCache creates a result cache storing the results as JSON blobs in the store,
+// at <scenario id>/<agent version>/<seed>.json.
+func NewBlobResultCache(store BlobStore) ResultCache {
+	return &blobResultCache{store: store}
+}
+
+// NewDirResultCache creates a result cache storing the results as JSON files laid out as
+// dir/<scenario id>/<agent version>/<seed>.json.
+func NewDirResultCache(dir string) ResultCache {
+	return NewBlobResultCache(NewDirBlobStore(dir))
+}
+
+func (c *blobResultCache) key(key CacheKey) string {
+	return path.Join(key.ScenarioID, url.PathEscape(key.AgentVersion), strconv.FormatInt(key.Seed, 10)+".json")
+}
+
+func (c *blobResultCache) Load(key CacheKey) (*Result, bool, error) {
+	data, err := c.store.Get(c.key(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var result Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cached result %s: %w", c.key(key), err)
+	}
+	return &result, true, nil
+}
+
+func (c *blobResultCache) Store(key CacheKey, result *Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return c.store.Put(c.key(key), data)
+}
+
+// cacheKey returns the key of the run in the result cache.
+func (s *scenario) cacheKey() CacheKey {
+	return CacheKey{
+		ScenarioID:   s.scenarioID(),
+		AgentVersion: s.agentVersion,
+		Seed:         s.runSeed,
+	}
+}
+
+// cachedResult returns the result stored in the result cache for the run, if any.
+func (s *scenario) cachedResult() (*Result, bool, error) {
+	if s.resultCache == nil {
+		return nil, false, nil
+	}
+
+	result, ok, err := s.resultCache.Load(s.cacheKey())
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	result.Cached = true
+	stats.Add("cache_hits", 1)
+
+	return result, true, nil
+}
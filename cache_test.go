@@ -0,0 +1,91 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirResultCache(t *testing.T) {
+	cache := NewDirResultCache(t.TempDir())
+	key := CacheKey{ScenarioID: "checkout/refund", AgentVersion: "v1.2/rc", Seed: 42}
+
+	_, ok, err := cache.Load(key)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	result := &Result{Success: true, Seed: 42, Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}}}
+	require.NoError(t, cache.Store(key, result))
+
+	loaded, ok, err := cache.Load(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, result, loaded)
+
+	_, ok, err = cache.Load(CacheKey{ScenarioID: "checkout/refund", AgentVersion: "v1.3", Seed: 42})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestScenario_Run_WithResultCache(t *testing.T) {
+	ctx := context.Background()
+	runs := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			runs++
+			return []Message{{Role: MessageRoleAssistant, Content: "Hello"}}, nil
+		},
+	}
+	cache := NewDirResultCache(t.TempDir())
+	newScenario := func(version string) Scenario {
+		return NewScenario(
+			WithID("cached"),
+			WithAgent(mockAgentInst),
+			WithTestingAgent(&mockTestingAgent{}),
+			WithSeed(7),
+			WithResultCache(cache, version),
+		)
+	}
+	hitsBefore := ReadStats().CacheHits
+
+	first, err := newScenario("v1").Run(ctx)
+	require.NoError(t, err)
+	assert.False(t, first.Cached)
+
+	second, err := newScenario("v1").Run(ctx)
+	require.NoError(t, err)
+	assert.True(t, second.Cached)
+	assert.Equal(t, first.RunID, second.RunID)
+	assert.Equal(t, 1, runs, "the cached run is skipped")
+	assert.Equal(t, hitsBefore+1, ReadStats().CacheHits)
+
+	third, err := newScenario("v2").Run(ctx)
+	require.NoError(t, err)
+	assert.False(t, third.Cached)
+	assert.Equal(t, 2, runs, "a new agent version runs again")
+}
+
+type failingResultCache struct {
+	ResultCache
+}
+
+func (failingResultCache) Store(key CacheKey, result *Result) error {
+	return errors.New("disk full")
+}
+
+func TestScenario_Run_ResultCacheStoreFailure(t *testing.T) {
+	result, err := NewScenario(
+		WithID("cached"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithResultCache(failingResultCache{NewDirResultCache(t.TempDir())}, "v1"),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, DiagnosticCacheStoreFailed, result.Diagnostics[0].Code)
+	assert.Equal(t, "disk full", result.Diagnostics[0].Message)
+}
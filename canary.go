@@ -0,0 +1,42 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// askCanary asks the canary judge set with WithCanaryJudge for its verdict on the conversation
+// judged by the testing agent, recording it on the result and a diagnostic when it disagrees.
+// The canary never changes the verdict, its failures being recorded as diagnostics.
+func (s *scenario) askCanary(ctx context.Context, result *Result) {
+	if s.canaryJudge == nil {
+		return
+	}
+
+	ctx = withAuditScope(ctx, ComponentCanary, len(s.turns))
+	var canary *Result
+	err := s.retry(ctx, func() error {
+		var err error
+		_, canary, err = s.canaryJudge.GenerateNextMessage(ctx, s.description, s.strategy, s.runSuccessCriteria(), s.runFailureCriteria(), result.Conversation, false, true)
+		return err
+	})
+	if err == nil && canary == nil {
+		err = errors.New("canary judge did not give a verdict")
+	}
+	if err != nil {
+		RecordDiagnostic(ctx, Diagnostic{
+			Code:    DiagnosticCanaryFailed,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result.CanaryVerdict = canary
+	if canary.Success != result.Success {
+		RecordDiagnostic(ctx, Diagnostic{
+			Code:    DiagnosticCanaryDisagreement,
+			Message: fmt.Sprintf("canary judge gave success=%v against success=%v: %s", canary.Success, result.Success, canary.Reasoning),
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_CanaryJudge(t *testing.T) {
+	var judged []Message
+	canary := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			require.True(t, lastMessage)
+			judged = conversation
+			return nil, NewFailurePartialResult(conversation, "canary says no", nil, []string{"criterion"}, nil), nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithCanaryJudge(canary),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success, "the canary does not affect the result")
+	assert.Equal(t, result.Conversation, judged)
+	require.NotNil(t, result.CanaryVerdict)
+	assert.False(t, result.CanaryVerdict.Success)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, DiagnosticCanaryDisagreement, result.Diagnostics[0].Code)
+	assert.Equal(t, ComponentCanary, result.Diagnostics[0].Component)
+	assert.Contains(t, result.Diagnostics[0].Message, "canary says no")
+}
+
+func TestScenario_Run_CanaryJudgeAgrees(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithCanaryJudge(&mockTestingAgent{}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	require.NotNil(t, result.CanaryVerdict)
+	assert.True(t, result.CanaryVerdict.Success)
+	assert.Empty(t, result.Diagnostics)
+}
+
+func TestScenario_Run_CanaryJudgeFails(t *testing.T) {
+	canary := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			return nil, nil, errors.New("model not found")
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithCanaryJudge(canary),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Nil(t, result.CanaryVerdict)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, DiagnosticCanaryFailed, result.Diagnostics[0].Code)
+}
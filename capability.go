@@ -0,0 +1,116 @@
+package scenario
+
+import (
+	"strings"
+	"sync"
+)
+
+// ModelCapabilities describes what a model supports, so the testing agent can pick its verdict
+// protocol and manage its context window automatically, see RegisterModelCapabilities.
+type ModelCapabilities struct {
+	// ToolCalling is whether the model supports tool calling. Without it, the testing agent
+	// gives its verdict as text.
+	ToolCalling bool
+
+	// StrictTools is whether the model supports strict tool schemas. Without it, the testing
+	// agent uses SchemaPresetLoose.
+	StrictTools bool
+
+	// JSONMode is whether the model supports JSON mode.
+	JSONMode bool
+
+	// ContextWindow is the context window of the model in tokens, 0 if unknown. The oldest
+	// messages shown to the testing agent are elided to fit in it.
+	ContextWindow int
+
+	// Vision is whether the model supports image inputs.
+	Vision bool
+}
+
+// ModelNamer is implemented by LLMCompletion adapters to report the model they complete with,
+// which the testing agent looks up in the model capability registry.
+type ModelNamer interface {
+	Model() string
+}
+
+// completionModel returns the model of the LLMCompletion, empty when it does not implement
+// ModelNamer.
+func completionModel(completion LLMCompletion) string {
+	if namer, ok := completion.(ModelNamer); ok {
+		return namer.Model()
+	}
+	return ""
+}
+
+var (
+	modelCapabilitiesMu sync.RWMutex
+	modelCapabilities   = map[string]ModelCapabilities{
+		"gpt-3.5-turbo": {ToolCalling: true, JSONMode: true, ContextWindow: 16_385},
+		"gpt-4-turbo":   {ToolCalling: true, JSONMode: true, ContextWindow: 128_000, Vision: true},
+		"gpt-4o":        {ToolCalling: true, StrictTools: true, JSONMode: true, ContextWindow: 128_000, Vision: true},
+		"gpt-4.1":       {ToolCalling: true, StrictTools: true, JSONMode: true, ContextWindow: 1_047_576, Vision: true},
+		"o1":            {ToolCalling: true, StrictTools: true, JSONMode: true, ContextWindow: 200_000, Vision: true},
+		"o1-mini":       {ContextWindow: 128_000},
+		"o3":            {ToolCalling: true, StrictTools: true, JSONMode: true, ContextWindow: 200_000, Vision: true},
+		"o3-mini":       {ToolCalling: true, StrictTools: true, JSONMode: true, ContextWindow: 200_000},
+		"o4-mini":       {ToolCalling: true, StrictTools: true, JSONMode: true, ContextWindow: 200_000, Vision: true},
+		"claude-":       {ToolCalling: true, ContextWindow: 200_000, Vision: true},
+	}
+)
+
+// RegisterModelCapabilities registers the capabilities of the models whose name starts with
+// the prefix, e.g. "gpt-4o" for "gpt-4o-mini" too. The longest matching prefix wins, so
+// specific versions can override their family. Registering a prefix again replaces it.
+func RegisterModelCapabilities(prefix string, capabilities ModelCapabilities) {
+	modelCapabilitiesMu.Lock()
+	defer modelCapabilitiesMu.Unlock()
+
+	modelCapabilities[prefix] = capabilities
+}
+
+// LookupModelCapabilities returns the capabilities registered for the longest prefix of the
+// model, or false if none matches.
+func LookupModelCapabilities(model string) (ModelCapabilities, bool) {
+	modelCapabilitiesMu.RLock()
+	defer modelCapabilitiesMu.RUnlock()
+
+	var capabilities ModelCapabilities
+	longest := -1
+	for prefix, c := range modelCapabilities {
+		if strings.HasPrefix(model, prefix) && len(prefix) > longest {
+			capabilities = c
+			longest = len(prefix)
+		}
+	}
+	return capabilities, longest >= 0
+}
+
+// fitContextWindow elides the content of the oldest messages of the conversation until its
+// estimated tokens fit in the share of the context window left for the conversation, returning
+// the number of messages elided. Messages are kept in place so the evidence indices of the
+// verdict still refer to the conversation.
+func fitContextWindow(conversation []Message, contextWindow int) ([]Message, int) {
+	// The system prompt, the tools and the verdict take the remaining quarter of the window
+	budget := contextWindow * 3 / 4
+	tokens := ConversationStats(conversation).EstimatedTokens
+	if tokens <= budget {
+		return conversation, 0
+	}
+
+	fitted := make([]Message, len(conversation))
+	copy(fitted, conversation)
+	elided := 0
+	for i := range fitted {
+		if tokens <= budget || i == len(fitted)-1 {
+			break
+		}
+		if fitted[i].Content == "" {
+			continue
+		}
+		tokens -= ConversationStats(fitted[i : i+1]).EstimatedTokens
+		fitted[i].Content = "[message elided to fit the context window]"
+		tokens += ConversationStats(fitted[i : i+1]).EstimatedTokens
+		elided++
+	}
+	return fitted, elided
+}
@@ -0,0 +1,67 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockNamedLLMCompletion is a mockLLMCompletion reporting its model.
+type mockNamedLLMCompletion struct {
+	mockLLMCompletion
+	model string
+}
+
+func (m *mockNamedLLMCompletion) Model() string {
+	return m.model
+}
+
+func TestLookupModelCapabilities(t *testing.T) {
+	capabilities, ok := LookupModelCapabilities("o1-mini-2024-09-12")
+	require.True(t, ok)
+	assert.False(t, capabilities.ToolCalling, "the longest prefix wins over o1")
+
+	capabilities, ok = LookupModelCapabilities("gpt-4o-mini")
+	require.True(t, ok)
+	assert.True(t, capabilities.StrictTools)
+
+	_, ok = LookupModelCapabilities("unknown-model")
+	assert.False(t, ok)
+}
+
+func TestRegisterModelCapabilities(t *testing.T) {
+	RegisterModelCapabilities("test-model", ModelCapabilities{ContextWindow: 8_000})
+
+	capabilities, ok := LookupModelCapabilities("test-model-v2")
+
+	require.True(t, ok)
+	assert.Equal(t, ModelCapabilities{ContextWindow: 8_000}, capabilities)
+}
+
+func TestNewTestingAgent_ModelCapabilities(t *testing.T) {
+	RegisterModelCapabilities("test-no-tools", ModelCapabilities{ContextWindow: 100})
+	var requests [][]Message
+	var requestTools [][]Tool
+	llm := &mockNamedLLMCompletion{model: "test-no-tools"}
+	llm.completionFunc = func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+		requests = append(requests, messages)
+		requestTools = append(requestTools, tools)
+		return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "next"}}}}, nil
+	}
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: strings.Repeat("a", 400)},
+		{Role: MessageRoleAssistant, Content: "short answer"},
+	}
+
+	_, _, err := NewTestingAgent(llm).GenerateNextMessage(context.Background(), "description", "strategy", nil, nil, conversation, false, false)
+
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Nil(t, requestTools[0], "the verdict is given as text by models without tool calling")
+	assert.Equal(t, "[message elided to fit the context window]", requests[0][2].Content)
+	assert.Equal(t, "short answer", requests[0][3].Content)
+	assert.Equal(t, strings.Repeat("a", 400), conversation[0].Content, "the conversation is left untouched")
+}
@@ -0,0 +1,91 @@
+package scenario
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// gzipSuffix is the suffix of the keys of the blobs compressed by a gzip blob store.
+const gzipSuffix = ".gz"
+
+// gzipMagic is the header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipBlobStore is a BlobStore compressing the blobs of another store with gzip.
+type gzipBlobStore struct {
+	store BlobStore
+}
+
+// NewGzipBlobStore creates a blob store compressing the blobs with gzip before storing them in
+// the store, at their key with a .gz suffix, e.g. for the long transcripts of nightly suites
+// with NewBlobResultCache or Result.StoreArtifacts. Blobs are decompressed when read, and blobs
+// stored without compression are still read as is, so existing stores can be switched to it.
+func NewGzipBlobStore(store BlobStore) BlobStore {
+	return &gzipBlobStore{store: store}
+}
+
+func (g *gzipBlobStore) Get(key string) ([]byte, error) {
+	data, err := g.store.Get(key + gzipSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = g.store.Get(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := DecompressReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress blob %s: %w", key, err)
+	}
+	data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress blob %s: %w", key, err)
+	}
+	return data, nil
+}
+
+func (g *gzipBlobStore) Put(key string, data []byte) error {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return g.store.Put(key+gzipSuffix, b.Bytes())
+}
+
+func (g *gzipBlobStore) List(prefix string) ([]string, error) {
+	keys, err := g.store.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	listed := make([]string, len(keys))
+	for i, key := range keys {
+		listed[i] = strings.TrimSuffix(key, gzipSuffix)
+	}
+	slices.Sort(listed)
+	return slices.Compact(listed), nil
+}
+
+// DecompressReader returns a reader decompressing r if it is a gzip stream, detected from its
+// header, or reading it as is otherwise. It streams, so large transcripts are not loaded in
+// memory at once, e.g. to read a result written through gzip.Writer with ReadResultJSON.
+func DecompressReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.Equal(header, gzipMagic) {
+		return buffered, nil
+	}
+	return gzip.NewReader(buffered)
+}
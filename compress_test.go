@@ -0,0 +1,73 @@
+package scenario
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipBlobStore(t *testing.T) {
+	dir := NewDirBlobStore(t.TempDir())
+	store := NewGzipBlobStore(dir)
+	transcript := strings.Repeat(`{"role":"user","content":"where is my order?"}`, 100)
+
+	require.NoError(t, store.Put("refund/a.json", []byte(transcript)))
+	compressed, err := dir.Get("refund/a.json.gz")
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(transcript)/10)
+
+	data, err := store.Get("refund/a.json")
+	require.NoError(t, err)
+	assert.Equal(t, transcript, string(data))
+
+	require.NoError(t, dir.Put("refund/b.json", []byte(`{"plain":true}`)))
+	data, err = store.Get("refund/b.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"plain":true}`, string(data), "blobs stored without compression are read as is")
+
+	keys, err := store.List("refund")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refund/a.json", "refund/b.json"}, keys)
+}
+
+func TestGzipBlobStore_Stores(t *testing.T) {
+	store := NewGzipBlobStore(NewDirBlobStore(t.TempDir()))
+
+	cache := NewBlobResultCache(store)
+	key := CacheKey{ScenarioID: "refund", AgentVersion: "v1", Seed: 42}
+	require.NoError(t, cache.Store(key, &Result{Success: true, Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}}}))
+	cached, ok, err := cache.Load(key)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "hi", cached.Conversation[0].Content)
+
+	memories := NewBlobPersonaMemoryStore(store)
+	require.NoError(t, memories.Remember("alice", PersonaMemory{ScenarioID: "refund", RunID: "r1"}))
+	recalled, err := memories.Recall("alice")
+	require.NoError(t, err)
+	require.Len(t, recalled, 1)
+	assert.Equal(t, "r1", recalled[0].RunID)
+}
+
+func TestReadResultJSON_Gzip(t *testing.T) {
+	result := &Result{ScenarioID: "refund", Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}}}
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	require.NoError(t, result.WriteJSON(w))
+	require.NoError(t, w.Close())
+
+	read, err := ReadResultJSON(&b)
+
+	require.NoError(t, err)
+	assert.Equal(t, result.Conversation, read.Conversation)
+
+	var plain bytes.Buffer
+	require.NoError(t, result.WriteJSON(&plain))
+	read, err = ReadResultJSON(&plain)
+	require.NoError(t, err)
+	assert.Equal(t, "refund", read.ScenarioID)
+}
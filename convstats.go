@@ -0,0 +1,100 @@
+package scenario
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// RoleStatistics are the statistics of the messages of a role in a conversation.
+type RoleStatistics struct {
+	// Messages is the number of messages.
+	Messages int `json:"messages"`
+
+	// AverageLength is the average number of characters of the messages.
+	AverageLength float64 `json:"average_length"`
+
+	// Questions is the number of questions asked in the messages, counted as runs of question
+	// marks.
+	Questions int `json:"questions"`
+
+	// ToolCalls is the number of tool calls made in the messages.
+	ToolCalls int `json:"tool_calls"`
+}
+
+// ConversationStatistics are basic statistics of a conversation, see ConversationStats.
+type ConversationStatistics struct {
+	// Messages is the number of messages.
+	Messages int `json:"messages"`
+
+	// ByRole are the statistics of the messages by role.
+	ByRole map[MessageRole]RoleStatistics `json:"by_role"`
+
+	// ToolCalls is the number of tool calls made in the conversation.
+	ToolCalls int `json:"tool_calls"`
+
+	// EstimatedTokens is a rough estimate of the number of tokens of the conversation, at four
+	// characters per token, for budgeting rather than billing.
+	EstimatedTokens int `json:"estimated_tokens"`
+}
+
+// ConversationStats computes the statistics of the messages.
+func ConversationStats(messages []Message) ConversationStatistics {
+	stats := ConversationStatistics{
+		Messages: len(messages),
+		ByRole:   map[MessageRole]RoleStatistics{},
+	}
+	lengths := map[MessageRole]int{}
+	for _, message := range messages {
+		length := utf8.RuneCountInString(message.Content)
+		lengths[message.Role] += length
+
+		role := stats.ByRole[message.Role]
+		role.Messages++
+		role.Questions += countQuestions(message.Content)
+		role.ToolCalls += len(message.ToolCalls)
+		stats.ByRole[message.Role] = role
+
+		stats.ToolCalls += len(message.ToolCalls)
+		stats.EstimatedTokens += estimateTokens(length)
+		for _, toolCall := range message.ToolCalls {
+			if toolCall.Function == nil {
+				continue
+			}
+			arguments, _ := json.Marshal(toolCall.Function.Arguments)
+			stats.EstimatedTokens += estimateTokens(len(toolCall.Function.Name) + len(arguments))
+		}
+	}
+	for role, length := range lengths {
+		roleStats := stats.ByRole[role]
+		roleStats.AverageLength = float64(length) / float64(roleStats.Messages)
+		stats.ByRole[role] = roleStats
+	}
+	return stats
+}
+
+// StatsSatisfy matches when the statistics of the conversation satisfy check, e.g. to assert
+// the agent asks at most one question per message.
+func StatsSatisfy(description string, check func(stats ConversationStatistics) bool) Matcher {
+	return matcherFunc{
+		description: description,
+		match: func(conversation []Message) bool {
+			return check(ConversationStats(conversation))
+		},
+	}
+}
+
+// countQuestions counts the questions of the text as runs of question marks.
+func countQuestions(text string) int {
+	count := 0
+	for i := range len(text) {
+		if text[i] == '?' && (i == 0 || text[i-1] != '?') {
+			count++
+		}
+	}
+	return count
+}
+
+// estimateTokens estimates the number of tokens of a text of the given number of characters.
+func estimateTokens(chars int) int {
+	return (chars + 3) / 4
+}
@@ -0,0 +1,34 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationStats(t *testing.T) {
+	stats := ConversationStats([]Message{
+		{Role: MessageRoleUser, Content: "Can you help?"},
+		{Role: MessageRoleAssistant, Content: "Sure! What city?? And when?", ToolCalls: []ToolCall{
+			{ID: "call_1", Function: &ToolCallFunction{Name: "lookup", Arguments: map[string]any{"q": "x"}}},
+		}},
+		{Role: MessageRoleUser, Content: "Paris"},
+	})
+
+	assert.Equal(t, 3, stats.Messages)
+	assert.Equal(t, 1, stats.ToolCalls)
+	assert.Equal(t, RoleStatistics{Messages: 2, AverageLength: 9, Questions: 1}, stats.ByRole[MessageRoleUser])
+	assert.Equal(t, RoleStatistics{Messages: 1, AverageLength: 27, Questions: 2, ToolCalls: 1}, stats.ByRole[MessageRoleAssistant])
+	assert.Equal(t, 4+7+4+2, stats.EstimatedTokens)
+}
+
+func TestStatsSatisfy(t *testing.T) {
+	oneQuestion := StatsSatisfy("agent asks at most one question per message", func(stats ConversationStatistics) bool {
+		agent := stats.ByRole[MessageRoleAssistant]
+		return agent.Questions <= agent.Messages
+	})
+
+	assert.True(t, oneQuestion.Match([]Message{{Role: MessageRoleAssistant, Content: "Which city?"}}))
+	assert.False(t, oneQuestion.Match([]Message{{Role: MessageRoleAssistant, Content: "Which city? When?"}}))
+	assert.Equal(t, "agent asks at most one question per message", oneQuestion.String())
+}
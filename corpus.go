@@ -0,0 +1,62 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+)
+
+// ConversationCorpus is a set of real, anonymized conversations scenarios can start from, see
+// WithConversationSeed.
+type ConversationCorpus struct {
+	conversations [][]Message
+}
+
+// NewConversationCorpus creates a corpus of the given conversations.
+func NewConversationCorpus(conversations ...[]Message) *ConversationCorpus {
+	return &ConversationCorpus{conversations: conversations}
+}
+
+// LoadConversationCorpus loads the conversations of the .json files of the directory, each
+// holding an array of messages, e.g. [{"role": "user", "content": "hi"}].
+func LoadConversationCorpus(dir string) (*ConversationCorpus, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no conversations found in %s", dir)
+	}
+
+	corpus := &ConversationCorpus{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var conversation []Message
+		if err := json.Unmarshal(data, &conversation); err != nil {
+			return nil, fmt.Errorf("failed to decode conversation %s: %w", path, err)
+		}
+		corpus.conversations = append(corpus.conversations, conversation)
+	}
+
+	return corpus, nil
+}
+
+// Len returns the number of conversations of the corpus.
+func (c *ConversationCorpus) Len() int {
+	return len(c.conversations)
+}
+
+// sample returns a copy of a conversation of the corpus picked with the random generator.
+func (c *ConversationCorpus) sample(rng *rand.Rand) ([]Message, error) {
+	if len(c.conversations) == 0 {
+		return nil, errors.New("conversation corpus is empty")
+	}
+
+	return append([]Message{}, c.conversations[rng.IntN(len(c.conversations))]...), nil
+}
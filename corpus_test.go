@@ -0,0 +1,86 @@
+package scenario
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConversationCorpus(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`[{"role": "user", "content": "my order is late"}, {"role": "assistant", "content": "sorry, let me check"}]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[{"role": "user", "content": "cancel my plan"}]`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`ignored`), 0o644))
+
+	corpus, err := LoadConversationCorpus(dir)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, corpus.Len())
+	assert.Equal(t, []Message{
+		{Role: MessageRoleUser, Content: "my order is late"},
+		{Role: MessageRoleAssistant, Content: "sorry, let me check"},
+	}, corpus.conversations[0])
+
+	_, err = LoadConversationCorpus(t.TempDir())
+	assert.ErrorContains(t, err, "no conversations found")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.json"), []byte(`{`), 0o644))
+	_, err = LoadConversationCorpus(dir)
+	assert.ErrorContains(t, err, "failed to decode conversation")
+}
+
+// mockHistoryAgent is a mock implementation of the HistoryAgent interface.
+type mockHistoryAgent struct {
+	mockAgent
+	history []Message
+}
+
+func (m *mockHistoryAgent) LoadHistory(ctx context.Context, history []Message) error {
+	m.history = history
+	return nil
+}
+
+func TestScenario_Run_ConversationSeed(t *testing.T) {
+	ctx := context.Background()
+	corpus := NewConversationCorpus(
+		[]Message{{Role: MessageRoleUser, Content: "my order is late"}},
+		[]Message{{Role: MessageRoleUser, Content: "cancel my plan"}},
+		[]Message{{Role: MessageRoleUser, Content: "where is my refund"}},
+	)
+
+	run := func(seed int64) ([]Message, *Result) {
+		agent := &mockHistoryAgent{}
+		result, err := NewScenario(
+			WithAgent(agent),
+			WithTestingAgent(&mockTestingAgent{}),
+			WithConversationSeed(corpus),
+			WithSeed(seed),
+		).Run(ctx)
+		require.NoError(t, err)
+		return agent.history, result
+	}
+
+	history, result := run(1)
+	require.Len(t, history, 1)
+	assert.Equal(t, history[0], result.Conversation[0], "the conversation starts from the seed")
+	again, _ := run(1)
+	assert.Equal(t, history, again, "the same seed samples the same conversation")
+
+	seen := map[string]bool{}
+	for seed := range int64(20) {
+		history, _ := run(seed)
+		seen[history[0].Content] = true
+	}
+	assert.Len(t, seen, 3, "different seeds sample different conversations")
+
+	_, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithConversationSeed(corpus),
+	).Run(ctx)
+	assert.EqualError(t, err, "conversation seed set but agent does not implement HistoryAgent")
+}
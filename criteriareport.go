@@ -0,0 +1,103 @@
+package scenario
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CriterionStats is the aggregated outcome of a criterion across results.
+type CriterionStats struct {
+	// Criterion is the criterion.
+	Criterion string
+
+	// Referenced is the number of results in which the criterion was evaluated.
+	Referenced int
+
+	// Passed is the number of results in which the criterion was met.
+	Passed int
+
+	// Failed is the number of results in which the criterion was unmet or triggered.
+	Failed int
+}
+
+// PassRate returns the ratio of results in which the criterion passed.
+func (c CriterionStats) PassRate() float64 {
+	if c.Referenced == 0 {
+		return 0
+	}
+	return float64(c.Passed) / float64(c.Referenced)
+}
+
+// CriteriaReport aggregates the outcome of every distinct criterion across results, e.g. all
+// the results of a suite, to highlight systematically failing criteria.
+type CriteriaReport struct {
+	// Criteria is the stats of every distinct criterion, sorted by ascending pass rate.
+	Criteria []CriterionStats
+}
+
+// NewCriteriaReport aggregates the met, unmet and triggered criteria of the results.
+func NewCriteriaReport(results []*Result) *CriteriaReport {
+	stats := map[string]*CriterionStats{}
+	record := func(criterion string, passed bool) {
+		s, ok := stats[criterion]
+		if !ok {
+			s = &CriterionStats{Criterion: criterion}
+			stats[criterion] = s
+		}
+		s.Referenced++
+		if passed {
+			s.Passed++
+		} else {
+			s.Failed++
+		}
+	}
+
+	for _, result := range results {
+		for _, criterion := range result.MetCriteria {
+			record(criterion, true)
+		}
+		for _, criterion := range result.UnmetCriteria {
+			record(criterion, false)
+		}
+		for _, criterion := range result.TriggeredFailures {
+			record(criterion, false)
+		}
+	}
+
+	report := &CriteriaReport{}
+	for _, s := range stats {
+		report.Criteria = append(report.Criteria, *s)
+	}
+	slices.SortFunc(report.Criteria, func(a, b CriterionStats) int {
+		if a.PassRate() != b.PassRate() {
+			if a.PassRate() < b.PassRate() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Criterion, b.Criterion)
+	})
+
+	return report
+}
+
+// Failing returns the criteria with a pass rate below the threshold.
+func (r *CriteriaReport) Failing(threshold float64) []CriterionStats {
+	var failing []CriterionStats
+	for _, c := range r.Criteria {
+		if c.PassRate() < threshold {
+			failing = append(failing, c)
+		}
+	}
+	return failing
+}
+
+// String renders the report with one criterion per line.
+func (r *CriteriaReport) String() string {
+	var sb strings.Builder
+	for _, c := range r.Criteria {
+		fmt.Fprintf(&sb, "%5.1f%% (%d/%d) %s\n", c.PassRate()*100, c.Passed, c.Referenced, c.Criterion)
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCriteriaReport(t *testing.T) {
+	results := []*Result{
+		{MetCriteria: []string{"vegetarian", "ingredients"}, UnmetCriteria: []string{"instructions"}},
+		{MetCriteria: []string{"vegetarian", "instructions"}, UnmetCriteria: []string{"ingredients"}},
+		{MetCriteria: []string{"vegetarian"}, UnmetCriteria: []string{"instructions"}, TriggeredFailures: []string{"includes meat"}},
+	}
+
+	report := NewCriteriaReport(results)
+
+	require.Len(t, report.Criteria, 4)
+	assert.Equal(t, CriterionStats{Criterion: "includes meat", Referenced: 1, Failed: 1}, report.Criteria[0])
+	assert.Equal(t, CriterionStats{Criterion: "instructions", Referenced: 3, Passed: 1, Failed: 2}, report.Criteria[1])
+	assert.Equal(t, CriterionStats{Criterion: "ingredients", Referenced: 2, Passed: 1, Failed: 1}, report.Criteria[2])
+	assert.Equal(t, CriterionStats{Criterion: "vegetarian", Referenced: 3, Passed: 3}, report.Criteria[3])
+
+	failing := report.Failing(0.5)
+	require.Len(t, failing, 2)
+	assert.Equal(t, "includes meat", failing[0].Criterion)
+	assert.Equal(t, "instructions", failing[1].Criterion)
+
+	assert.Contains(t, report.String(), " 33.3% (1/3) instructions\n")
+	assert.Equal(t, 0.0, CriterionStats{}.PassRate())
+}
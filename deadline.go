@@ -0,0 +1,38 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout is the error of a run cancelled by the hard timeout set with WithHardTimeout, or
+// whose verdict overran the grace period of the soft deadline set with WithSoftDeadline.
+var ErrTimeout = errors.New("scenario run timed out")
+
+// softDeadlineReached reports whether the soft deadline of the run is reached.
+func (s *scenario) softDeadlineReached() bool {
+	return s.softDeadline > 0 && time.Since(s.testStart) >= s.softDeadline
+}
+
+// withHardTimeout returns a context cancelled with ErrTimeout after the hard timeout.
+func (s *scenario) withHardTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.hardTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, s.hardTimeout, ErrTimeout)
+}
+
+// withGracePeriod returns a context cancelled with ErrTimeout after the grace period, for the
+// verdict asked once the soft deadline is reached.
+func (s *scenario) withGracePeriod(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.gracePeriod <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, s.gracePeriod, ErrTimeout)
+}
+
+// timedOut reports whether the context was cancelled by a hard timeout or a grace period.
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrTimeout)
+}
@@ -0,0 +1,97 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_SoftDeadline(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			time.Sleep(20 * time.Millisecond)
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+		},
+	}
+	var lastMessages []bool
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			lastMessages = append(lastMessages, lastMessage)
+			if lastMessage {
+				return nil, &Result{Success: true, Conversation: conversation}, nil
+			}
+			msg := "User message"
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithMaxTurns(10),
+		WithSoftDeadline(10*time.Millisecond, time.Second),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.True(t, result.DeadlineReached)
+	assert.Len(t, result.Turns, 1, "no new turn is started after the deadline")
+	assert.Equal(t, []bool{false, true}, lastMessages)
+}
+
+func TestScenario_Run_SoftDeadline_GracePeriod(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			time.Sleep(20 * time.Millisecond)
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+		},
+	}
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if lastMessage {
+				<-ctx.Done()
+				return nil, nil, ctx.Err()
+			}
+			msg := "User message"
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithSoftDeadline(10*time.Millisecond, 10*time.Millisecond),
+	)
+
+	_, err := s.Run(ctx)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestScenario_Run_HardTimeout(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithHardTimeout(10*time.Millisecond),
+	)
+
+	result, err := s.Run(ctx)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.False(t, result.Success)
+}
@@ -0,0 +1,129 @@
+package scenario
+
+import (
+	"context"
+	"sync"
+)
+
+// DiagnosticCode identifies a kind of non-fatal anomaly of a run.
+type DiagnosticCode string
+
+const (
+	// DiagnosticProviderRetry is a request to an LLM provider retried after a failure.
+	DiagnosticProviderRetry DiagnosticCode = "provider_retry"
+
+	// DiagnosticVerdictProtocolFallback is the testing agent falling back to the text verdict
+	// protocol because the model rejected tool calling.
+	DiagnosticVerdictProtocolFallback DiagnosticCode = "verdict_protocol_fallback"
+
+	// DiagnosticSimulatorRegenerated is a message of the simulated user regenerated because
+	// it quoted the scenario, see WithCriteriaLeakGuard.
+	DiagnosticSimulatorRegenerated DiagnosticCode = "simulator_regenerated"
+
+	// DiagnosticConversationTruncated is the conversation judged by the testing agent being
+	// changed by the truncation policies set with WithTruncation.
+	DiagnosticConversationTruncated DiagnosticCode = "conversation_truncated"
+
+	// DiagnosticContextWindowElided is messages elided for the conversation to fit in the
+	// context window of the model of the testing agent.
+	DiagnosticContextWindowElided DiagnosticCode = "context_window_elided"
+
+	// DiagnosticArtifactTruncated is a text artifact truncated in the transcript, see
+	// WithArtifactTextLimit.
+	DiagnosticArtifactTruncated DiagnosticCode = "artifact_truncated"
+
+	// DiagnosticCacheStoreFailed is a result that could not be stored in the result cache.
+	DiagnosticCacheStoreFailed DiagnosticCode = "cache_store_failed"
+
+	// DiagnosticMemoryStoreFailed is a conversation that could not be stored in the persona
+	// memory store set with WithPersonaMemory.
+	DiagnosticMemoryStoreFailed DiagnosticCode = "memory_store_failed"
+
+	// DiagnosticCircuitOpen is a call to an LLM provider failed fast by an open
+	// CircuitBreaker.
+	DiagnosticCircuitOpen DiagnosticCode = "circuit_open"
+
+	// DiagnosticPartialJudgmentFailed is the partial conversation of an aborted or timed out
+	// run that could not be judged, see WithPartialJudgment.
+	DiagnosticPartialJudgmentFailed DiagnosticCode = "partial_judgment_failed"
+
+	// DiagnosticCallRetried is a call of the agent or the testing agent retried after a
+	// transient error, see WithRetryPolicy.
+	DiagnosticCallRetried DiagnosticCode = "call_retried"
+
+	// DiagnosticTopicDrift is the conversation drifting off the allowed topics, recorded
+	// instead of failing the scenario with TopicGuard.Warn, see WithAllowedTopics.
+	DiagnosticTopicDrift DiagnosticCode = "topic_drift"
+
+	// DiagnosticCanaryDisagreement is a verdict of the canary judge of WithCanaryJudge that
+	// disagrees with the verdict of the testing agent.
+	DiagnosticCanaryDisagreement DiagnosticCode = "canary_disagreement"
+
+	// DiagnosticCriteriaUnmatched is success criteria the verdict of the testing agent lists
+	// neither as met nor as unmet, scored 0 without overturning a successful verdict, see
+	// WithPassingScore.
+	DiagnosticCriteriaUnmatched DiagnosticCode = "criteria_unmatched"
+
+	// DiagnosticCanaryFailed is the canary judge of WithCanaryJudge failing to give a verdict.
+	DiagnosticCanaryFailed DiagnosticCode = "canary_failed"
+)
+
+// Diagnostic is a non-fatal anomaly encountered during a run, kept in Result.Diagnostics for
+// post-hoc analysis of odd runs.
+type Diagnostic struct {
+	// Code identifies the kind of anomaly.
+	Code DiagnosticCode `json:"code"`
+
+	// Component is the component of the run the anomaly happened in, set from the context.
+	Component Component `json:"component,omitempty"`
+
+	// Message describes the anomaly.
+	Message string `json:"message"`
+
+	// Turn is the turn of the run the anomaly happened at, set from the context.
+	Turn int `json:"turn"`
+
+	// Retryable is whether the operation was, or could have been, retried.
+	Retryable bool `json:"retryable"`
+}
+
+// diagnostics is an append-only log of the diagnostics of a scenario run.
+type diagnostics struct {
+	mu          sync.Mutex
+	diagnostics []Diagnostic
+}
+
+type diagnosticsContextKey struct{}
+
+// withDiagnostics returns a context carrying a new diagnostics log.
+func withDiagnostics(ctx context.Context) (context.Context, *diagnostics) {
+	d := &diagnostics{}
+	return context.WithValue(ctx, diagnosticsContextKey{}, d), d
+}
+
+// RecordDiagnostic records a diagnostic on the scenario run in the context, attributing it to
+// the component and turn of the context when not set. It is a no-op outside of a scenario run.
+// Agents, LLMCompletion implementations and guardrails can call it to surface anomalies in
+// Result.Diagnostics.
+func RecordDiagnostic(ctx context.Context, diagnostic Diagnostic) {
+	d, ok := ctx.Value(diagnosticsContextKey{}).(*diagnostics)
+	if !ok {
+		return
+	}
+
+	if scope, ok := ctx.Value(auditScopeContextKey{}).(auditScope); ok && diagnostic.Component == "" {
+		diagnostic.Component = scope.component
+		diagnostic.Turn = scope.turn
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.diagnostics = append(d.diagnostics, diagnostic)
+}
+
+// Diagnostics returns a copy of the diagnostics recorded so far.
+func (d *diagnostics) Diagnostics() []Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Diagnostic{}, d.diagnostics...)
+}
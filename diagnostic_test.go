@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordDiagnostic(t *testing.T) {
+	RecordDiagnostic(context.Background(), Diagnostic{Code: DiagnosticProviderRetry})
+
+	ctx, diagnostics := withDiagnostics(context.Background())
+	RecordDiagnostic(withAuditScope(ctx, ComponentJudge, 3), Diagnostic{Code: DiagnosticProviderRetry, Message: "retry"})
+	RecordDiagnostic(withAuditScope(ctx, ComponentJudge, 3), Diagnostic{Code: "guardrail_near_miss", Component: ComponentGuardrail, Turn: 1})
+
+	assert.Equal(t, []Diagnostic{
+		{Code: DiagnosticProviderRetry, Component: ComponentJudge, Message: "retry", Turn: 3},
+		{Code: "guardrail_near_miss", Component: ComponentGuardrail, Turn: 1},
+	}, diagnostics.Diagnostics())
+}
+
+func TestScenario_Run_Diagnostics(t *testing.T) {
+	agent := &mockArtifactProducer{
+		mockAgent: mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			RecordDiagnostic(ctx, Diagnostic{Code: "guardrail_near_miss", Message: "PII score 0.49"})
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+		}},
+		artifacts: map[int][]Artifact{0: {{Name: "log.txt", ContentType: "text/plain", Data: []byte("0123456789")}}},
+	}
+
+	s := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithArtifactTextLimit(4),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []Diagnostic{
+		{Code: "guardrail_near_miss", Component: ComponentAgent, Message: "PII score 0.49"},
+		{Code: DiagnosticArtifactTruncated, Component: ComponentAgent, Message: "artifact log.txt of 10 bytes truncated to 4 bytes"},
+	}, result.Diagnostics)
+}
+
+func TestOpenAICompletion_RetryDiagnostics(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithMaxRetries(1))
+	ctx, diagnostics := withDiagnostics(context.Background())
+
+	_, err := completion.Completion(withAuditScope(ctx, ComponentSimulator, 2), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Diagnostic{{
+		Code:      DiagnosticProviderRetry,
+		Component: ComponentSimulator,
+		Message:   "retrying openai chat/completions request, attempt 2",
+		Turn:      2,
+		Retryable: true,
+	}}, diagnostics.Diagnostics())
+}
@@ -0,0 +1,130 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TurnDiff is the comparison of a single turn between two conversations.
+type TurnDiff struct {
+	// Turn is the zero-based index of the turn.
+	Turn int
+
+	// A is the messages of the turn in the first conversation.
+	A []Message
+
+	// B is the messages of the turn in the second conversation.
+	B []Message
+
+	// Equal is true if the turn is identical in both conversations.
+	Equal bool
+}
+
+// ConversationDiff is the turn by turn comparison of two conversations.
+type ConversationDiff struct {
+	// Turns is the comparison of every turn, aligned by turn index.
+	Turns []TurnDiff
+
+	// FirstDivergentTurn is the first turn that differs between the conversations, or -1 if they are identical.
+	FirstDivergentTurn int
+
+	// DivergentRole is the role of the first differing message, telling whether the user or the agent diverged first.
+	DivergentRole MessageRole
+}
+
+// DiffConversations aligns two conversations by turn, a turn being a user message and
+// everything that follows it until the next user message, and compares them.
+func DiffConversations(a, b []Message) ConversationDiff {
+	turnsA, turnsB := splitTurns(a), splitTurns(b)
+	diff := ConversationDiff{
+		Turns:              make([]TurnDiff, max(len(turnsA), len(turnsB))),
+		FirstDivergentTurn: -1,
+	}
+
+	for i := range diff.Turns {
+		turn := TurnDiff{Turn: i}
+		if i < len(turnsA) {
+			turn.A = turnsA[i]
+		}
+		if i < len(turnsB) {
+			turn.B = turnsB[i]
+		}
+
+		index := firstDifference(turn.A, turn.B)
+		turn.Equal = index == -1
+		if !turn.Equal && diff.FirstDivergentTurn == -1 {
+			diff.FirstDivergentTurn = i
+			if index < len(turn.A) {
+				diff.DivergentRole = turn.A[index].Role
+			} else {
+				diff.DivergentRole = turn.B[index].Role
+			}
+		}
+		diff.Turns[i] = turn
+	}
+
+	return diff
+}
+
+// DiffRepetitions compares the conversation of every repetition of a scenario with the first one.
+func DiffRepetitions(results []*Result) []ConversationDiff {
+	if len(results) < 2 {
+		return nil
+	}
+
+	diffs := make([]ConversationDiff, len(results)-1)
+	for i, result := range results[1:] {
+		diffs[i] = DiffConversations(results[0].Conversation, result.Conversation)
+	}
+
+	return diffs
+}
+
+// String renders the diff turn by turn, marking the turns that differ.
+func (d ConversationDiff) String() string {
+	if d.FirstDivergentTurn == -1 {
+		return "conversations are identical\n"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "conversations diverged first at turn %d (%s)\n", d.FirstDivergentTurn, d.DivergentRole)
+	for _, turn := range d.Turns {
+		if turn.Equal {
+			fmt.Fprintf(&sb, "  turn %d: equal\n", turn.Turn)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "  turn %d: differs\n", turn.Turn)
+		for _, message := range turn.A {
+			fmt.Fprintf(&sb, "    - %s: %s\n", message.Role, message.Content)
+		}
+		for _, message := range turn.B {
+			fmt.Fprintf(&sb, "    + %s: %s\n", message.Role, message.Content)
+		}
+	}
+
+	return sb.String()
+}
+
+// splitTurns splits a conversation into turns, each starting with a user message.
+func splitTurns(conversation []Message) [][]Message {
+	var turns [][]Message
+	for _, turn := range conversationTurns(conversation) {
+		turns = append(turns, turn)
+	}
+	return turns
+}
+
+// firstDifference returns the index of the first message that differs between a and b, or -1.
+func firstDifference(a, b []Message) int {
+	for i := range max(len(a), len(b)) {
+		if i >= len(a) || i >= len(b) {
+			return i
+		}
+		if a[i].Role != b[i].Role || a[i].Content != b[i].Content {
+			return i
+		}
+	}
+
+	return -1
+}
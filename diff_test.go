@@ -0,0 +1,56 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConversations(t *testing.T) {
+	a := []Message{
+		{Role: MessageRoleUser, Content: "dinner idea"},
+		{Role: MessageRoleAssistant, Content: "any allergies?"},
+		{Role: MessageRoleUser, Content: "no"},
+		{Role: MessageRoleAssistant, Content: "try a lentil curry"},
+	}
+	b := []Message{
+		{Role: MessageRoleUser, Content: "dinner idea"},
+		{Role: MessageRoleAssistant, Content: "any allergies?"},
+		{Role: MessageRoleUser, Content: "no"},
+		{Role: MessageRoleAssistant, Content: "try a mushroom risotto"},
+		{Role: MessageRoleUser, Content: "thanks"},
+	}
+
+	diff := DiffConversations(a, b)
+
+	require.Len(t, diff.Turns, 3)
+	assert.Equal(t, 1, diff.FirstDivergentTurn)
+	assert.Equal(t, MessageRoleAssistant, diff.DivergentRole)
+	assert.True(t, diff.Turns[0].Equal)
+	assert.False(t, diff.Turns[1].Equal)
+	assert.False(t, diff.Turns[2].Equal)
+	assert.Empty(t, diff.Turns[2].A)
+	assert.Contains(t, diff.String(), "diverged first at turn 1 (assistant)")
+	assert.Contains(t, diff.String(), "+ assistant: try a mushroom risotto")
+
+	identical := DiffConversations(a, a)
+	assert.Equal(t, -1, identical.FirstDivergentTurn)
+	assert.Equal(t, "conversations are identical\n", identical.String())
+}
+
+func TestDiffRepetitions(t *testing.T) {
+	results := []*Result{
+		{Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}}},
+		{Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}}},
+		{Conversation: []Message{{Role: MessageRoleUser, Content: "hello"}}},
+	}
+
+	diffs := DiffRepetitions(results)
+
+	require.Len(t, diffs, 2)
+	assert.Equal(t, -1, diffs[0].FirstDivergentTurn)
+	assert.Equal(t, 0, diffs[1].FirstDivergentTurn)
+	assert.Equal(t, MessageRoleUser, diffs[1].DivergentRole)
+	assert.Nil(t, DiffRepetitions(results[:1]))
+}
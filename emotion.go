@@ -0,0 +1,119 @@
+package scenario
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EmotionalState is the emotional state of the simulated user.
+type EmotionalState string
+
+const (
+	// EmotionalStateNeutral is the state of a user whose request is still being handled.
+	EmotionalStateNeutral EmotionalState = "neutral"
+
+	// EmotionalStateFrustrated is the state of a user whose request went unresolved for too long.
+	EmotionalStateFrustrated EmotionalState = "frustrated"
+
+	// EmotionalStateDeEscalated is the state of a frustrated user calmed down by the agent
+	// acknowledging the frustration.
+	EmotionalStateDeEscalated EmotionalState = "de_escalated"
+)
+
+// acknowledgementPattern matches agent messages acknowledging the frustration of the user.
+var acknowledgementPattern = regexp.MustCompile(`(?i)\b(sorry|apolog(y|ies|i[sz]e)|frustrat(ed|ing|ion))\b`)
+
+// EmotionalArc configures how the emotional state of the simulated user evolves over the scenario.
+// The user starts neutral and grows frustrated if the request is unresolved by FrustratedAfterTurn,
+// the zero value making the user frustrated from the first turn. With DeEscalate, the user then
+// calms down once the agent acknowledges the frustration, e.g. by apologizing, which is detected
+// in the replies of the agent. NeverFrustrated keeps the user neutral instead.
+type EmotionalArc struct {
+	// FrustratedAfterTurn is the zero-based turn from which the user becomes frustrated, 0 for
+	// the first turn.
+	FrustratedAfterTurn int
+
+	// DeEscalate allows the user to calm down again once the agent acknowledges the frustration.
+	DeEscalate bool
+
+	// NeverFrustrated keeps the user neutral for the whole scenario, e.g. for a baseline run of
+	// a scenario otherwise played with a frustrated user.
+	NeverFrustrated bool
+}
+
+// next returns the emotional state of the simulated user at the zero-based turn, from the state
+// at the previous turn and the replies of the agent to it.
+func (a EmotionalArc) next(previous EmotionalState, turn int, replies []Message) EmotionalState {
+	switch {
+	case previous == EmotionalStateDeEscalated:
+		return EmotionalStateDeEscalated
+	case previous == EmotionalStateFrustrated && a.DeEscalate && acknowledgesFrustration(replies):
+		return EmotionalStateDeEscalated
+	case previous == EmotionalStateFrustrated:
+		return EmotionalStateFrustrated
+	case !a.NeverFrustrated && turn >= a.FrustratedAfterTurn:
+		return EmotionalStateFrustrated
+	default:
+		return EmotionalStateNeutral
+	}
+}
+
+// instructions returns the emotional state instructions for the testing agent, given the
+// states of the user at every turn so far, the last one being the current turn.
+func (a EmotionalArc) instructions(states []EmotionalState) string {
+	turn := len(states) - 1
+	instructions := fmt.Sprintf("<emotional_state>\nAt turn %d the user is %s.", turn, states[turn])
+	switch states[turn] {
+	case EmotionalStateFrustrated:
+		instructions += " The request was not resolved in time, show growing frustration in your messages."
+		if a.DeEscalate {
+			instructions += " If the agent acknowledges your frustration, calm down."
+		}
+	case EmotionalStateDeEscalated:
+		instructions += " The agent acknowledged your frustration, stay calm while making sure the request gets resolved."
+	}
+	if turn > 0 {
+		previous := make([]string, turn)
+		for i, state := range states[:turn] {
+			previous[i] = fmt.Sprintf("turn %d %s", i, state)
+		}
+		instructions += "\nStates at the previous turns: " + strings.Join(previous, ", ") + "."
+	}
+	return instructions + "\n</emotional_state>"
+}
+
+// acknowledgesFrustration reports whether an agent message acknowledges the frustration of the
+// user.
+func acknowledgesFrustration(replies []Message) bool {
+	for _, message := range replies {
+		if message.Role == MessageRoleAssistant && acknowledgementPattern.MatchString(message.Content) {
+			return true
+		}
+	}
+	return false
+}
+
+// emotionalState returns the emotional state of the simulated user at the zero-based turn,
+// following the arc from the states of the previous turns and the last replies of the agent.
+func (s *scenario) emotionalState(turn int) EmotionalState {
+	for len(s.emotionalStates) <= turn {
+		previous := EmotionalStateNeutral
+		if n := len(s.emotionalStates); n > 0 {
+			previous = s.emotionalStates[n-1]
+		}
+		state := s.emotionalArc.next(previous, len(s.emotionalStates), lastReplies(s.conversation))
+		s.emotionalStates = append(s.emotionalStates, state)
+	}
+	return s.emotionalStates[turn]
+}
+
+// lastReplies returns the messages of the conversation after the last user message.
+func lastReplies(conversation []Message) []Message {
+	for i := len(conversation) - 1; i >= 0; i-- {
+		if conversation[i].Role == MessageRoleUser {
+			return conversation[i+1:]
+		}
+	}
+	return nil
+}
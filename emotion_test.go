@@ -0,0 +1,76 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmotionalArc_next(t *testing.T) {
+	apology := []Message{{Role: MessageRoleAssistant, Content: "Sorry for the wait, I'm on it."}}
+	unhelpful := []Message{{Role: MessageRoleAssistant, Content: "Please hold."}}
+
+	arc := EmotionalArc{FrustratedAfterTurn: 2}
+	assert.Equal(t, EmotionalStateNeutral, arc.next(EmotionalStateNeutral, 1, unhelpful))
+	assert.Equal(t, EmotionalStateFrustrated, arc.next(EmotionalStateNeutral, 2, unhelpful))
+	assert.Equal(t, EmotionalStateFrustrated, arc.next(EmotionalStateFrustrated, 3, apology), "no de-escalation without DeEscalate")
+
+	arc.DeEscalate = true
+	assert.Equal(t, EmotionalStateFrustrated, arc.next(EmotionalStateFrustrated, 3, unhelpful))
+	assert.Equal(t, EmotionalStateDeEscalated, arc.next(EmotionalStateFrustrated, 3, apology))
+	assert.Equal(t, EmotionalStateDeEscalated, arc.next(EmotionalStateDeEscalated, 4, unhelpful))
+
+	immediate := EmotionalArc{}
+	assert.Equal(t, EmotionalStateFrustrated, immediate.next(EmotionalStateNeutral, 0, nil), "the zero value is frustrated from the first turn")
+
+	never := EmotionalArc{NeverFrustrated: true}
+	assert.Equal(t, EmotionalStateNeutral, never.next(EmotionalStateNeutral, 0, nil))
+	assert.Equal(t, EmotionalStateNeutral, never.next(EmotionalStateNeutral, 5, unhelpful))
+}
+
+func TestScenario_Run_EmotionalArc(t *testing.T) {
+	ctx := context.Background()
+	var strategies []string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			strategies = append(strategies, strategy)
+			msg := "User message"
+			return &msg, nil, nil
+		},
+	}
+	replies := []string{"Let me check.", "Still checking.", "Sorry for the delay, your refund is on its way."}
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			reply := replies[0]
+			replies = replies[1:]
+			return []Message{{Role: MessageRoleAssistant, Content: reply}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithStrategy("test strategy"),
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithEmotionalArc(EmotionalArc{FrustratedAfterTurn: 1, DeEscalate: true}),
+		WithMaxTurns(3),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.Len(t, strategies, 4)
+	assert.Contains(t, strategies[0], "test strategy")
+	assert.Contains(t, strategies[0], "At turn 0 the user is neutral.")
+	assert.Contains(t, strategies[1], "At turn 1 the user is frustrated.")
+	assert.Contains(t, strategies[1], "If the agent acknowledges your frustration, calm down.")
+	assert.Contains(t, strategies[2], "At turn 2 the user is frustrated.")
+	assert.Contains(t, strategies[3], "At turn 3 the user is de_escalated.")
+	assert.Contains(t, strategies[3], "States at the previous turns: turn 0 neutral, turn 1 frustrated, turn 2 frustrated.", "the judge sees the state of every turn")
+
+	require.Len(t, result.Turns, 3)
+	assert.Equal(t, EmotionalStateNeutral, result.Turns[0].EmotionalState)
+	assert.Equal(t, EmotionalStateFrustrated, result.Turns[1].EmotionalState)
+	assert.Equal(t, EmotionalStateFrustrated, result.Turns[2].EmotionalState)
+}
@@ -1,7 +1,6 @@
 package examples_test
 
 import (
-	"context"
 	"testing"
 
 	"github.com/langwatch/scenario-go"
@@ -10,8 +9,7 @@ import (
 func Test_VegetarianRecipeAgentNormal(t *testing.T) {
 	t.Parallel()
 
-	ctx := context.Background()
-	sc := scenario.NewScenario(
+	scenario.RunT(t,
 		scenario.WithDescription("User is looking for a dinner idea"),
 		scenario.WithAgent(NewVegetarianRecipeAgent()),
 		scenario.WithTestingAgent(scenario.NewTestingAgent(scenario.NewOpenAICompletion("gpt-4o-mini"))),
@@ -25,22 +23,12 @@ func Test_VegetarianRecipeAgentNormal(t *testing.T) {
 			"The agent asks more than two follow-up questions",
 		),
 	)
-
-	result, err := sc.Run(ctx)
-	if err != nil {
-		t.Fatalf("scenario failed to run: %v", err)
-	}
-	if !result.Success {
-		result.LogResultDetails(t)
-		t.Errorf("expected success but got failure")
-	}
 }
 
 func Test_VegetarianRecipeAgentUberHungry(t *testing.T) {
 	t.Parallel()
 
-	ctx := context.Background()
-	sc := scenario.NewScenario(
+	scenario.RunT(t,
 		scenario.WithDescription("User is very very hungry, they say they could eat a cow"),
 		scenario.WithAgent(NewVegetarianRecipeAgent()),
 		scenario.WithTestingAgent(scenario.NewTestingAgent(scenario.NewOpenAICompletion("gpt-4o-mini"))),
@@ -54,13 +42,4 @@ func Test_VegetarianRecipeAgentUberHungry(t *testing.T) {
 			"The agent asks more than two follow-up questions",
 		),
 	)
-
-	result, err := sc.Run(ctx)
-	if err != nil {
-		t.Fatalf("scenario failed to run: %v", err)
-	}
-	if !result.Success {
-		result.LogResultDetails(t)
-		t.Errorf("expected success but got failure")
-	}
 }
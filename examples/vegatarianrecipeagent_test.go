@@ -10,8 +10,7 @@ import (
 )
 
 func TestVegetarianRecipeAgent(t *testing.T) {
-	ctx := context.Background()
-	sc := scenario.NewScenario(
+	scenario.RunT(t,
 		scenario.WithDescription("User is looking for a dinner idea"),
 		scenario.WithAgent(NewVegetarianRecipeAgent()),
 		scenario.WithTestingAgent(scenario.NewTestingAgent(scenario.NewOpenAICompletion("gpt-4o-mini"))),
@@ -25,15 +24,6 @@ func TestVegetarianRecipeAgent(t *testing.T) {
 			"The agent asks more than two follow-up questions",
 		),
 	)
-
-	result, err := sc.Run(ctx)
-	if err != nil {
-		t.Fatalf("scenario failed to run: %v", err)
-	}
-	if !result.Success {
-		result.LogResultDetails(t)
-		t.Errorf("expected success but got failure")
-	}
 }
 
 type VegetarianRecipeAgent struct {
@@ -42,16 +32,22 @@ type VegetarianRecipeAgent struct {
 }
 
 func NewVegetarianRecipeAgent() *VegetarianRecipeAgent {
-	return &VegetarianRecipeAgent{
-		history: []scenario.Message{{
-			Role: "system",
-			Content: `
+	a := &VegetarianRecipeAgent{
+		client: openai.NewClient(),
+	}
+	_ = a.Reset(context.Background())
+	return a
+}
+
+func (a *VegetarianRecipeAgent) Reset(ctx context.Context) error {
+	a.history = []scenario.Message{{
+		Role: "system",
+		Content: `
 You are a vegetarian recipe agent.
 Given the user request, ask AT MOST ONE follow-up question, then provide a complete recipe.
 Keep your responses concise and focused.`,
-		}},
-		client: openai.NewClient(),
-	}
+	}}
+	return nil
 }
 
 func (a *VegetarianRecipeAgent) Run(ctx context.Context, message string) ([]scenario.Message, error) {
@@ -70,7 +66,7 @@ func (a *VegetarianRecipeAgent) Run(ctx context.Context, message string) ([]scen
 		case scenario.MessageRoleAssistant:
 			openaiMessages[i] = openai.AssistantMessage(message.Content)
 		case scenario.MessageRoleDeveloper:
-			openaiMessages[i] = openai.SystemMessage(message.Content)
+			openaiMessages[i] = openai.DeveloperMessage(message.Content)
 		default:
 			return nil, fmt.Errorf("unknown message role: %s", message.Role)
 		}
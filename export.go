@@ -0,0 +1,345 @@
+package scenario
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// evalsMessage is a chat message in the OpenAI Evals and promptfoo formats.
+type evalsMessage struct {
+	Role    MessageRole `json:"role"`
+	Content string      `json:"content"`
+}
+
+// openAIEvalsSample is a single sample of an OpenAI Evals JSONL dataset.
+type openAIEvalsSample struct {
+	Input    []evalsMessage `json:"input"`
+	Ideal    string         `json:"ideal"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// promptfooTestCase is a single test case of a promptfoo tests file.
+type promptfooTestCase struct {
+	Description string            `json:"description,omitempty"`
+	Vars        map[string]any    `json:"vars"`
+	Assert      []promptfooAssert `json:"assert,omitempty"`
+}
+
+// promptfooAssert is an assertion of a promptfoo test case.
+type promptfooAssert struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// WriteOpenAIEvalsJSONL writes the results as an OpenAI Evals compatible JSONL dataset. Each
+// result becomes a sample whose input is the conversation up to the last assistant message,
+// which is used as the ideal answer.
+func WriteOpenAIEvalsJSONL(w io.Writer, results []*Result) error {
+	encoder := json.NewEncoder(w)
+	for i, result := range results {
+		input, ideal := splitLastAssistantMessage(result.Conversation)
+		sample := openAIEvalsSample{
+			Input: input,
+			Ideal: ideal,
+			Metadata: map[string]any{
+				"scenario_id":        result.ScenarioID,
+				"run_id":             result.RunID,
+				"success":            result.Success,
+				"reasoning":          result.Reasoning,
+				"met_criteria":       result.MetCriteria,
+				"unmet_criteria":     result.UnmetCriteria,
+				"triggered_failures": result.TriggeredFailures,
+			},
+		}
+		if err := encoder.Encode(sample); err != nil {
+			return fmt.Errorf("failed to encode result %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// WritePromptfooTests writes the results as a promptfoo compatible JSON tests file. The
+// conversation is exposed through the "messages" var and every criterion the judge evaluated
+// becomes an llm-rubric assertion.
+func WritePromptfooTests(w io.Writer, results []*Result) error {
+	testCases := make([]promptfooTestCase, len(results))
+	for i, result := range results {
+		input, _ := splitLastAssistantMessage(result.Conversation)
+		testCases[i] = promptfooTestCase{
+			Description: result.ScenarioID,
+			Vars: map[string]any{
+				"messages": input,
+			},
+		}
+		for _, criterion := range append(append([]string{}, result.MetCriteria...), result.UnmetCriteria...) {
+			testCases[i].Assert = append(testCases[i].Assert, promptfooAssert{
+				Type:  "llm-rubric",
+				Value: criterion,
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(testCases); err != nil {
+		return fmt.Errorf("failed to encode promptfoo tests: %w", err)
+	}
+
+	return nil
+}
+
+// labelStudioTask is a task of a Label Studio import file.
+type labelStudioTask struct {
+	Data        labelStudioData         `json:"data"`
+	Predictions []labelStudioPrediction `json:"predictions"`
+}
+
+// labelStudioData is the data of a Label Studio task.
+type labelStudioData struct {
+	Dialogue   []labelStudioUtterance `json:"dialogue"`
+	ScenarioID string                 `json:"scenario_id"`
+	RunID      string                 `json:"run_id"`
+	Success    bool                   `json:"success"`
+	Reasoning  string                 `json:"reasoning"`
+}
+
+// labelStudioUtterance is a message of the dialogue of a Label Studio task.
+type labelStudioUtterance struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// labelStudioPrediction is the pre-annotation of a Label Studio task.
+type labelStudioPrediction struct {
+	ModelVersion string                  `json:"model_version"`
+	Result       []labelStudioAnnotation `json:"result"`
+}
+
+// labelStudioAnnotation is a highlight of a message of the dialogue with a criterion label.
+type labelStudioAnnotation struct {
+	ID       string                     `json:"id"`
+	FromName string                     `json:"from_name"`
+	ToName   string                     `json:"to_name"`
+	Type     string                     `json:"type"`
+	Value    labelStudioParagraphsValue `json:"value"`
+}
+
+// labelStudioParagraphsValue is the value of a paragraph labels annotation.
+type labelStudioParagraphsValue struct {
+	Start           string   `json:"start"`
+	End             string   `json:"end"`
+	StartOffset     int      `json:"startOffset"`
+	EndOffset       int      `json:"endOffset"`
+	Text            string   `json:"text"`
+	ParagraphLabels []string `json:"paragraphlabels"`
+}
+
+// WriteLabelStudioTasks writes the results as a Label Studio JSON import file for human review.
+// The judged conversation is the "dialogue" of each task, and every message the judge cited as
+// evidence is pre-annotated with a "<status>: <criterion>" label. The labeling config should
+// have a Paragraphs tag named "dialogue" and a ParagraphLabels tag named "criteria".
+func WriteLabelStudioTasks(w io.Writer, results []*Result) error {
+	tasks := make([]labelStudioTask, len(results))
+	for i, result := range results {
+		conversation := result.JudgedMessages()
+		task := labelStudioTask{
+			Data: labelStudioData{
+				Dialogue:   make([]labelStudioUtterance, len(conversation)),
+				ScenarioID: result.ScenarioID,
+				RunID:      result.RunID,
+				Success:    result.Success,
+				Reasoning:  result.Reasoning,
+			},
+		}
+		for j, message := range conversation {
+			task.Data.Dialogue[j] = labelStudioUtterance{Author: string(message.Role), Text: message.Content}
+		}
+
+		annotations := []labelStudioAnnotation{}
+		for _, criterion := range result.CriterionResults() {
+			for _, index := range criterion.Evidence {
+				if index < 0 || index >= len(conversation) {
+					continue
+				}
+				text := conversation[index].Content
+				annotations = append(annotations, labelStudioAnnotation{
+					ID:       fmt.Sprintf("%d-%d", i, len(annotations)),
+					FromName: "criteria",
+					ToName:   "dialogue",
+					Type:     "paragraphlabels",
+					Value: labelStudioParagraphsValue{
+						Start:           strconv.Itoa(index),
+						End:             strconv.Itoa(index),
+						StartOffset:     0,
+						EndOffset:       utf8.RuneCountInString(text),
+						Text:            text,
+						ParagraphLabels: []string{fmt.Sprintf("%s: %s", criterion.Status, criterion.Criterion)},
+					},
+				})
+			}
+		}
+		task.Predictions = []labelStudioPrediction{{ModelVersion: "scenario-judge", Result: annotations}}
+		tasks[i] = task
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(tasks); err != nil {
+		return fmt.Errorf("failed to encode label studio tasks: %w", err)
+	}
+
+	return nil
+}
+
+// csvHeader is the header row of WriteCSV.
+var csvHeader = []string{
+	"scenario_id",
+	"run_id",
+	"seed",
+	"success",
+	"cached",
+	"aborted",
+	"turns",
+	"total_duration_ms",
+	"agent_duration_ms",
+	"llm_calls",
+	"prompt_tokens",
+	"completion_tokens",
+	"met_criteria",
+	"unmet_criteria",
+	"triggered_failures",
+	"tags",
+	"persona",
+	"reasoning",
+}
+
+// WriteCSV writes the results as a CSV file with a header row and one row per run, with its
+// verdict, durations, token usage, tags and criteria counts, for analysis in a spreadsheet.
+// Tags are joined with ";".
+func WriteCSV(w io.Writer, results []*Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for i, result := range results {
+		var usage ComponentUsage
+		for _, u := range result.UsageByComponent() {
+			usage.Calls += u.Calls
+			usage.PromptTokens += u.PromptTokens
+			usage.CompletionTokens += u.CompletionTokens
+		}
+		persona := ""
+		if result.Persona != nil {
+			persona = result.Persona.Name
+		}
+
+		if err := writer.Write([]string{
+			result.ScenarioID,
+			result.RunID,
+			strconv.FormatInt(result.Seed, 10),
+			strconv.FormatBool(result.Success),
+			strconv.FormatBool(result.Cached),
+			strconv.FormatBool(result.Aborted),
+			strconv.Itoa(len(result.Turns)),
+			strconv.FormatInt(result.TotalDurationNSec.Milliseconds(), 10),
+			strconv.FormatInt(result.AgentDurationNSec.Milliseconds(), 10),
+			strconv.Itoa(usage.Calls),
+			strconv.FormatInt(usage.PromptTokens, 10),
+			strconv.FormatInt(usage.CompletionTokens, 10),
+			strconv.Itoa(len(result.MetCriteria)),
+			strconv.Itoa(len(result.UnmetCriteria)),
+			strconv.Itoa(len(result.TriggeredFailures)),
+			strings.Join(result.Tags, ";"),
+			persona,
+			result.Reasoning,
+		}); err != nil {
+			return fmt.Errorf("failed to write result %d: %w", i, err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteTAP writes the results as a TAP version 14 (Test Anything Protocol) report, one test
+// point per run named after its scenario ID, for Jenkins and other TAP consumers. Failed
+// runs carry a YAML diagnostic with the reasoning and the criteria that failed.
+func WriteTAP(w io.Writer, results []*Result) error {
+	if _, err := fmt.Fprintf(w, "TAP version 14\n1..%d\n", len(results)); err != nil {
+		return fmt.Errorf("failed to write TAP plan: %w", err)
+	}
+
+	for i, result := range results {
+		status := "ok"
+		if !result.Success {
+			status = "not ok"
+		}
+		if _, err := fmt.Fprintf(w, "%s %d - %s\n", status, i+1, tapDescription(result.ScenarioID)); err != nil {
+			return fmt.Errorf("failed to write result %d: %w", i, err)
+		}
+		if result.Success {
+			continue
+		}
+
+		// JSON values are valid YAML flow scalars and sequences
+		diagnostic := []struct {
+			key   string
+			value any
+		}{
+			{"message", result.Reasoning},
+			{"run_id", result.RunID},
+			{"seed", result.Seed},
+			{"aborted", result.Aborted},
+			{"unmet_criteria", result.UnmetCriteria},
+			{"triggered_failures", result.TriggeredFailures},
+		}
+		lines := []string{"  ---"}
+		for _, field := range diagnostic {
+			value, err := json.Marshal(field.value)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s of result %d: %w", field.key, i, err)
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s", field.key, value))
+		}
+		lines = append(lines, "  ...")
+		if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+			return fmt.Errorf("failed to write result %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// tapDescription escapes the characters of a test point description that TAP gives a meaning.
+func tapDescription(description string) string {
+	return strings.NewReplacer("\\", "\\\\", "#", "\\#", "\n", " ").Replace(description)
+}
+
+// splitLastAssistantMessage splits the conversation into the messages before the last
+// assistant message and the content of that message.
+func splitLastAssistantMessage(conversation []Message) ([]evalsMessage, string) {
+	last := len(conversation)
+	for i := len(conversation) - 1; i >= 0; i-- {
+		if conversation[i].Role == MessageRoleAssistant {
+			last = i
+			break
+		}
+	}
+
+	input := make([]evalsMessage, 0, last)
+	for _, message := range conversation[:last] {
+		input = append(input, evalsMessage{Role: message.Role, Content: message.Content})
+	}
+	if last == len(conversation) {
+		return input, ""
+	}
+
+	return input, conversation[last].Content
+}
@@ -0,0 +1,154 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportTestResult() *Result {
+	return &Result{
+		ScenarioID: "recipes/dinner-idea",
+		RunID:      "01ARYZ6S41TSV4RRFFQ69G5FAV",
+		Success:    true,
+		Conversation: []Message{
+			{Role: MessageRoleUser, Content: "dinner idea"},
+			{Role: MessageRoleAssistant, Content: "any allergies?"},
+			{Role: MessageRoleUser, Content: "no"},
+			{Role: MessageRoleAssistant, Content: "try a lentil curry"},
+		},
+		Reasoning:     "Recipe was provided",
+		MetCriteria:   []string{"Recipe is vegetarian"},
+		UnmetCriteria: []string{"Recipe includes instructions"},
+	}
+}
+
+func TestWriteOpenAIEvalsJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteOpenAIEvalsJSONL(&buf, []*Result{newExportTestResult(), {}})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var sample openAIEvalsSample
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &sample))
+	assert.Equal(t, "try a lentil curry", sample.Ideal)
+	require.Len(t, sample.Input, 3)
+	assert.Equal(t, evalsMessage{Role: MessageRoleUser, Content: "no"}, sample.Input[2])
+	assert.Equal(t, true, sample.Metadata["success"])
+	assert.Equal(t, "recipes/dinner-idea", sample.Metadata["scenario_id"])
+	assert.Equal(t, "01ARYZ6S41TSV4RRFFQ69G5FAV", sample.Metadata["run_id"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &sample))
+	assert.Empty(t, sample.Ideal)
+	assert.Empty(t, sample.Input)
+}
+
+func TestWritePromptfooTests(t *testing.T) {
+	var buf bytes.Buffer
+	err := WritePromptfooTests(&buf, []*Result{newExportTestResult()})
+	require.NoError(t, err)
+
+	var testCases []promptfooTestCase
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &testCases))
+	require.Len(t, testCases, 1)
+	assert.Equal(t, "recipes/dinner-idea", testCases[0].Description)
+	assert.Equal(t, []promptfooAssert{
+		{Type: "llm-rubric", Value: "Recipe is vegetarian"},
+		{Type: "llm-rubric", Value: "Recipe includes instructions"},
+	}, testCases[0].Assert)
+	assert.Len(t, testCases[0].Vars["messages"], 3)
+}
+
+func TestWriteLabelStudioTasks(t *testing.T) {
+	result := newExportTestResult()
+	result.Evidence = map[string][]int{
+		"Recipe is vegetarian":         {3},
+		"Recipe includes instructions": {3, 12},
+	}
+
+	var buf bytes.Buffer
+	err := WriteLabelStudioTasks(&buf, []*Result{result})
+	require.NoError(t, err)
+
+	var tasks []labelStudioTask
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "recipes/dinner-idea", tasks[0].Data.ScenarioID)
+	require.Len(t, tasks[0].Data.Dialogue, 4)
+	assert.Equal(t, labelStudioUtterance{Author: "assistant", Text: "try a lentil curry"}, tasks[0].Data.Dialogue[3])
+
+	require.Len(t, tasks[0].Predictions, 1)
+	assert.Equal(t, []labelStudioAnnotation{
+		{
+			ID: "0-0", FromName: "criteria", ToName: "dialogue", Type: "paragraphlabels",
+			Value: labelStudioParagraphsValue{Start: "3", End: "3", EndOffset: 18, Text: "try a lentil curry", ParagraphLabels: []string{"met: Recipe is vegetarian"}},
+		},
+		{
+			ID: "0-1", FromName: "criteria", ToName: "dialogue", Type: "paragraphlabels",
+			Value: labelStudioParagraphsValue{Start: "3", End: "3", EndOffset: 18, Text: "try a lentil curry", ParagraphLabels: []string{"unmet: Recipe includes instructions"}},
+		},
+	}, tasks[0].Predictions[0].Result)
+}
+
+func TestWriteCSV(t *testing.T) {
+	result := newExportTestResult()
+	result.Seed = 7
+	result.Tags = []string{"smoke", "recipes"}
+	result.Turns = []TurnStats{{Turn: 0}, {Turn: 1}}
+	result.TotalDurationNSec = 1500 * time.Millisecond
+	result.AgentDurationNSec = 500 * time.Millisecond
+	result.Reasoning = "Recipe was provided, but no instructions"
+	result.AuditLog = []AuditEntry{
+		{Component: ComponentSimulator, PromptTokens: 10, CompletionTokens: 2},
+		{Component: ComponentJudge, PromptTokens: 30, CompletionTokens: 5},
+	}
+
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []*Result{result, {}})
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{
+		"recipes/dinner-idea", "01ARYZ6S41TSV4RRFFQ69G5FAV", "7", "true", "false", "false", "2",
+		"1500", "500", "2", "40", "7", "1", "1", "0", "smoke;recipes", "",
+		"Recipe was provided, but no instructions",
+	}, records[1])
+	assert.Equal(t, "false", records[2][3])
+}
+
+func TestWriteTAP(t *testing.T) {
+	failed := newExportTestResult()
+	failed.Success = false
+	failed.Seed = 7
+	failed.ScenarioID = "recipes/dinner #2"
+	failed.TriggeredFailures = []string{}
+
+	var buf bytes.Buffer
+	err := WriteTAP(&buf, []*Result{{ScenarioID: "recipes/lunch", Success: true}, failed})
+	require.NoError(t, err)
+
+	assert.Equal(t, `TAP version 14
+1..2
+ok 1 - recipes/lunch
+not ok 2 - recipes/dinner \#2
+  ---
+  message: "Recipe was provided"
+  run_id: "01ARYZ6S41TSV4RRFFQ69G5FAV"
+  seed: 7
+  aborted: false
+  unmet_criteria: ["Recipe includes instructions"]
+  triggered_failures: []
+  ...
+`, buf.String())
+}
@@ -0,0 +1,343 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// FormatValidator checks that the final answer of the agent conforms to a format, returning an
+// error describing precisely where it does not.
+type FormatValidator interface {
+	// Validate returns an error if the content does not conform to the format.
+	Validate(content string) error
+
+	// String describes the format, it is used as the criterion in results.
+	String() string
+}
+
+// formatValidatorFunc is a FormatValidator backed by a function.
+type formatValidatorFunc struct {
+	description string
+	validate    func(content string) error
+}
+
+func (v formatValidatorFunc) Validate(content string) error {
+	return v.validate(content)
+}
+
+func (v formatValidatorFunc) String() string {
+	return v.description
+}
+
+// ValidJSON checks that the final answer is valid JSON. A markdown code fence around the JSON
+// is allowed.
+func ValidJSON() FormatValidator {
+	return formatValidatorFunc{
+		description: "final answer is valid JSON",
+		validate: func(content string) error {
+			_, err := decodeJSONAnswer(content)
+			return err
+		},
+	}
+}
+
+// MatchesJSONSchema checks that the final answer is valid JSON matching the JSON schema. The
+// type, enum, const, properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum and maximum keywords are supported.
+func MatchesJSONSchema(schema map[string]any) FormatValidator {
+	return formatValidatorFunc{
+		description: "final answer is JSON matching the schema",
+		validate: func(content string) error {
+			value, err := decodeJSONAnswer(content)
+			if err != nil {
+				return err
+			}
+			return validateJSONSchema(schema, value, "$")
+		},
+	}
+}
+
+// MarkdownTable checks that the final answer contains a valid Markdown table, with a header
+// row, a delimiter row and body rows all having the same number of columns.
+func MarkdownTable() FormatValidator {
+	return formatValidatorFunc{
+		description: "final answer contains a Markdown table",
+		validate:    validateMarkdownTable,
+	}
+}
+
+// MaxLength checks that the final answer is at most n characters long.
+func MaxLength(n int) FormatValidator {
+	return formatValidatorFunc{
+		description: fmt.Sprintf("final answer is at most %d characters long", n),
+		validate: func(content string) error {
+			if length := utf8.RuneCountInString(content); length > n {
+				return fmt.Errorf("answer is %d characters long", length)
+			}
+			return nil
+		},
+	}
+}
+
+// MinLength checks that the final answer is at least n characters long.
+func MinLength(n int) FormatValidator {
+	return formatValidatorFunc{
+		description: fmt.Sprintf("final answer is at least %d characters long", n),
+		validate: func(content string) error {
+			if length := utf8.RuneCountInString(content); length < n {
+				return fmt.Errorf("answer is %d characters long", length)
+			}
+			return nil
+		},
+	}
+}
+
+// finalAnswer returns the content of the last assistant message of the conversation.
+func finalAnswer(conversation []Message) (string, bool) {
+	for i := len(conversation) - 1; i >= 0; i-- {
+		if conversation[i].Role == MessageRoleAssistant && conversation[i].Content != "" {
+			return conversation[i].Content, true
+		}
+	}
+
+	return "", false
+}
+
+var jsonCodeFence = regexp.MustCompile("(?s)^```[a-zA-Z]*\\s*\\n(.*?)\\n?```$")
+
+// decodeJSONAnswer decodes the JSON of an answer, optionally wrapped in a code fence.
+func decodeJSONAnswer(content string) (any, error) {
+	content = strings.TrimSpace(content)
+	if match := jsonCodeFence.FindStringSubmatch(content); match != nil {
+		content = match[1]
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("invalid JSON at offset %d: %w", syntaxErr.Offset, err)
+		}
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	return value, nil
+}
+
+// validateJSONSchema validates the value against the schema, path locating the value in the
+// answer for the diagnostics.
+func validateJSONSchema(schema map[string]any, value any, path string) error {
+	if schemaType, ok := schema["type"]; ok {
+		types, _ := schemaType.([]any)
+		if name, ok := schemaType.(string); ok {
+			types = []any{name}
+		}
+		if !slices.ContainsFunc(types, func(t any) bool { return jsonTypeMatches(t, value) }) {
+			return fmt.Errorf("%s: expected %v, got %s", path, schemaType, jsonTypeName(value))
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return jsonEqual(e, value) }) {
+			return fmt.Errorf("%s: %s is not one of %s", path, jsonString(value), jsonString(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		return fmt.Errorf("%s: expected %s, got %s", path, jsonString(constant), jsonString(value))
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		return validateJSONObject(schema, value, path)
+	case []any:
+		if minItems, ok := jsonNumber(schema["minItems"]); ok && float64(len(value)) < minItems {
+			return fmt.Errorf("%s: expected at least %v items, got %d", path, minItems, len(value))
+		}
+		if maxItems, ok := jsonNumber(schema["maxItems"]); ok && float64(len(value)) > maxItems {
+			return fmt.Errorf("%s: expected at most %v items, got %d", path, maxItems, len(value))
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				if err := validateJSONSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(value))
+		if minLength, ok := jsonNumber(schema["minLength"]); ok && length < minLength {
+			return fmt.Errorf("%s: expected at least %v characters, got %v", path, minLength, length)
+		}
+		if maxLength, ok := jsonNumber(schema["maxLength"]); ok && length > maxLength {
+			return fmt.Errorf("%s: expected at most %v characters, got %v", path, maxLength, length)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern %q in schema: %w", path, pattern, err)
+			}
+			if !re.MatchString(value) {
+				return fmt.Errorf("%s: %q does not match pattern %q", path, value, pattern)
+			}
+		}
+	case float64:
+		if minimum, ok := jsonNumber(schema["minimum"]); ok && value < minimum {
+			return fmt.Errorf("%s: expected at least %v, got %v", path, minimum, value)
+		}
+		if maximum, ok := jsonNumber(schema["maximum"]); ok && value > maximum {
+			return fmt.Errorf("%s: expected at most %v, got %v", path, maximum, value)
+		}
+	}
+
+	return nil
+}
+
+func validateJSONObject(schema map[string]any, value map[string]any, path string) error {
+	required, _ := schema["required"].([]any)
+	if names, ok := schema["required"].([]string); ok {
+		for _, name := range names {
+			required = append(required, name)
+		}
+	}
+	for _, name := range required {
+		if _, ok := value[fmt.Sprint(name)]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		propertySchema, ok := properties[name].(map[string]any)
+		if !ok {
+			if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			}
+			continue
+		}
+		if err := validateJSONSchema(propertySchema, value[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func jsonTypeMatches(schemaType any, value any) bool {
+	switch schemaType {
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return schemaType == jsonTypeName(value)
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func jsonNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
+
+func jsonEqual(a, b any) bool {
+	return jsonString(a) == jsonString(b)
+}
+
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+var markdownTableDelimiterCell = regexp.MustCompile(`^:?-{3,}:?$`)
+
+// validateMarkdownTable checks that the content contains a valid Markdown table.
+func validateMarkdownTable(content string) error {
+	lines := strings.Split(content, "\n")
+	for i := 0; i+1 < len(lines); i++ {
+		header, ok := markdownTableCells(lines[i])
+		if !ok {
+			continue
+		}
+		delimiter, ok := markdownTableCells(lines[i+1])
+		if !ok || !slices.ContainsFunc(delimiter, func(cell string) bool { return strings.Contains(cell, "-") }) {
+			continue
+		}
+
+		if len(delimiter) != len(header) {
+			return fmt.Errorf("line %d: delimiter row has %d columns, header has %d", i+2, len(delimiter), len(header))
+		}
+		for column, cell := range delimiter {
+			if !markdownTableDelimiterCell.MatchString(cell) {
+				return fmt.Errorf("line %d: invalid delimiter %q in column %d", i+2, cell, column+1)
+			}
+		}
+		for j := i + 2; j < len(lines); j++ {
+			row, ok := markdownTableCells(lines[j])
+			if !ok {
+				break
+			}
+			if len(row) != len(header) {
+				return fmt.Errorf("line %d: row has %d columns, header has %d", j+1, len(row), len(header))
+			}
+		}
+		return nil
+	}
+
+	return errors.New("no Markdown table found")
+}
+
+// markdownTableCells splits a Markdown table row into its trimmed cells.
+func markdownTableCells(line string) ([]string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.Contains(line, "|") {
+		return nil, false
+	}
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+
+	return cells, true
+}
@@ -0,0 +1,102 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidJSON(t *testing.T) {
+	validator := ValidJSON()
+	assert.Equal(t, "final answer is valid JSON", validator.String())
+
+	assert.NoError(t, validator.Validate(`{"name": "salad"}`))
+	assert.NoError(t, validator.Validate("```json\n{\"name\": \"salad\"}\n```"))
+
+	err := validator.Validate(`{"name": "salad",}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid JSON at offset 18")
+}
+
+func TestMatchesJSONSchema(t *testing.T) {
+	validator := MatchesJSONSchema(map[string]any{
+		"type":                 "object",
+		"required":             []string{"name", "ingredients"},
+		"additionalProperties": false,
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string", "minLength": 1},
+			"servings": map[string]any{"type": "integer", "minimum": 1, "maximum": 12},
+			"diet":     map[string]any{"enum": []any{"vegetarian", "vegan"}},
+			"ingredients": map[string]any{
+				"type":     "array",
+				"minItems": 1,
+				"items":    map[string]any{"type": "string", "pattern": "^[a-z ]+$"},
+			},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"valid", `{"name": "salad", "servings": 2, "diet": "vegan", "ingredients": ["lettuce", "tomato"]}`, ""},
+		{"invalid JSON", `salad`, "invalid JSON"},
+		{"wrong type", `[]`, "$: expected object, got array"},
+		{"missing required", `{"name": "salad"}`, `$: missing required property "ingredients"`},
+		{"additional property", `{"name": "salad", "ingredients": ["lettuce"], "meat": true}`, `$: unexpected property "meat"`},
+		{"not an integer", `{"name": "salad", "servings": 1.5, "ingredients": ["lettuce"]}`, "$.servings: expected integer, got number"},
+		{"maximum", `{"name": "salad", "servings": 20, "ingredients": ["lettuce"]}`, "$.servings: expected at most 12, got 20"},
+		{"enum", `{"name": "salad", "diet": "keto", "ingredients": ["lettuce"]}`, `$.diet: "keto" is not one of ["vegetarian","vegan"]`},
+		{"minLength", `{"name": "", "ingredients": ["lettuce"]}`, "$.name: expected at least 1 characters, got 0"},
+		{"minItems", `{"name": "salad", "ingredients": []}`, "$.ingredients: expected at least 1 items, got 0"},
+		{"pattern", `{"name": "salad", "ingredients": ["lettuce", "Bacon!"]}`, `$.ingredients[1]: "Bacon!" does not match pattern "^[a-z ]+$"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(tt.content)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	validator := MarkdownTable()
+
+	assert.NoError(t, validator.Validate("Here you go:\n\n| Dish | Time |\n| --- | :---: |\n| Salad | 10 min |\n| Soup | 30 min |\n\nEnjoy!"))
+	assert.NoError(t, validator.Validate("Dish | Time\n---|---\nSalad | 10 min"))
+
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"no table", "Salad, 10 min", "no Markdown table found"},
+		{"delimiter columns", "| Dish | Time |\n| --- |\n| Salad | 10 min |", "line 2: delimiter row has 1 columns, header has 2"},
+		{"invalid delimiter", "| Dish | Time |\n| --- | -x- |", `line 2: invalid delimiter "-x-" in column 2`},
+		{"row columns", "| Dish | Time |\n| --- | --- |\n| Salad | 10 min | vegan |", "line 3: row has 3 columns, header has 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validator.Validate(tt.content)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestLengthValidators(t *testing.T) {
+	assert.NoError(t, MaxLength(5).Validate("héllo"))
+	assert.EqualError(t, MaxLength(4).Validate("héllo"), "answer is 5 characters long")
+	assert.Equal(t, "final answer is at most 4 characters long", MaxLength(4).String())
+
+	assert.NoError(t, MinLength(5).Validate("héllo"))
+	assert.EqualError(t, MinLength(6).Validate("héllo"), "answer is 5 characters long")
+}
@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+)
+
+// scriptedTestingAgent is a TestingAgent sending scripted user messages without calling an LLM.
+type scriptedTestingAgent struct {
+	messages []string
+}
+
+// NewScriptedTestingAgent creates a testing agent sending the messages in order, one per turn,
+// without calling an LLM. After the last message it gives a success verdict, leaving the
+// outcome to the programmatic criteria of the scenario: success and failure assertions, format
+// validators and metric criteria. It makes runs cheap and deterministic, e.g. for fuzzing.
+func NewScriptedTestingAgent(messages ...string) TestingAgent {
+	return &scriptedTestingAgent{messages: messages}
+}
+
+func (t *scriptedTestingAgent) GenerateNextMessage(
+	ctx context.Context,
+	description string,
+	strategy string,
+	successCriteria []string,
+	failureCriteria []string,
+	conversation []Message,
+	firstMessage bool,
+	lastMessage bool,
+) (*string, *Result, error) {
+	sent := 0
+	for _, message := range conversation {
+		if message.Role == MessageRoleUser {
+			sent++
+		}
+	}
+	if firstMessage {
+		sent = 0
+	}
+	if !lastMessage && sent < len(t.messages) {
+		return &t.messages[sent], nil, nil
+	}
+
+	return nil, NewSuccessPartialResult(conversation, "The scripted messages were sent.", []string{}), nil
+}
+
+// FuzzScenario registers a fuzz target running the scenario built from the options returned
+// by build with the fuzzed initial user message, judged by a scripted testing agent so no LLM
+// is called. The target fails when the run returns an error or fails its programmatic
+// criteria, and the fuzzer reports panics of the agent. Add seed messages with f.Add before.
+//
+//	func FuzzRefunds(f *testing.F) {
+//		f.Add("i want a refund")
+//		scenario.FuzzScenario(f, func(message string) []scenario.ScenarioOption {
+//			return []scenario.ScenarioOption{
+//				scenario.WithAgent(&refundAgent{}),
+//				scenario.WithFailureAssertions(scenario.ToolCalled("refund").AtLeast(2)),
+//			}
+//		})
+//	}
+func FuzzScenario(f *testing.F, build func(message string) []ScenarioOption) {
+	f.Helper()
+
+	f.Fuzz(func(t *testing.T, message string) {
+		opts := append(build(message), WithTestingAgent(NewScriptedTestingAgent(message)))
+		result, err := NewScenario(opts...).Run(t.Context())
+		if err != nil {
+			t.Fatalf("scenario run failed for message %q: %v", message, err)
+		}
+		if !result.Success {
+			result.LogResultDetails(t)
+			t.Fatalf("scenario failed for message %q: %s", message, result.Reasoning)
+		}
+	})
+}
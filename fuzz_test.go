@@ -0,0 +1,50 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptedTestingAgent(t *testing.T) {
+	ctx := context.Background()
+	runs := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			runs++
+			return []Message{{Role: MessageRoleAssistant, Content: "echo: " + message}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(NewScriptedTestingAgent("hi", "refund please")),
+		WithFailureAssertions(Contains("echo: refund")),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	assert.False(t, result.Success, "the programmatic criteria decide the outcome")
+	assert.Equal(t, []string{`a message contains "echo: refund"`}, result.TriggeredFailures)
+}
+
+func FuzzScenario_EchoAgent(f *testing.F) {
+	f.Add("hi")
+	f.Add("where is my order?")
+
+	FuzzScenario(f, func(message string) []ScenarioOption {
+		return []ScenarioOption{
+			WithAgent(&mockAgent{
+				runFunc: func(ctx context.Context, message string) ([]Message, error) {
+					return []Message{{Role: MessageRoleAssistant, Content: strings.ToUpper(message) + "!"}}, nil
+				},
+			}),
+			WithSuccessAssertions(Contains("!")),
+		}
+	})
+}
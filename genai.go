@@ -0,0 +1,54 @@
+package scenario
+
+// The attribute names and values of the OpenTelemetry semantic conventions for generative AI
+// systems, see https://opentelemetry.io/docs/specs/semconv/gen-ai/.
+const (
+	genAISystem        = "gen_ai.system"
+	genAIOperationName = "gen_ai.operation.name"
+	genAIRequestModel  = "gen_ai.request.model"
+	genAIInputTokens   = "gen_ai.usage.input_tokens"
+	genAIOutputTokens  = "gen_ai.usage.output_tokens"
+	errorType          = "error.type"
+
+	genAIOperationChat = "chat"
+	errorTypeOther     = "_OTHER"
+)
+
+// genAISystems are the gen_ai.system values of the providers whose name differs.
+var genAISystems = map[string]string{
+	providerAzureOpenAI: "az.ai.openai",
+}
+
+// GenAISpanName returns the name of the span of the call following the OpenTelemetry GenAI
+// semantic conventions, e.g. "chat gpt-4o-mini".
+func (e AuditEntry) GenAISpanName() string {
+	if e.Model == "" {
+		return genAIOperationChat
+	}
+	return genAIOperationChat + " " + e.Model
+}
+
+// GenAIAttributes returns the attributes of the span of the call following the OpenTelemetry
+// GenAI semantic conventions, so LLM-aware observability tools interpret the traces of the
+// scenario runs, along with the component and turn of the run the call was made for. Values
+// are strings or int64s, to convert to attribute.KeyValue.
+func (e AuditEntry) GenAIAttributes() map[string]any {
+	system := e.Provider
+	if s, ok := genAISystems[e.Provider]; ok {
+		system = s
+	}
+	attributes := map[string]any{
+		genAISystem:             system,
+		genAIOperationName:      genAIOperationChat,
+		genAIRequestModel:       e.Model,
+		genAIInputTokens:        e.PromptTokens,
+		genAIOutputTokens:       e.CompletionTokens,
+		"scenario.component":    string(e.Component),
+		"scenario.turn":         int64(e.Turn),
+		"scenario.payload_hash": e.PayloadSHA256,
+	}
+	if e.Error != "" {
+		attributes[errorType] = errorTypeOther
+	}
+	return attributes
+}
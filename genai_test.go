@@ -0,0 +1,59 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEntry_GenAIAttributes(t *testing.T) {
+	entry := AuditEntry{
+		Provider:         "openai",
+		Model:            "gpt-4o-mini",
+		PromptTokens:     120,
+		CompletionTokens: 30,
+		Component:        ComponentJudge,
+		Turn:             2,
+	}
+
+	assert.Equal(t, "chat gpt-4o-mini", entry.GenAISpanName())
+	attributes := entry.GenAIAttributes()
+	assert.Equal(t, "openai", attributes["gen_ai.system"])
+	assert.Equal(t, "chat", attributes["gen_ai.operation.name"])
+	assert.Equal(t, "gpt-4o-mini", attributes["gen_ai.request.model"])
+	assert.Equal(t, int64(120), attributes["gen_ai.usage.input_tokens"])
+	assert.Equal(t, int64(30), attributes["gen_ai.usage.output_tokens"])
+	assert.Equal(t, "judge", attributes["scenario.component"])
+	assert.Equal(t, int64(2), attributes["scenario.turn"])
+	assert.NotContains(t, attributes, "error.type")
+
+	entry.Error = "rate limited"
+	assert.Equal(t, "_OTHER", entry.GenAIAttributes()["error.type"])
+}
+
+func TestScenario_Run_CallObserver(t *testing.T) {
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			RecordAuditEntry(ctx, AuditEntry{Provider: "anthropic", Model: "claude-sonnet"})
+			return []Message{{Role: MessageRoleAssistant, Content: "hello"}}, nil
+		},
+	}
+	var observed []AuditEntry
+
+	result, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithCallObserver(func(ctx context.Context, entry AuditEntry) {
+			require.NotNil(t, ctx)
+			observed = append(observed, entry)
+		}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, observed, 1)
+	assert.Equal(t, result.AuditLog, observed)
+	assert.Equal(t, ComponentAgent, observed[0].Component)
+	assert.Equal(t, "chat claude-sonnet", observed[0].GenAISpanName())
+}
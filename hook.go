@@ -0,0 +1,145 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+)
+
+// Hooks are called by the run loop of a scenario, e.g. to log every turn or to add per-turn
+// assertions, see WithHooks. Every hook is optional. A hook returning an error interrupts the
+// run, which returns the error.
+type Hooks struct {
+	// OnTurnStart is called at the start of every turn with the conversation so far.
+	OnTurnStart func(ctx context.Context, turn int, conversation []Message) error
+
+	// OnUserMessage is called with the message of the simulated user once it is appended to
+	// the conversation, before it is sent to the agent.
+	OnUserMessage func(ctx context.Context, turn int, message Message) error
+
+	// OnAgentMessages is called with the messages of the agent once they are appended to the
+	// conversation.
+	OnAgentMessages func(ctx context.Context, turn int, messages []Message) error
+
+	// OnVerdict is called with the result of the run, whichever way it ended. It is not called
+	// when the run fails with an error.
+	OnVerdict func(ctx context.Context, result *Result) error
+}
+
+// turnStart calls the OnTurnStart hooks.
+func (s *scenario) turnStart(ctx context.Context, turn int) error {
+	for _, hooks := range s.hooks {
+		if hooks.OnTurnStart == nil {
+			continue
+		}
+		if err := hooks.OnTurnStart(ctx, turn, s.conversation); err != nil {
+			return fmt.Errorf("turn start hook failed at turn %d: %w", turn, err)
+		}
+	}
+	return nil
+}
+
+// userMessage calls the OnUserMessage hooks.
+func (s *scenario) userMessage(ctx context.Context, turn int, message Message) error {
+	for _, hooks := range s.hooks {
+		if hooks.OnUserMessage == nil {
+			continue
+		}
+		if err := hooks.OnUserMessage(ctx, turn, message); err != nil {
+			return fmt.Errorf("user message hook failed at turn %d: %w", turn, err)
+		}
+	}
+	return nil
+}
+
+// agentMessages calls the OnAgentMessages hooks.
+func (s *scenario) agentMessages(ctx context.Context, turn int, messages []Message) error {
+	for _, hooks := range s.hooks {
+		if hooks.OnAgentMessages == nil {
+			continue
+		}
+		if err := hooks.OnAgentMessages(ctx, turn, messages); err != nil {
+			return fmt.Errorf("agent messages hook failed at turn %d: %w", turn, err)
+		}
+	}
+	return nil
+}
+
+// verdict calls the OnVerdict hooks.
+func (s *scenario) verdict(ctx context.Context, result *Result) error {
+	for _, hooks := range s.hooks {
+		if hooks.OnVerdict == nil {
+			continue
+		}
+		if err := hooks.OnVerdict(ctx, result); err != nil {
+			return fmt.Errorf("verdict hook failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// RunEventType is the kind of a RunEvent.
+type RunEventType string
+
+const (
+	// RunEventUserMessage is a message of the simulated user appended to the conversation.
+	RunEventUserMessage RunEventType = "user_message"
+
+	// RunEventAgentMessages is the messages of the agent appended to the conversation.
+	RunEventAgentMessages RunEventType = "agent_messages"
+
+	// RunEventVerdict is the result of the run.
+	RunEventVerdict RunEventType = "verdict"
+)
+
+// RunEvent is an event of a scenario run, sent as it happens by the hooks of StreamRunEvents.
+type RunEvent struct {
+	// Type is the kind of event.
+	Type RunEventType `json:"type"`
+
+	// RunID is the unique identifier of the run, see Result.RunID.
+	RunID string `json:"run_id"`
+
+	// ScenarioID is the identifier of the scenario, see Result.ScenarioID.
+	ScenarioID string `json:"scenario_id"`
+
+	// Turn is the zero-based turn of the event, the number of turns for a verdict.
+	Turn int `json:"turn"`
+
+	// Messages are the messages appended to the conversation, nil for a verdict.
+	Messages []Message `json:"messages,omitempty"`
+
+	// Result is the result of the run, only set for a verdict.
+	Result *Result `json:"result,omitempty"`
+}
+
+// StreamRunEvents returns hooks sending the messages of a run to fn as soon as they are
+// appended to the conversation, then its result, e.g. to upload long runs to an observability
+// platform while they are running rather than only once they are over. Runs that are aborted
+// end with a verdict event for their partial result, while runs failing with an error end
+// without one, leaving the messages streamed so far. An error returned by fn interrupts the
+// run.
+func StreamRunEvents(fn func(ctx context.Context, event RunEvent) error) Hooks {
+	send := func(ctx context.Context, event RunEvent) error {
+		info, _ := RunInfoFromContext(ctx)
+		event.RunID, event.ScenarioID = info.RunID, info.ScenarioID
+		return fn(ctx, event)
+	}
+
+	return Hooks{
+		OnUserMessage: func(ctx context.Context, turn int, message Message) error {
+			return send(ctx, RunEvent{Type: RunEventUserMessage, Turn: turn, Messages: []Message{message}})
+		},
+		OnAgentMessages: func(ctx context.Context, turn int, messages []Message) error {
+			return send(ctx, RunEvent{Type: RunEventAgentMessages, Turn: turn, Messages: append([]Message{}, messages...)})
+		},
+		OnVerdict: func(ctx context.Context, result *Result) error {
+			return fn(ctx, RunEvent{
+				Type:       RunEventVerdict,
+				RunID:      result.RunID,
+				ScenarioID: result.ScenarioID,
+				Turn:       len(result.Turns),
+				Result:     result,
+			})
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_Hooks(t *testing.T) {
+	var calls []string
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithHooks(Hooks{
+			OnTurnStart: func(ctx context.Context, turn int, conversation []Message) error {
+				calls = append(calls, fmt.Sprintf("turn start %d, %d messages", turn, len(conversation)))
+				return nil
+			},
+			OnUserMessage: func(ctx context.Context, turn int, message Message) error {
+				calls = append(calls, fmt.Sprintf("user message %d: %s", turn, message.Content))
+				return nil
+			},
+			OnAgentMessages: func(ctx context.Context, turn int, messages []Message) error {
+				calls = append(calls, fmt.Sprintf("agent messages %d: %s", turn, messages[0].Content))
+				return nil
+			},
+			OnVerdict: func(ctx context.Context, result *Result) error {
+				calls = append(calls, fmt.Sprintf("verdict: %t", result.Success))
+				return nil
+			},
+		}),
+		WithHooks(Hooks{
+			OnVerdict: func(ctx context.Context, result *Result) error {
+				calls = append(calls, "second verdict hook")
+				return nil
+			},
+		}),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{
+		"turn start 0, 0 messages",
+		"user message 0: Initial user message",
+		"agent messages 0: Agent response to: Initial user message",
+		"verdict: true",
+		"second verdict hook",
+	}, calls)
+}
+
+func TestScenario_Run_HookError(t *testing.T) {
+	errAssertion := errors.New("agent did not greet the user")
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithHooks(Hooks{
+			OnAgentMessages: func(ctx context.Context, turn int, messages []Message) error {
+				return errAssertion
+			},
+			OnVerdict: func(ctx context.Context, result *Result) error {
+				t.Error("verdict hook called after a failed run")
+				return nil
+			},
+		}),
+	)
+
+	_, err := s.Run(context.Background())
+
+	require.ErrorIs(t, err, errAssertion)
+	assert.ErrorContains(t, err, "agent messages hook failed at turn 0")
+}
+
+func TestScenario_Run_StreamRunEvents(t *testing.T) {
+	var events []RunEvent
+	s := NewScenario(
+		WithID("greeting"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithHooks(StreamRunEvents(func(ctx context.Context, event RunEvent) error {
+			events = append(events, event)
+			return nil
+		})),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, RunEventUserMessage, events[0].Type)
+	assert.Equal(t, []Message{{Role: MessageRoleUser, Content: "Initial user message"}}, events[0].Messages)
+	assert.Equal(t, RunEventAgentMessages, events[1].Type)
+	assert.Equal(t, "Agent response to: Initial user message", events[1].Messages[0].Content)
+	assert.Equal(t, RunEvent{Type: RunEventVerdict, RunID: result.RunID, ScenarioID: "greeting", Turn: 1, Result: result}, events[2])
+	for _, event := range events {
+		assert.Equal(t, result.RunID, event.RunID)
+		assert.Equal(t, "greeting", event.ScenarioID)
+	}
+}
+
+func TestScenario_Run_StreamRunEvents_AgentError(t *testing.T) {
+	var events []RunEventType
+	s := NewScenario(
+		WithAgent(&mockAgent{
+			runFunc: func(ctx context.Context, message string) ([]Message, error) {
+				return nil, errors.New("agent unavailable")
+			},
+		}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithHooks(StreamRunEvents(func(ctx context.Context, event RunEvent) error {
+			events = append(events, event.Type)
+			return nil
+		})),
+	)
+
+	_, err := s.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Equal(t, []RunEventType{RunEventUserMessage}, events, "the messages streamed before the error are kept")
+}
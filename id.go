@@ -0,0 +1,36 @@
+package scenario
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// scenarioIDPattern matches namespaced scenario IDs such as "billing/refund-happy-path".
+var scenarioIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+
+// nonSlugCharacters matches the runs of characters replaced when generating a scenario ID.
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// validScenarioID reports whether id is a valid namespaced scenario ID.
+func validScenarioID(id string) bool {
+	return scenarioIDPattern.MatchString(id)
+}
+
+// generateScenarioID generates a scenario ID from the description, made of a slug of the
+// description and a short hash of it to keep IDs of similar descriptions apart. The ID is
+// deterministic but not stable: any edit of the description changes it, see WithID.
+func generateScenarioID(description string) string {
+	slug := strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(description), "-"), "-")
+	if len(slug) > 48 {
+		slug = strings.TrimRight(slug[:48], "-")
+	}
+
+	sum := sha256.Sum256([]byte(description))
+	hash := hex.EncodeToString(sum[:4])
+	if slug == "" {
+		return "scenario-" + hash
+	}
+	return slug + "-" + hash
+}
@@ -0,0 +1,54 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidScenarioID(t *testing.T) {
+	assert.True(t, validScenarioID("billing/refund-happy-path"))
+	assert.True(t, validScenarioID("recipes/v2/dinner_idea.long"))
+	assert.False(t, validScenarioID(""))
+	assert.False(t, validScenarioID("billing//refund"))
+	assert.False(t, validScenarioID("/billing"))
+	assert.False(t, validScenarioID("billing refund"))
+}
+
+func TestGenerateScenarioID(t *testing.T) {
+	id := generateScenarioID("User is looking for a dinner idea!")
+	assert.Regexp(t, `^user-is-looking-for-a-dinner-idea-[0-9a-f]{8}$`, id)
+	assert.Equal(t, id, generateScenarioID("User is looking for a dinner idea!"))
+	assert.NotEqual(t, id, generateScenarioID("User is looking for a dinner idea?"))
+	assert.Regexp(t, `^scenario-[0-9a-f]{8}$`, generateScenarioID(""))
+	assert.True(t, validScenarioID(generateScenarioID("a very long description that goes on and on beyond the slug length limit")))
+}
+
+func TestScenario_Run_ScenarioID(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := NewScenario(
+		WithID("billing/refund-happy-path"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+	).Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "billing/refund-happy-path", result.ScenarioID)
+
+	result, err = NewScenario(
+		WithDescription("Refund happy path"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+	).Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, generateScenarioID("Refund happy path"), result.ScenarioID)
+
+	_, err = NewScenario(
+		WithID("billing refund"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+	).Run(ctx)
+	assert.EqualError(t, err, `invalid scenario id "billing refund"`)
+}
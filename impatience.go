@@ -0,0 +1,61 @@
+package scenario
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Impatience configures how many clarifying questions the simulated user tolerates before
+// pushing back or abandoning the conversation.
+type Impatience struct {
+	// MaxQuestions is the number of clarifying questions from the agent the user tolerates,
+	// counting every sentence of the agent ending with a question mark.
+	MaxQuestions int
+
+	// Abandon makes the user abandon the conversation instead of pushing back once the agent
+	// asks too many questions, ending the scenario with the final verdict.
+	Abandon bool
+}
+
+// exhausted reports whether the agent asked more questions than the user tolerates.
+func (i Impatience) exhausted(conversation []Message) bool {
+	return countAgentQuestions(conversation) > i.MaxQuestions
+}
+
+// instructions returns the impatience instructions for the testing agent, empty while the
+// user is still patient.
+func (i Impatience) instructions(conversation []Message) string {
+	questions := countAgentQuestions(conversation)
+	if questions <= i.MaxQuestions {
+		return ""
+	}
+
+	instructions := fmt.Sprintf("<impatience>\nThe agent asked %d clarifying questions, more than the %d the user tolerates.", questions, i.MaxQuestions)
+	if i.Abandon {
+		instructions += " The user abandons the conversation, the agent failed to balance clarification with progress."
+	} else {
+		instructions += " Push back: tell the agent to stop asking questions and to make progress with what it already knows."
+	}
+	return instructions + "\n</impatience>"
+}
+
+// questionPattern matches a sentence ending with a question mark. Question marks followed by
+// other characters, e.g. in URLs or code, do not end a sentence.
+var questionPattern = regexp.MustCompile(`[^.!?\n]*\pL[^.!?\n]*\?+[!"')\]]*(\s|$)`)
+
+// codeBlockPattern matches the fenced code blocks of a message.
+var codeBlockPattern = regexp.MustCompile("(?s)```.*?```")
+
+// countAgentQuestions counts the questions asked by the agent, every sentence of its messages
+// ending with a question mark being a question, outside of code blocks.
+func countAgentQuestions(conversation []Message) int {
+	count := 0
+	for _, message := range conversation {
+		if message.Role != MessageRoleAssistant {
+			continue
+		}
+		content := codeBlockPattern.ReplaceAllString(message.Content, "")
+		count += len(questionPattern.FindAllString(content, -1))
+	}
+	return count
+}
@@ -0,0 +1,80 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpatience_Instructions(t *testing.T) {
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "book a table"},
+		{Role: MessageRoleAssistant, Content: "For how many people?"},
+		{Role: MessageRoleUser, Content: "2"},
+		{Role: MessageRoleAssistant, Content: "At what time?"},
+	}
+
+	impatience := Impatience{MaxQuestions: 2}
+	assert.Empty(t, impatience.instructions(conversation))
+	assert.False(t, impatience.exhausted(conversation))
+
+	conversation = append(conversation, Message{Role: MessageRoleAssistant, Content: "Indoor or outdoor?"})
+	assert.True(t, impatience.exhausted(conversation))
+	assert.Equal(t, "<impatience>\nThe agent asked 3 clarifying questions, more than the 2 the user tolerates. "+
+		"Push back: tell the agent to stop asking questions and to make progress with what it already knows.\n</impatience>",
+		impatience.instructions(conversation))
+
+	impatience.Abandon = true
+	assert.Contains(t, impatience.instructions(conversation), "The user abandons the conversation")
+}
+
+func TestCountAgentQuestions(t *testing.T) {
+	for content, want := range map[string]int{
+		"For how many people? And at what time?":            2,
+		"Would you like a table by the window?\nThanks!":    1,
+		"See https://example.com/book?table=2 for details.": 0,
+		"Run this:\n```\nok = x ? y : z\n```":               0,
+		"Really?! Let me check.":                            1,
+		"Your booking is confirmed.":                        0,
+	} {
+		assert.Equal(t, want, countAgentQuestions([]Message{{Role: MessageRoleAssistant, Content: content}}), content)
+	}
+	assert.Zero(t, countAgentQuestions([]Message{{Role: MessageRoleUser, Content: "Can you help?"}}))
+}
+
+func TestScenario_Run_ImpatienceAbandon(t *testing.T) {
+	ctx := context.Background()
+	var lastMessages []bool
+	var lastStrategy string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			lastMessages = append(lastMessages, lastMessage)
+			lastStrategy = strategy
+			if lastMessage {
+				return nil, NewFailurePartialResult(conversation, "User abandoned", []string{}, []string{}, []string{}), nil
+			}
+			msg := "book a table"
+			return &msg, nil, nil
+		},
+	}
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, Content: "Could you tell me more?"}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithImpatience(Impatience{MaxQuestions: 1, Abandon: true}),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "User abandoned", result.Reasoning)
+	assert.Equal(t, []bool{false, false, true}, lastMessages)
+	assert.Contains(t, lastStrategy, "The user abandons the conversation")
+}
@@ -0,0 +1,47 @@
+package scenario
+
+import "iter"
+
+// Messages returns an iterator over the messages of the conversation of the result with
+// their indices.
+func (r *Result) Messages() iter.Seq2[int, Message] {
+	return func(yield func(int, Message) bool) {
+		for i, message := range r.Conversation {
+			if !yield(i, message) {
+				return
+			}
+		}
+	}
+}
+
+// TurnsSeq returns an iterator over the turns of the conversation of the result with their
+// indices, each turn starting with a user message followed by the replies to it. The turns
+// are subslices of the conversation, they are not copied.
+func (r *Result) TurnsSeq() iter.Seq2[int, []Message] {
+	return conversationTurns(r.Conversation)
+}
+
+// conversationTurns returns an iterator over the turns of a conversation, each starting with
+// a user message.
+func conversationTurns(conversation []Message) iter.Seq2[int, []Message] {
+	return func(yield func(int, []Message) bool) {
+		turn, start := 0, 0
+		hasUserMessage := false
+		for i, message := range conversation {
+			if message.Role != MessageRoleUser {
+				continue
+			}
+			if hasUserMessage {
+				if !yield(turn, conversation[start:i:i]) {
+					return
+				}
+				turn++
+				start = i
+			}
+			hasUserMessage = true
+		}
+		if start < len(conversation) {
+			yield(turn, conversation[start:len(conversation):len(conversation)])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult_Messages(t *testing.T) {
+	result := &Result{Conversation: []Message{
+		{Role: MessageRoleUser, Content: "hi"},
+		{Role: MessageRoleAssistant, Content: "hello"},
+		{Role: MessageRoleUser, Content: "bye"},
+	}}
+
+	var contents []string
+	for i, message := range result.Messages() {
+		if i == 2 {
+			break
+		}
+		contents = append(contents, message.Content)
+	}
+
+	assert.Equal(t, []string{"hi", "hello"}, contents)
+}
+
+func TestResult_TurnsSeq(t *testing.T) {
+	result := &Result{Conversation: []Message{
+		{Role: MessageRoleSystem, Content: "be brief"},
+		{Role: MessageRoleUser, Content: "hi"},
+		{Role: MessageRoleAssistant, Content: "hello"},
+		{Role: MessageRoleUser, Content: "order?"},
+		{Role: MessageRoleAssistant, Content: "thinking"},
+		{Role: MessageRoleAssistant, Content: "shipped"},
+		{Role: MessageRoleUser, Content: "thanks"},
+	}}
+
+	var turns [][]Message
+	for i, turn := range result.TurnsSeq() {
+		assert.Equal(t, len(turns), i)
+		turns = append(turns, turn)
+	}
+
+	assert.Equal(t, [][]Message{
+		result.Conversation[0:3],
+		result.Conversation[3:6],
+		result.Conversation[6:7],
+	}, turns)
+	assert.Equal(t, turns, splitTurns(result.Conversation))
+
+	for range (&Result{}).TurnsSeq() {
+		t.Fatal("an empty conversation has no turns")
+	}
+}
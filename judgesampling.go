@@ -0,0 +1,51 @@
+package scenario
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+// JudgeSampling configures on which turns the per-turn judge calls are made: the topic guard
+// of WithAllowedTopics and the separate verdict of WithBlindSimulator. Skipping turns trades
+// detection latency for cost, the final turn is always judged.
+type JudgeSampling struct {
+	// EveryNTurns judges every Nth turn only, e.g. the turns 3, 6 and 9 of the scenario with
+	// 3, every turn when zero or 1.
+	EveryNTurns int `json:"every_n_turns,omitempty"`
+
+	// Probability judges the turns kept by EveryNTurns with the given probability, drawn from
+	// the seed of the run so the sampled turns are reproduced with WithSeed, every turn when
+	// zero.
+	Probability float64 `json:"probability,omitempty"`
+}
+
+// judged reports whether the zero-based turn is sampled for judging with the seed of the run.
+func (j JudgeSampling) judged(seed int64, turn int) bool {
+	if j.EveryNTurns > 1 && (turn+1)%j.EveryNTurns != 0 {
+		return false
+	}
+	if j.Probability > 0 && j.Probability < 1 {
+		// Every turn has its own stream, so redone or added turns do not shift the others
+		return rand.New(rand.NewPCG(uint64(seed), uint64(turn)+1)).Float64() < j.Probability
+	}
+	return true
+}
+
+// judgedTurn reports whether the per-turn judge calls are made for the zero-based turn, the
+// last turn of the run always being judged.
+func (s *scenario) judgedTurn(turn int, last bool) bool {
+	return s.judgeSampling == nil || last || s.judgeSampling.judged(s.runSeed, turn)
+}
+
+type judgeSkippedContextKey struct{}
+
+// withJudgeSkipped returns a context skipping the per-turn judge calls of the testing agent.
+func withJudgeSkipped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, judgeSkippedContextKey{}, true)
+}
+
+// judgeSkipped reports whether the per-turn judge calls are skipped for the context.
+func judgeSkipped(ctx context.Context) bool {
+	skipped, _ := ctx.Value(judgeSkippedContextKey{}).(bool)
+	return skipped
+}
@@ -0,0 +1,80 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJudgeSampling_Judged(t *testing.T) {
+	judged := func(sampling JudgeSampling, seed int64) []int {
+		var turns []int
+		for turn := range 12 {
+			if sampling.judged(seed, turn) {
+				turns = append(turns, turn)
+			}
+		}
+		return turns
+	}
+
+	assert.Len(t, judged(JudgeSampling{}, 1), 12)
+	assert.Len(t, judged(JudgeSampling{EveryNTurns: 1}, 1), 12)
+	assert.Equal(t, []int{2, 5, 8, 11}, judged(JudgeSampling{EveryNTurns: 3}, 1))
+	assert.Len(t, judged(JudgeSampling{Probability: 1}, 1), 12)
+
+	sampled := judged(JudgeSampling{Probability: 0.5}, 42)
+	assert.NotEmpty(t, sampled)
+	assert.Less(t, len(sampled), 12)
+	assert.Equal(t, sampled, judged(JudgeSampling{Probability: 0.5}, 42), "the sampled turns are reproduced with the seed")
+	for _, turn := range judged(JudgeSampling{EveryNTurns: 2, Probability: 0.5}, 42) {
+		assert.Equal(t, 1, turn%2)
+	}
+}
+
+func TestScenario_Run_JudgeSampling(t *testing.T) {
+	var classified []int
+	classifier := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			scope, _ := ctx.Value(auditScopeContextKey{}).(auditScope)
+			classified = append(classified, scope.turn)
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{Content: "off-topic: weather"},
+			}}}, nil
+		},
+	}
+	var skipped []bool
+	testingAgent := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if lastMessage {
+				return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+			}
+			if !firstMessage {
+				skipped = append(skipped, judgeSkipped(ctx))
+			}
+			msg := "hi"
+			return &msg, nil, nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(testingAgent),
+		WithMaxTurns(5),
+		WithAllowedTopics(TopicGuard{
+			Topics:     []string{"orders"},
+			Classifier: classifier,
+			Warn:       true,
+		}),
+		WithJudgeSampling(JudgeSampling{EveryNTurns: 2}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, []int{1, 3, 4}, classified, "every second turn and the final turn are judged")
+	assert.Equal(t, []bool{true, false, true, false}, skipped)
+	assert.Equal(t, &JudgeSampling{EveryNTurns: 2}, result.JudgeSampling)
+	require.Len(t, result.Diagnostics, 1, "the consecutive off-topic turns are counted over the judged turns")
+	assert.Equal(t, 1, result.Diagnostics[0].Turn)
+}
@@ -0,0 +1,116 @@
+package scenario
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNoAPIKeyAvailable is returned when every key of an APIKeyPool has been disabled.
+var ErrNoAPIKeyAvailable = errors.New("no api key available in pool")
+
+// KeySelection is the strategy used by an APIKeyPool to pick a key.
+type KeySelection int
+
+const (
+	// KeySelectionRoundRobin picks the keys in turn.
+	KeySelectionRoundRobin KeySelection = iota
+
+	// KeySelectionLeastLoaded picks the key with the fewest requests in flight.
+	KeySelectionLeastLoaded
+)
+
+// APIKeyPool is a pool of provider API keys shared by LLM completion adapters, spreading
+// requests over multiple keys and disabling the ones that ran out of quota.
+type APIKeyPool struct {
+	mu        sync.Mutex
+	selection KeySelection
+	keys      []*pooledAPIKey
+	next      int
+}
+
+type pooledAPIKey struct {
+	key      string
+	inFlight int
+	disabled bool
+}
+
+// NewAPIKeyPool creates a new pool with the given keys and selection strategy.
+func NewAPIKeyPool(selection KeySelection, keys ...string) *APIKeyPool {
+	p := &APIKeyPool{selection: selection}
+	for _, key := range keys {
+		p.keys = append(p.keys, &pooledAPIKey{key: key})
+	}
+	return p
+}
+
+// Acquire picks an enabled key for a request, it must be released with Release once the
+// request is done.
+func (p *APIKeyPool) Acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var picked *pooledAPIKey
+	for i := range p.keys {
+		candidate := p.keys[(p.next+i)%len(p.keys)]
+		if candidate.disabled {
+			continue
+		}
+		if p.selection == KeySelectionRoundRobin {
+			picked = candidate
+			p.next = (p.next + i + 1) % len(p.keys)
+			break
+		}
+		if picked == nil || candidate.inFlight < picked.inFlight {
+			picked = candidate
+		}
+	}
+	if picked == nil {
+		return "", ErrNoAPIKeyAvailable
+	}
+
+	picked.inFlight++
+	return picked.key, nil
+}
+
+// Release marks a request made with the key as done.
+func (p *APIKeyPool) Release(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k := p.find(key); k != nil && k.inFlight > 0 {
+		k.inFlight--
+	}
+}
+
+// Disable stops the key from being picked, e.g. after it hit a quota error.
+func (p *APIKeyPool) Disable(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k := p.find(key); k != nil {
+		k.disabled = true
+	}
+}
+
+// Available returns the number of keys that are not disabled.
+func (p *APIKeyPool) Available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	available := 0
+	for _, k := range p.keys {
+		if !k.disabled {
+			available++
+		}
+	}
+	return available
+}
+
+func (p *APIKeyPool) find(key string) *pooledAPIKey {
+	for _, k := range p.keys {
+		if k.key == key {
+			return k
+		}
+	}
+	return nil
+}
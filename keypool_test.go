@@ -0,0 +1,52 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyPool_RoundRobin(t *testing.T) {
+	pool := NewAPIKeyPool(KeySelectionRoundRobin, "a", "b", "c")
+
+	var picked []string
+	for range 4 {
+		key, err := pool.Acquire()
+		require.NoError(t, err)
+		picked = append(picked, key)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, picked)
+
+	pool.Disable("b")
+	assert.Equal(t, 2, pool.Available())
+	key, err := pool.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "c", key)
+	key, err = pool.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, "a", key)
+}
+
+func TestAPIKeyPool_LeastLoaded(t *testing.T) {
+	pool := NewAPIKeyPool(KeySelectionLeastLoaded, "a", "b")
+
+	first, err := pool.Acquire()
+	require.NoError(t, err)
+	second, err := pool.Acquire()
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	pool.Release(second)
+	third, err := pool.Acquire()
+	require.NoError(t, err)
+	assert.Equal(t, second, third)
+}
+
+func TestAPIKeyPool_AllDisabled(t *testing.T) {
+	pool := NewAPIKeyPool(KeySelectionRoundRobin, "a")
+	pool.Disable("a")
+
+	_, err := pool.Acquire()
+	assert.ErrorIs(t, err, ErrNoAPIKeyAvailable)
+}
@@ -0,0 +1,117 @@
+package scenario
+
+import (
+	"strings"
+	"unicode"
+)
+
+// scriptLanguages are the languages detected from the script of the text, in the order they are
+// checked. Japanese comes before Han since Japanese text mixes Kana and Kanji.
+var scriptLanguages = []struct {
+	language string
+	tables   []*unicode.RangeTable
+}{
+	{"ja", []*unicode.RangeTable{unicode.Hiragana, unicode.Katakana}},
+	{"zh", []*unicode.RangeTable{unicode.Han}},
+	{"ko", []*unicode.RangeTable{unicode.Hangul}},
+	{"ru", []*unicode.RangeTable{unicode.Cyrillic}},
+	{"ar", []*unicode.RangeTable{unicode.Arabic}},
+	{"he", []*unicode.RangeTable{unicode.Hebrew}},
+	{"el", []*unicode.RangeTable{unicode.Greek}},
+	{"hi", []*unicode.RangeTable{unicode.Devanagari}},
+	{"th", []*unicode.RangeTable{unicode.Thai}},
+}
+
+// stopwords are common words used to tell apart the languages written in the Latin script.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "to", "of", "it", "for", "with", "what", "how", "can", "i", "my", "this", "that", "have", "please", "your", "any", "want", "need", "thanks", "hello"},
+	"es": {"el", "los", "las", "que", "y", "es", "por", "para", "con", "una", "se", "mi", "su", "cómo", "qué", "hola", "gracias", "quiero", "está", "puedo", "tiene"},
+	"fr": {"le", "les", "des", "et", "est", "je", "vous", "pour", "une", "pas", "avec", "dans", "mon", "votre", "bonjour", "merci", "ce", "il", "suis", "veux"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "sie", "ein", "eine", "zu", "mit", "für", "auf", "mein", "bitte", "danke", "wie", "was", "möchte"},
+	"it": {"il", "lo", "di", "che", "è", "per", "non", "sono", "mi", "ciao", "grazie", "come", "questo", "ho", "vorrei", "della"},
+	"pt": {"o", "os", "não", "um", "uma", "com", "meu", "você", "obrigado", "olá", "eu", "quero", "posso", "isso", "tem"},
+	"nl": {"het", "een", "en", "ik", "niet", "van", "je", "voor", "op", "dat", "mijn", "hallo", "bedankt", "wat", "hoe", "wil"},
+}
+
+// DetectLanguage returns the ISO 639-1 code of the language of the text, or an empty string if
+// it cannot be told. Detection is heuristic: the script of the text is used first, then common
+// words for languages written in the Latin script (en, es, fr, de, it, pt and nl).
+func DetectLanguage(text string) string {
+	letters := 0
+	scripts := make([]int, len(scriptLanguages))
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, script := range scriptLanguages {
+			if unicode.IsOneOf(script.tables, r) {
+				scripts[i]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+	for i, script := range scriptLanguages {
+		// Kana is enough to tell Japanese, other scripts must make up most of the text
+		if (script.language == "ja" && scripts[i] > 0) || scripts[i]*2 > letters {
+			return script.language
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	scores := map[string]int{}
+	for language, languageStopwords := range stopwords {
+		for _, word := range words {
+			for _, stopword := range languageStopwords {
+				if word == stopword {
+					scores[language]++
+				}
+			}
+		}
+	}
+
+	best, bestScore, tie := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = language, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+
+	return best
+}
+
+// LanguageMismatch matches when the agent replies in a different language than the last
+// message of the user. Messages whose language cannot be detected are ignored.
+func LanguageMismatch() Matcher {
+	return matcherFunc{
+		description: "agent replied in a different language than the user",
+		match: func(conversation []Message) bool {
+			userLanguage := ""
+			for _, message := range conversation {
+				switch message.Role {
+				case MessageRoleUser:
+					if language := DetectLanguage(message.Content); language != "" {
+						userLanguage = language
+					}
+				case MessageRoleAssistant:
+					language := DetectLanguage(message.Content)
+					if userLanguage != "" && language != "" && language != userLanguage {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	}
+}
@@ -0,0 +1,54 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text     string
+		language string
+	}{
+		{"i want a vegetarian recipe for dinner please", "en"},
+		{"hola, quiero una receta vegetariana para la cena", "es"},
+		{"bonjour, je veux une recette végétarienne", "fr"},
+		{"ich möchte bitte ein vegetarisches Rezept", "de"},
+		{"ciao, vorrei una ricetta vegetariana per la cena", "it"},
+		{"olá, eu quero uma receita vegetariana", "pt"},
+		{"hallo, ik wil een vegetarisch recept", "nl"},
+		{"ベジタリアンのレシピを教えて", "ja"},
+		{"我想要一个素食食谱", "zh"},
+		{"채식 레시피를 알려주세요", "ko"},
+		{"Я хочу вегетарианский рецепт", "ru"},
+		{"ok", ""},
+		{"42!", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.language, DetectLanguage(tt.text))
+		})
+	}
+}
+
+func TestLanguageMismatch(t *testing.T) {
+	matcher := LanguageMismatch()
+	assert.Equal(t, "agent replied in a different language than the user", matcher.String())
+
+	assert.True(t, matcher.Match([]Message{
+		{Role: MessageRoleUser, Content: "hola, quiero una receta"},
+		{Role: MessageRoleAssistant, Content: "Sure, here is the recipe you asked for"},
+	}))
+	assert.False(t, matcher.Match([]Message{
+		{Role: MessageRoleUser, Content: "hola, quiero una receta"},
+		{Role: MessageRoleAssistant, Content: "¡Claro! Aquí tienes una receta para la cena"},
+		{Role: MessageRoleUser, Content: "ok"},
+		{Role: MessageRoleAssistant, Content: "¿Algo más que pueda hacer por ti?"},
+	}), "messages in an undetected language are ignored")
+	assert.False(t, matcher.Match([]Message{
+		{Role: MessageRoleUser, Content: "i want a recipe"},
+		{Role: MessageRoleAssistant, Content: "Tofu!"},
+	}))
+}
@@ -0,0 +1,45 @@
+package scenario
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// leakMinWords is the minimum number of words of a criterion or description for it to be
+	// checked for leaks, shorter texts are too generic to tell a leak from a coincidence.
+	leakMinWords = 4
+
+	// leakWindowWords is the number of consecutive words of a criterion or description that
+	// make a leak when found in a message.
+	leakWindowWords = 6
+)
+
+// leakedText returns the first of the texts quoted verbatim in the message, ignoring case and
+// punctuation. A text is quoted when the message contains it, or leakWindowWords consecutive
+// words of it.
+func leakedText(message string, texts []string) (string, bool) {
+	messageWords := " " + strings.Join(leakWords(message), " ") + " "
+	for _, text := range texts {
+		words := leakWords(text)
+		if len(words) < leakMinWords {
+			continue
+		}
+
+		window := min(len(words), leakWindowWords)
+		for i := 0; i+window <= len(words); i++ {
+			if strings.Contains(messageWords, " "+strings.Join(words[i:i+window], " ")+" ") {
+				return text, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// leakWords splits the text into lowercase words, dropping punctuation.
+func leakWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
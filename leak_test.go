@@ -0,0 +1,77 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeakedText(t *testing.T) {
+	texts := []string{
+		"Agent offers a vegetarian recipe with a full list of ingredients",
+		"Agent is polite",
+	}
+
+	tests := []struct {
+		message string
+		leaked  string
+	}{
+		{"give me a vegetarian recipe pls", ""},
+		{"agent is polite", ""}, // too short to be told from a coincidence
+		{"i need you to offer a vegetarian recipe with a full list of ingredients", "Agent offers a vegetarian recipe with a full list of ingredients"},
+		{"Vegetarian recipe, with a FULL list of ingredients!", "Agent offers a vegetarian recipe with a full list of ingredients"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			leaked, ok := leakedText(tt.message, texts)
+			assert.Equal(t, tt.leaked != "", ok)
+			assert.Equal(t, tt.leaked, leaked)
+		})
+	}
+}
+
+func TestScenario_Run_CriteriaLeakGuard(t *testing.T) {
+	ctx := context.Background()
+	var messages []string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if !firstMessage {
+				return nil, NewSuccessPartialResult(conversation, "Done", []string{}), nil
+			}
+			msg := "please offer a vegetarian recipe with a full list of ingredients"
+			if strings.Contains(strategy, "Never quote") {
+				msg = "any veggie recipes"
+			}
+			messages = append(messages, msg)
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithSuccessCriteria("Agent offers a vegetarian recipe with a full list of ingredients"),
+		WithCriteriaLeakGuard(1),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "any veggie recipes", result.Conversation[0].Content)
+	assert.Len(t, messages, 2)
+
+	s = NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithDescription("User asks for a vegetarian recipe with a full list of ingredients"),
+		WithCriteriaLeakGuard(0),
+	)
+
+	_, err = s.Run(ctx)
+
+	require.ErrorContains(t, err, `simulated user quoted "User asks for a vegetarian recipe with a full list of ingredients" verbatim after 0 regenerations`)
+}
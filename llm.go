@@ -1,6 +1,17 @@
 package scenario
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// ErrDestinationNotAllowed is returned by the LLMCompletion adapters when a request would be
+// sent to a destination that is not in the allowlist set with WithAllowedDestinations.
+var ErrDestinationNotAllowed = errors.New("destination not allowed")
 
 // LLMCompletion is an interface for an LLM that supports completion.
 type LLMCompletion interface {
@@ -15,14 +26,110 @@ type LLMCompletion interface {
 }
 
 type LLMCompletionResponse struct {
-	Choices []LLMCompletionResponseChoice
+	Choices []LLMCompletionResponseChoice `json:"choices"`
 }
 
 type LLMCompletionResponseChoice struct {
-	Message LLMCompletionResponseChoiceMessage
+	Message LLMCompletionResponseChoiceMessage `json:"message"`
 }
 
 type LLMCompletionResponseChoiceMessage struct {
-	Content   string
-	ToolCalls []ToolCall
+	Content   string     `json:"content"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// CompletionOption configures an LLMCompletion adapter shipped with the package, such as
+// NewOpenAICompletion.
+type CompletionOption func(*completionConfig)
+
+// completionConfig is the configuration shared by the LLMCompletion adapters.
+type completionConfig struct {
+	keyPool        *APIKeyPool
+	requestTimeout time.Duration
+	maxRetries     *int
+	httpClient     *http.Client
+	allowedHosts   []string
+}
+
+// WithAPIKeyPool spreads the requests over the keys of the pool instead of the key the
+// client was configured with. With OpenAI compatible providers, keys that run out of quota are
+// disabled and the request is retried with the next key of the pool.
+func WithAPIKeyPool(pool *APIKeyPool) CompletionOption {
+	return func(c *completionConfig) {
+		c.keyPool = pool
+	}
+}
+
+// WithRequestTimeout sets the timeout of every request attempt made by the adapter.
+func WithRequestTimeout(timeout time.Duration) CompletionOption {
+	return func(c *completionConfig) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithMaxRetries sets how many times the adapter retries a failed request.
+func WithMaxRetries(maxRetries int) CompletionOption {
+	return func(c *completionConfig) {
+		c.maxRetries = &maxRetries
+	}
+}
+
+// WithHTTPClient sets the HTTP client used by the adapter, e.g. with a custom transport
+// going through a corporate proxy.
+func WithHTTPClient(client *http.Client) CompletionOption {
+	return func(c *completionConfig) {
+		c.httpClient = client
+	}
+}
+
+// WithAllowedDestinations restricts the hosts the adapter sends requests to, e.g. to make sure
+// transcripts containing sensitive data only reach an on-prem judge model. Hosts are matched
+// with or without their port. Redirects to other hosts are rejected, and an empty allowlist
+// rejects every request.
+func WithAllowedDestinations(hosts ...string) CompletionOption {
+	return func(c *completionConfig) {
+		c.allowedHosts = append([]string{}, hosts...)
+	}
+}
+
+// requestClient returns the HTTP client of the adapter, checking the destination of every redirect
+// when an allowlist is configured.
+func (c *completionConfig) requestClient() *http.Client {
+	client := http.DefaultClient
+	if c.httpClient != nil {
+		client = c.httpClient
+	}
+	if c.allowedHosts == nil {
+		return client
+	}
+
+	checked := *client
+	checkRedirect := client.CheckRedirect
+	checked.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := c.checkDestination(req); err != nil {
+			return err
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		// The default policy of http.Client
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &checked
+}
+
+// checkDestination returns ErrDestinationNotAllowed if the request is sent to a host outside
+// of the allowlist, when one is configured.
+func (c *completionConfig) checkDestination(req *http.Request) error {
+	if c.allowedHosts == nil {
+		return nil
+	}
+	if slices.Contains(c.allowedHosts, req.URL.Host) || slices.Contains(c.allowedHosts, req.URL.Hostname()) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrDestinationNotAllowed, req.URL.Host)
 }
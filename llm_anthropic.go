@@ -0,0 +1,379 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// anthropicDefaultBaseURL is the base URL of the Anthropic API when ANTHROPIC_BASE_URL is
+	// not set.
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+
+	// anthropicVersion is the version of the Anthropic API the adapter speaks.
+	anthropicVersion = "2023-06-01"
+
+	// anthropicDefaultMaxTokens is the max tokens of a request when none is given, the
+	// Messages API requires one.
+	anthropicDefaultMaxTokens = 4096
+
+	// anthropicDefaultMaxRetries is how many times a failed request is retried by default.
+	anthropicDefaultMaxRetries = 2
+)
+
+// AnthropicError is an error returned by the Anthropic API.
+type AnthropicError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Type is the type of the error, e.g. "rate_limit_error".
+	Type string
+
+	// Message is the message of the error.
+	Message string
+}
+
+func (e *AnthropicError) Error() string {
+	return fmt.Sprintf("anthropic: %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// retryable reports whether the request can be retried.
+func (e *AnthropicError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+type anthropicCompletion struct {
+	completionConfig
+
+	model   string
+	apiKey  string
+	baseURL string
+}
+
+// NewAnthropicCompletion creates a new completion against the Anthropic Messages API, with
+// the API key of the ANTHROPIC_API_KEY environment variable and the base URL of
+// ANTHROPIC_BASE_URL when set. System and developer messages are sent as the system prompt.
+func NewAnthropicCompletion(model string, opts ...CompletionOption) *anthropicCompletion {
+	c := &anthropicCompletion{
+		model:   model,
+		apiKey:  os.Getenv("ANTHROPIC_API_KEY"),
+		baseURL: anthropicDefaultBaseURL,
+	}
+	if baseURL := os.Getenv("ANTHROPIC_BASE_URL"); baseURL != "" {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	for _, opt := range opts {
+		opt(&c.completionConfig)
+	}
+	return c
+}
+
+// anthropicRequest is a request of the Messages API.
+type anthropicRequest struct {
+	Model       string               `json:"model"`
+	MaxTokens   int64                `json:"max_tokens"`
+	System      string               `json:"system,omitempty"`
+	Messages    []anthropicMessage   `json:"messages"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Temperature *float64             `json:"temperature,omitempty"`
+}
+
+// anthropicMessage is a message of a Messages API request.
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicContentBlock is a content block of a message, text, tool use or tool result.
+type anthropicContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+// anthropicTool is a tool of a Messages API request.
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// anthropicToolChoice is how the model uses the tools of a Messages API request.
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// anthropicResponse is a response of the Messages API.
+type anthropicResponse struct {
+	Content []anthropicContentBlock `json:"content"`
+	Usage   struct {
+		InputTokens  int64 `json:"input_tokens"`
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Model returns the model the adapter completes with.
+func (c *anthropicCompletion) Model() string {
+	return c.model
+}
+
+// Completion will generate a response from Claude based on the messages, temperature, max tokens, tools, and tool choice.
+func (c *anthropicCompletion) Completion(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+	request := anthropicRequest{
+		Model:       c.model,
+		MaxTokens:   anthropicDefaultMaxTokens,
+		Temperature: temperature,
+	}
+	if maxTokens != nil {
+		request.MaxTokens = *maxTokens
+	}
+
+	var err error
+	request.System, request.Messages, err = anthropicMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range tools {
+		if tool.Type != ToolTypeFunction {
+			return nil, fmt.Errorf("tool type is not function: %s", tool.Type)
+		}
+		request.Tools = append(request.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: tool.Function.Parameters,
+		})
+	}
+	if toolChoice != nil && len(tools) > 0 {
+		switch *toolChoice {
+		case "required":
+			request.ToolChoice = &anthropicToolChoice{Type: "any"}
+		case "auto", "none":
+			request.ToolChoice = &anthropicToolChoice{Type: *toolChoice}
+		default:
+			request.ToolChoice = &anthropicToolChoice{Type: "tool", Name: *toolChoice}
+		}
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal messages request: %w", err)
+	}
+
+	apiKey := c.apiKey
+	if c.keyPool != nil {
+		apiKey, err = c.keyPool.Acquire()
+		if err != nil {
+			return nil, err
+		}
+		defer c.keyPool.Release(apiKey)
+	}
+
+	start := time.Now()
+	response, err := c.send(ctx, payload, apiKey)
+	auditEntry := AuditEntry{
+		Time:          start,
+		Provider:      "anthropic",
+		Endpoint:      "messages",
+		Model:         c.model,
+		Duration:      time.Since(start),
+		PayloadSHA256: hashPayload(payload),
+	}
+	if err != nil {
+		auditEntry.Error = err.Error()
+		RecordAuditEntry(ctx, auditEntry)
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+	auditEntry.PromptTokens = response.Usage.InputTokens
+	auditEntry.CompletionTokens = response.Usage.OutputTokens
+	RecordAuditEntry(ctx, auditEntry)
+
+	message := LLMCompletionResponseChoiceMessage{}
+	var texts []string
+	for _, block := range response.Content {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "tool_use":
+			var args map[string]any
+			if err := json.Unmarshal(block.Input, &args); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tool use input of %s: %w", block.Name, err)
+			}
+			message.ToolCalls = append(message.ToolCalls, ToolCall{
+				ID:   block.ID,
+				Type: ToolTypeFunction,
+				Function: &ToolCallFunction{
+					Name:      block.Name,
+					Arguments: args,
+				},
+			})
+		}
+	}
+	message.Content = strings.Join(texts, "")
+
+	return &LLMCompletionResponse{
+		Choices: []LLMCompletionResponseChoice{{Message: message}},
+	}, nil
+}
+
+// send sends the request to the Messages API, retrying on rate limits and server errors.
+func (c *anthropicCompletion) send(ctx context.Context, payload []byte, apiKey string) (*anthropicResponse, error) {
+	maxRetries := anthropicDefaultMaxRetries
+	if c.maxRetries != nil {
+		maxRetries = *c.maxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.sendOnce(ctx, payload, apiKey)
+		if err == nil || attempt == maxRetries || !anthropicRetryable(err) || ctx.Err() != nil {
+			return response, err
+		}
+
+		RecordDiagnostic(ctx, Diagnostic{
+			Code:      DiagnosticProviderRetry,
+			Message:   fmt.Sprintf("retrying anthropic messages request after attempt %d: %v", attempt+1, err),
+			Retryable: true,
+		})
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(500 * time.Millisecond << attempt):
+		}
+	}
+}
+
+// anthropicRetryable reports whether a failed request can be retried: rate limits, server
+// errors and transport errors.
+func anthropicRetryable(err error) bool {
+	var apiErr *AnthropicError
+	if errors.As(err, &apiErr) {
+		return apiErr.retryable()
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// sendOnce makes a single attempt of the request.
+func (c *anthropicCompletion) sendOnce(ctx context.Context, payload []byte, apiKey string) (*anthropicResponse, error) {
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkDestination(req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("Anthropic-Version", anthropicVersion)
+
+	resp, err := c.requestClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &AnthropicError{StatusCode: resp.StatusCode, Message: string(body)}
+		var errorBody struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &errorBody) == nil && errorBody.Error.Type != "" {
+			apiErr.Type = errorBody.Error.Type
+			apiErr.Message = errorBody.Error.Message
+		}
+		return nil, apiErr
+	}
+
+	var response anthropicResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	return &response, nil
+}
+
+// anthropicMessages converts the messages to the system prompt and the messages of a Messages
+// API request. Tool results are sent as user messages, and consecutive messages of the same
+// role are merged as the API expects alternating roles.
+func anthropicMessages(messages []Message) (string, []anthropicMessage, error) {
+	// Tool calls without results are left out, the API rejects them
+	answered := answeredToolCalls(messages)
+
+	var system []string
+	var converted []anthropicMessage
+	for _, message := range messages {
+		var role string
+		var blocks []anthropicContentBlock
+		switch message.Role {
+		case MessageRoleSystem, MessageRoleDeveloper:
+			system = append(system, message.Content)
+			continue
+		case MessageRoleUser:
+			role = "user"
+			if message.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: message.Content})
+			}
+		case MessageRoleAssistant:
+			role = "assistant"
+			if message.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: message.Content})
+			}
+			for _, toolCall := range message.ToolCalls {
+				if !answered[toolCall.ID] || toolCall.Function == nil {
+					continue
+				}
+				input := json.RawMessage("{}")
+				if toolCall.Function.Arguments != nil {
+					var err error
+					if input, err = json.Marshal(toolCall.Function.Arguments); err != nil {
+						return "", nil, fmt.Errorf("failed to marshal tool call arguments: %w", err)
+					}
+				}
+				blocks = append(blocks, anthropicContentBlock{Type: "tool_use", ID: toolCall.ID, Name: toolCall.Function.Name, Input: input})
+			}
+		case MessageRoleTool:
+			role = "user"
+			blocks = append(blocks, anthropicContentBlock{Type: "tool_result", ToolUseID: message.ToolCallID, Content: message.Content})
+		default:
+			return "", nil, fmt.Errorf("unknown message role: %s", message.Role)
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if last := len(converted) - 1; last >= 0 && converted[last].Role == role {
+			converted[last].Content = append(converted[last].Content, blocks...)
+			continue
+		}
+		converted = append(converted, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	return strings.Join(system, "\n\n"), converted, nil
+}
@@ -0,0 +1,129 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnthropicCompletion_FinishTest(t *testing.T) {
+	var request map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("Anthropic-Version"))
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &request))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"content": [
+				{"type": "text", "text": "Judging."},
+				{"type": "tool_use", "id": "toolu_1", "name": "finish_test", "input": {"verdict": "success", "reasoning": "helpful", "met_criteria": ["Agent helps"], "unmet_criteria": [], "triggered_failures": []}}
+			],
+			"usage": {"input_tokens": 120, "output_tokens": 30}
+		}`))
+	}))
+	defer server.Close()
+	t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+	t.Setenv("ANTHROPIC_API_KEY", "key")
+
+	testingAgent := NewTestingAgent(NewAnthropicCompletion("claude-sonnet-4-5"))
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "help"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "search"}}}},
+		{Role: MessageRoleTool, ToolCallID: "call_1", Content: "found"},
+		{Role: MessageRoleAssistant, Content: "sure"},
+	}
+
+	ctx, auditLog := withAuditLog(context.Background(), nil)
+	_, result, err := testingAgent.GenerateNextMessage(ctx, "description", "strategy", []string{"Agent helps"}, nil, conversation, false, true)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{"Agent helps"}, result.MetCriteria)
+
+	assert.Equal(t, "claude-sonnet-4-5", request["model"])
+	assert.EqualValues(t, anthropicDefaultMaxTokens, request["max_tokens"])
+	assert.Contains(t, request["system"], "description")
+	assert.Equal(t, map[string]any{"type": "any"}, request["tool_choice"])
+	tools := request["tools"].([]any)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "finish_test", tools[0].(map[string]any)["name"])
+	assert.NotNil(t, tools[0].(map[string]any)["input_schema"])
+
+	// The roles are reversed for the testing agent, the tool result being merged with the
+	// messages following it
+	messages := request["messages"].([]any)
+	roles := make([]string, len(messages))
+	for i, message := range messages {
+		roles[i] = message.(map[string]any)["role"].(string)
+	}
+	assert.Equal(t, []string{"user", "assistant", "user"}, roles)
+
+	entries := auditLog.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "anthropic", entries[0].Provider)
+	assert.Equal(t, int64(120), entries[0].PromptTokens)
+	assert.Equal(t, int64(30), entries[0].CompletionTokens)
+}
+
+func TestAnthropicMessages(t *testing.T) {
+	system, messages, err := anthropicMessages([]Message{
+		{Role: MessageRoleSystem, Content: "be brief"},
+		{Role: MessageRoleUser, Content: "weather?"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{
+			{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "weather", Arguments: map[string]any{"city": "Paris"}}},
+			{ID: "call_2", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "unanswered"}},
+		}},
+		{Role: MessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+		{Role: MessageRoleAssistant, Content: "It is sunny."},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "be brief", system)
+	assert.Equal(t, []anthropicMessage{
+		{Role: "user", Content: []anthropicContentBlock{{Type: "text", Text: "weather?"}}},
+		{Role: "assistant", Content: []anthropicContentBlock{{Type: "tool_use", ID: "call_1", Name: "weather", Input: json.RawMessage(`{"city":"Paris"}`)}}},
+		{Role: "user", Content: []anthropicContentBlock{{Type: "tool_result", ToolUseID: "call_1", Content: "sunny"}}},
+		{Role: "assistant", Content: []anthropicContentBlock{{Type: "text", Text: "It is sunny."}}},
+	}, messages)
+}
+
+func TestAnthropicCompletion_Errors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type": "error", "error": {"type": "invalid_request_error", "message": "max_tokens: too large"}}`))
+	}))
+	defer server.Close()
+	t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+
+	_, err := NewAnthropicCompletion("claude-sonnet-4-5").Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	var apiErr *AnthropicError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "invalid_request_error", apiErr.Type)
+	assert.Equal(t, 1, attempts, "client errors are not retried")
+
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	_, err = NewAnthropicCompletion("claude-sonnet-4-5", WithMaxRetries(1)).Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, 3, attempts, "server errors are retried")
+
+	_, err = NewAnthropicCompletion("claude-sonnet-4-5", WithAllowedDestinations("api.anthropic.com")).Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrDestinationNotAllowed)
+}
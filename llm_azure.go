@@ -0,0 +1,34 @@
+package scenario
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// providerAzureOpenAI is the provider of the calls of an Azure OpenAI completion.
+const providerAzureOpenAI = "azure_openai"
+
+// NewAzureOpenAICompletion creates a new completion against a deployment of an Azure OpenAI
+// resource, e.g. https://<resource>.openai.azure.com, with the API version, e.g.
+// "2024-06-01", and the API key of the AZURE_OPENAI_API_KEY environment variable sent in the
+// Api-Key header. API keys of an APIKeyPool are sent the same way. The deployment is reported
+// as the model of the adapter.
+func NewAzureOpenAICompletion(deployment, endpoint, apiVersion string, opts ...CompletionOption) *openAICompletion {
+	client := openai.NewClient(
+		option.WithBaseURL(strings.TrimSuffix(endpoint, "/")+"/openai/deployments/"+url.PathEscape(deployment)+"/"),
+		option.WithQuery("api-version", apiVersion),
+		// The OpenAI credentials of the environment are not sent to Azure
+		option.WithHeaderDel("Authorization"),
+		option.WithHeaderDel("OpenAI-Organization"),
+		option.WithHeaderDel("OpenAI-Project"),
+		option.WithHeader("Api-Key", os.Getenv("AZURE_OPENAI_API_KEY")),
+	)
+
+	c := NewOpenAICompletionWithClient(deployment, client, opts...)
+	c.provider = providerAzureOpenAI
+	return c
+}
@@ -0,0 +1,62 @@
+package scenario
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureOpenAICompletion(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "openai-key")
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-key")
+
+	var request *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}], "usage": {"prompt_tokens": 3, "completion_tokens": 1}}`))
+	}))
+	defer server.Close()
+
+	completion := NewAzureOpenAICompletion("judge-gpt4o", server.URL+"/", "2024-06-01", WithMaxRetries(0))
+	ctx, log := withAuditLog(context.Background(), nil)
+
+	resp, err := completion.Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, "judge-gpt4o", completion.Model())
+	require.NotNil(t, request)
+	assert.Equal(t, "/openai/deployments/judge-gpt4o/chat/completions", request.URL.Path)
+	assert.Equal(t, "2024-06-01", request.URL.Query().Get("api-version"))
+	assert.Equal(t, "azure-key", request.Header.Get("Api-Key"))
+	assert.Empty(t, request.Header.Get("Authorization"), "the OpenAI key is not sent to Azure")
+
+	entries := log.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "azure_openai", entries[0].Provider)
+	assert.Equal(t, "az.ai.openai", entries[0].GenAIAttributes()["gen_ai.system"])
+}
+
+func TestAzureOpenAICompletion_APIKeyPool(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Api-Key")+"|"+r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer server.Close()
+
+	pool := NewAPIKeyPool(KeySelectionRoundRobin, "first", "second")
+	completion := NewAzureOpenAICompletion("judge", server.URL, "2024-06-01", WithAPIKeyPool(pool), WithMaxRetries(0))
+
+	for range 2 {
+		_, err := completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"first|", "second|"}, keys)
+}
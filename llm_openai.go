@@ -3,37 +3,54 @@ package scenario
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"time"
 
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
 	"github.com/openai/openai-go/packages/param"
 	"github.com/openai/openai-go/shared"
 	"github.com/openai/openai-go/shared/constant"
 )
 
 type openAICompletion struct {
-	model  string
-	client openai.Client
+	completionConfig
+
+	model    string
+	client   openai.Client
+	provider string
 }
 
 // NewOpenAICompletion creates a new OpenAI completion.
-func NewOpenAICompletion(model string) *openAICompletion {
-	return &openAICompletion{
-		model:  model,
-		client: openai.NewClient(),
-	}
+func NewOpenAICompletion(model string, opts ...CompletionOption) *openAICompletion {
+	return NewOpenAICompletionWithClient(model, openai.NewClient(), opts...)
 }
 
 // NewOpenAICompletionWithClient creates a new OpenAI completion with a specific client.
-func NewOpenAICompletionWithClient(model string, client openai.Client) *openAICompletion {
-	return &openAICompletion{
-		model:  model,
-		client: client,
+func NewOpenAICompletionWithClient(model string, client openai.Client, opts ...CompletionOption) *openAICompletion {
+	c := &openAICompletion{
+		model:    model,
+		client:   client,
+		provider: "openai",
+	}
+	for _, opt := range opts {
+		opt(&c.completionConfig)
 	}
+	return c
+}
+
+// Model returns the model the adapter completes with.
+func (c *openAICompletion) Model() string {
+	return c.model
 }
 
 // Completion will generate a response from an LLM based on the messages, temperature, max tokens, tools, and tool choice.
 func (c *openAICompletion) Completion(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+	// Tool calls without results are left out, providers reject them
+	answered := answeredToolCalls(messages)
 	openaiMessages := make([]openai.ChatCompletionMessageParamUnion, len(messages))
 	for i, message := range messages {
 		switch message.Role {
@@ -41,6 +58,24 @@ func (c *openAICompletion) Completion(ctx context.Context, messages []Message, t
 			openaiMessages[i] = openai.UserMessage(message.Content)
 		case MessageRoleAssistant:
 			openaiMessages[i] = openai.AssistantMessage(message.Content)
+			for _, toolCall := range message.ToolCalls {
+				if !answered[toolCall.ID] || toolCall.Function == nil {
+					continue
+				}
+				arguments, err := json.Marshal(toolCall.Function.Arguments)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal tool call arguments: %w", err)
+				}
+				openaiMessages[i].OfAssistant.ToolCalls = append(openaiMessages[i].OfAssistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+					ID: toolCall.ID,
+					Function: openai.ChatCompletionMessageToolCallFunctionParam{
+						Name:      toolCall.Function.Name,
+						Arguments: string(arguments),
+					},
+				})
+			}
+		case MessageRoleTool:
+			openaiMessages[i] = openai.ToolMessage(message.Content, message.ToolCallID)
 		case MessageRoleSystem:
 			openaiMessages[i] = openai.SystemMessage(message.Content)
 		case MessageRoleDeveloper:
@@ -78,12 +113,110 @@ func (c *openAICompletion) Completion(ctx context.Context, messages []Message, t
 	if maxTokens != nil {
 		params.MaxTokens = openai.Int(*maxTokens)
 	}
+	batch, batched := ctx.Value(batchRequestContextKey{}).(*batchRequest)
+	if batched {
+		return batch.complete(ctx, c.model, params)
+	}
+	stream := completionProgressRequested(ctx)
+	if stream {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	}
 
-	chatCompletion, err := c.client.Chat.Completions.New(ctx, params)
+	payload, err := json.Marshal(params)
 	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chat completion params: %w", err)
+	}
+
+	// attempts counts the attempts of the request with the current key of the pool
+	attempts := 0
+	requestOpts := []option.RequestOption{
+		option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+			if err := c.checkDestination(req); err != nil {
+				return nil, err
+			}
+			attempts++
+			if attempts > 1 {
+				RecordDiagnostic(ctx, Diagnostic{
+					Code:      DiagnosticProviderRetry,
+					Message:   fmt.Sprintf("retrying %s chat/completions request, attempt %d", c.provider, attempts),
+					Retryable: true,
+				})
+			}
+			return next(req)
+		}),
+	}
+	if c.requestTimeout > 0 {
+		requestOpts = append(requestOpts, option.WithRequestTimeout(c.requestTimeout))
+	}
+	if c.maxRetries != nil {
+		requestOpts = append(requestOpts, option.WithMaxRetries(*c.maxRetries))
+	}
+	if c.httpClient != nil || c.allowedHosts != nil {
+		requestOpts = append(requestOpts, option.WithHTTPClient(c.requestClient()))
+	}
+
+	var chatCompletion *openai.ChatCompletion
+	var quotaErr error
+	for {
+		var apiKey string
+		keyOpts := requestOpts
+		if c.keyPool != nil {
+			apiKey, err = c.keyPool.Acquire()
+			if err != nil {
+				if quotaErr != nil {
+					return nil, fmt.Errorf("failed to create chat completion: %w", errors.Join(err, quotaErr))
+				}
+				return nil, err
+			}
+			if c.provider == providerAzureOpenAI {
+				keyOpts = append(slices.Clip(requestOpts), option.WithHeader("Api-Key", apiKey))
+			} else {
+				keyOpts = append(slices.Clip(requestOpts), option.WithAPIKey(apiKey))
+			}
+		}
+
+		attempts = 0
+		start := time.Now()
+		if stream {
+			chatCompletion, err = c.streamChatCompletion(ctx, params, keyOpts)
+		} else {
+			chatCompletion, err = c.client.Chat.Completions.New(ctx, params, keyOpts...)
+		}
+		if c.keyPool != nil {
+			c.keyPool.Release(apiKey)
+		}
+		auditEntry := AuditEntry{
+			Time:          start,
+			Provider:      c.provider,
+			Endpoint:      "chat/completions",
+			Model:         c.model,
+			Duration:      time.Since(start),
+			PayloadSHA256: hashPayload(payload),
+		}
+		if err == nil {
+			auditEntry.PromptTokens = chatCompletion.Usage.PromptTokens
+			auditEntry.CompletionTokens = chatCompletion.Usage.CompletionTokens
+			RecordAuditEntry(ctx, auditEntry)
+			break
+		}
+
+		auditEntry.Error = err.Error()
+		RecordAuditEntry(ctx, auditEntry)
+		var apiErr *openai.Error
+		if c.keyPool != nil && errors.As(err, &apiErr) && apiErr.Code == "insufficient_quota" {
+			// Retry with the next key of the pool until none is left
+			c.keyPool.Disable(apiKey)
+			quotaErr = err
+			continue
+		}
 		return nil, fmt.Errorf("failed to create chat completion: %w", err)
 	}
 
+	return completionResponse(chatCompletion)
+}
+
+// completionResponse converts an OpenAI chat completion to an LLMCompletionResponse.
+func completionResponse(chatCompletion *openai.ChatCompletion) (*LLMCompletionResponse, error) {
 	response := &LLMCompletionResponse{
 		Choices: make([]LLMCompletionResponseChoice, len(chatCompletion.Choices)),
 	}
@@ -115,3 +248,33 @@ func (c *openAICompletion) Completion(ctx context.Context, messages []Message, t
 
 	return response, nil
 }
+
+// streamChatCompletion creates a chat completion by streaming it, reporting the tokens received
+// so far with ReportCompletionProgress.
+func (c *openAICompletion) streamChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams, requestOpts []option.RequestOption) (*openai.ChatCompletion, error) {
+	stream := c.client.Chat.Completions.NewStreaming(ctx, params, requestOpts...)
+	defer stream.Close()
+
+	var acc openai.ChatCompletionAccumulator
+	var tokens int64
+	for stream.Next() {
+		chunk := stream.Current()
+		if !acc.AddChunk(chunk) {
+			return nil, errors.New("failed to accumulate chat completion chunk")
+		}
+		if len(chunk.Choices) > 0 {
+			tokens++
+			ReportCompletionProgress(ctx, tokens, false)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	if acc.Usage.CompletionTokens > 0 {
+		tokens = acc.Usage.CompletionTokens
+	}
+	ReportCompletionProgress(ctx, tokens, true)
+
+	return &acc.ChatCompletion, nil
+}
@@ -0,0 +1,255 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAICompletion_APIKeyPool(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") == "Bearer exhausted" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"code": "insufficient_quota", "message": "quota exceeded", "type": "insufficient_quota"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer server.Close()
+
+	pool := NewAPIKeyPool(KeySelectionRoundRobin, "exhausted", "valid")
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAPIKeyPool(pool))
+
+	resp, err := completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+	require.NoError(t, err, "the request is retried with the next key")
+	assert.Equal(t, "hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, 1, pool.Available())
+
+	_, err = completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer exhausted", "Bearer valid", "Bearer valid"}, authorizations)
+}
+
+func TestOpenAICompletion_APIKeyPoolExhausted(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error": {"code": "insufficient_quota", "message": "quota exceeded", "type": "insufficient_quota"}}`))
+	}))
+	defer server.Close()
+
+	pool := NewAPIKeyPool(KeySelectionRoundRobin, "first", "second")
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithMaxRetries(0))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAPIKeyPool(pool))
+
+	_, err := completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.ErrorIs(t, err, ErrNoAPIKeyAvailable)
+	assert.ErrorContains(t, err, "quota exceeded")
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 0, pool.Available())
+}
+
+func TestOpenAICompletion_APIKeyPoolRetryDiagnostics(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") == "Bearer exhausted" {
+			w.Header().Set("Retry-After-Ms", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error": {"code": "insufficient_quota", "message": "quota exceeded", "type": "insufficient_quota"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer server.Close()
+
+	pool := NewAPIKeyPool(KeySelectionRoundRobin, "exhausted", "valid")
+	client := openai.NewClient(option.WithBaseURL(server.URL))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAPIKeyPool(pool), WithMaxRetries(1))
+	ctx, diagnostics := withDiagnostics(context.Background())
+
+	_, err := completion.Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer exhausted", "Bearer exhausted", "Bearer valid"}, authorizations)
+	var retries []string
+	for _, diagnostic := range diagnostics.Diagnostics() {
+		if diagnostic.Code == DiagnosticProviderRetry {
+			retries = append(retries, diagnostic.Message)
+		}
+	}
+	assert.Equal(t, []string{"retrying openai chat/completions request, attempt 2"}, retries, "the attempts are counted per key")
+}
+
+func TestOpenAICompletion_RequestOptions(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	transportCalls := 0
+	httpClient := &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		transportCalls++
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client,
+		WithMaxRetries(1),
+		WithRequestTimeout(time.Second),
+		WithHTTPClient(httpClient),
+	)
+
+	_, err := completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.Error(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, transportCalls)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestOpenAICompletion_AllowedDestinations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"), option.WithMaxRetries(0))
+	messages := []Message{{Role: MessageRoleUser, Content: "hi"}}
+
+	allowed := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAllowedDestinations("127.0.0.1"))
+	_, err := allowed.Completion(context.Background(), messages, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	denied := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAllowedDestinations("judge.internal"))
+	_, err = denied.Completion(context.Background(), messages, nil, nil, nil, nil)
+	require.ErrorIs(t, err, ErrDestinationNotAllowed)
+
+	empty := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAllowedDestinations())
+	_, err = empty.Completion(context.Background(), messages, nil, nil, nil, nil)
+	require.ErrorIs(t, err, ErrDestinationNotAllowed, "an empty allowlist denies every destination")
+}
+
+func TestOpenAICompletion_AllowedDestinationsRedirect(t *testing.T) {
+	leaked := false
+	outside := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = true
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer outside.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Redirect to the same server under another host name, outside of the allowlist
+		http.Redirect(w, r, strings.Replace(outside.URL, "127.0.0.1", "localhost", 1)+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"), option.WithMaxRetries(0))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client, WithAllowedDestinations("127.0.0.1"))
+
+	_, err := completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.ErrorIs(t, err, ErrDestinationNotAllowed)
+	assert.False(t, leaked, "the transcript is not sent to the redirect destination")
+}
+
+func TestOpenAICompletion_StreamingProgress(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id": "1", "choices": [{"index": 0, "delta": {"role": "assistant", "content": "hel"}}]}`,
+			`{"id": "1", "choices": [{"index": 0, "delta": {"content": "lo"}}]}`,
+			`{"id": "1", "choices": [{"index": 0, "delta": {}, "finish_reason": "stop"}]}`,
+			`{"id": "1", "choices": [], "usage": {"prompt_tokens": 10, "completion_tokens": 2, "total_tokens": 12}}`,
+		} {
+			_, _ = w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client)
+
+	var progress []JudgeProgress
+	ctx := withCompletionProgress(context.Background(), func(tokens int64, done bool) {
+		progress = append(progress, JudgeProgress{Tokens: tokens, Done: done})
+	})
+	resp, err := completion.Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp.Choices[0].Message.Content)
+	assert.Equal(t, true, requests[0]["stream"])
+	assert.Equal(t, []JudgeProgress{{Tokens: 1}, {Tokens: 2}, {Tokens: 3}, {Tokens: 2, Done: true}}, progress)
+
+	_, err = completion.Completion(context.Background(), []Message{{Role: MessageRoleUser, Content: "hi"}}, nil, nil, nil, nil)
+	require.Error(t, err, "responses are not streamed without progress reporting")
+	assert.NotContains(t, requests[1], "stream")
+}
+
+func TestOpenAICompletion_ToolCallChain(t *testing.T) {
+	var body struct {
+		Messages []map[string]any `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "hello"}}]}`))
+	}))
+	defer server.Close()
+
+	client := openai.NewClient(option.WithBaseURL(server.URL), option.WithAPIKey("key"), option.WithMaxRetries(0))
+	completion := NewOpenAICompletionWithClient("gpt-4o-mini", client)
+	messages := []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{
+			{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "lookup_order", Arguments: map[string]any{"id": "42"}}},
+		}},
+		{Role: MessageRoleTool, Content: "shipped", ToolCallID: "call_1"},
+		{Role: MessageRoleAssistant, Content: "it shipped", ToolCalls: []ToolCall{
+			{ID: "call_2", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "notify"}},
+		}},
+	}
+
+	_, err := completion.Completion(context.Background(), messages, nil, nil, nil, nil)
+
+	require.NoError(t, err)
+	require.Len(t, body.Messages, 4)
+	assert.Equal(t, []any{map[string]any{
+		"id":       "call_1",
+		"type":     "function",
+		"function": map[string]any{"name": "lookup_order", "arguments": `{"id":"42"}`},
+	}}, body.Messages[1]["tool_calls"])
+	assert.Equal(t, map[string]any{"role": "tool", "content": "shipped", "tool_call_id": "call_1"}, body.Messages[2])
+	assert.NotContains(t, body.Messages[3], "tool_calls", "tool calls without results are left out")
+}
@@ -0,0 +1,207 @@
+package scenario
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Matcher is a programmatic assertion over a conversation, usable as a stop condition or as
+// a success or failure criterion that does not need to be judged by the testing agent.
+type Matcher interface {
+	// Match reports whether the conversation satisfies the matcher.
+	Match(conversation []Message) bool
+
+	// String describes the matcher, it is used as the criterion in results.
+	String() string
+}
+
+// matcherFunc is a Matcher backed by a function.
+type matcherFunc struct {
+	description string
+	match       func(conversation []Message) bool
+}
+
+func (m matcherFunc) Match(conversation []Message) bool {
+	return m.match(conversation)
+}
+
+func (m matcherFunc) String() string {
+	return m.description
+}
+
+// Contains matches when any message of the conversation contains substr.
+func Contains(substr string) Matcher {
+	return contentMatcher(fmt.Sprintf("a message contains %q", substr), "", func(content string) bool {
+		return strings.Contains(content, substr)
+	})
+}
+
+// MatchesRegexp matches when any message of the conversation matches the regular expression.
+// It panics if the pattern cannot be compiled.
+func MatchesRegexp(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return contentMatcher(fmt.Sprintf("a message matches %q", pattern), "", re.MatchString)
+}
+
+// Not inverts the given matcher.
+func Not(m Matcher) Matcher {
+	return matcherFunc{
+		description: fmt.Sprintf("not (%s)", m),
+		match: func(conversation []Message) bool {
+			return !m.Match(conversation)
+		},
+	}
+}
+
+// RoleSelector selects the messages of the conversation with a given role.
+type RoleSelector struct {
+	role MessageRole
+}
+
+// Role selects the messages of the conversation with the given role.
+func Role(role MessageRole) RoleSelector {
+	return RoleSelector{role: role}
+}
+
+// Contains matches when any message with the selected role contains substr.
+func (r RoleSelector) Contains(substr string) Matcher {
+	return contentMatcher(fmt.Sprintf("a %s message contains %q", r.role, substr), r.role, func(content string) bool {
+		return strings.Contains(content, substr)
+	})
+}
+
+// MatchesRegexp matches when any message with the selected role matches the regular
+// expression. It panics if the pattern cannot be compiled.
+func (r RoleSelector) MatchesRegexp(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return contentMatcher(fmt.Sprintf("a %s message matches %q", r.role, pattern), r.role, re.MatchString)
+}
+
+// Count counts the messages with the selected role.
+func (r RoleSelector) Count() Counter {
+	return Counter{
+		description: fmt.Sprintf("%s messages", r.role),
+		count: func(conversation []Message) int {
+			count := 0
+			for _, message := range conversation {
+				if message.Role == r.role {
+					count++
+				}
+			}
+			return count
+		},
+	}
+}
+
+// ToolCalled counts the calls to the tool with the given name.
+func ToolCalled(name string) Counter {
+	return Counter{
+		description: fmt.Sprintf("calls to tool %q", name),
+		count: func(conversation []Message) int {
+			count := 0
+			for _, message := range conversation {
+				for _, toolCall := range message.ToolCalls {
+					if toolCall.Function != nil && toolCall.Function.Name == name {
+						count++
+					}
+				}
+			}
+			return count
+		},
+	}
+}
+
+// forbiddenToolCalled matches when the tool with the given name is called.
+func forbiddenToolCalled(name string) Matcher {
+	return toolCallMatcher(fmt.Sprintf("forbidden tool %q was called", name), func(toolName string) bool {
+		return toolName == name
+	})
+}
+
+// toolCalledOutside matches when a tool other than the allowed ones is called.
+func toolCalledOutside(allowed []string) Matcher {
+	return toolCallMatcher(fmt.Sprintf("a tool outside of the allowed tools %q was called", allowed), func(toolName string) bool {
+		return !slices.Contains(allowed, toolName)
+	})
+}
+
+// toolCallMatcher matches when the name of any tool called in the conversation satisfies match.
+func toolCallMatcher(description string, match func(toolName string) bool) Matcher {
+	return matcherFunc{
+		description: description,
+		match: func(conversation []Message) bool {
+			for _, message := range conversation {
+				for _, toolCall := range message.ToolCalls {
+					if toolCall.Function != nil && match(toolCall.Function.Name) {
+						return true
+					}
+				}
+			}
+			return false
+		},
+	}
+}
+
+// Counter counts occurrences in a conversation, use one of its methods to turn it into a Matcher.
+type Counter struct {
+	description string
+	count       func(conversation []Message) int
+}
+
+// AtMost matches when the count is less than or equal to n.
+func (c Counter) AtMost(n int) Matcher {
+	return c.matcher(fmt.Sprintf("at most %d %s", n, c.description), func(count int) bool {
+		return count <= n
+	})
+}
+
+// AtLeast matches when the count is greater than or equal to n.
+func (c Counter) AtLeast(n int) Matcher {
+	return c.matcher(fmt.Sprintf("at least %d %s", n, c.description), func(count int) bool {
+		return count >= n
+	})
+}
+
+// Exactly matches when the count is equal to n.
+func (c Counter) Exactly(n int) Matcher {
+	return c.matcher(fmt.Sprintf("exactly %d %s", n, c.description), func(count int) bool {
+		return count == n
+	})
+}
+
+// Never matches when the count is zero.
+func (c Counter) Never() Matcher {
+	return c.matcher(fmt.Sprintf("no %s", c.description), func(count int) bool {
+		return count == 0
+	})
+}
+
+func (c Counter) matcher(description string, compare func(count int) bool) Matcher {
+	return matcherFunc{
+		description: description,
+		match: func(conversation []Message) bool {
+			return compare(c.count(conversation))
+		},
+	}
+}
+
+// contentMatcher matches when the content of any message with the given role (or any role,
+// if empty) satisfies match.
+func contentMatcher(description string, role MessageRole, match func(content string) bool) Matcher {
+	return matcherFunc{
+		description: description,
+		match: func(conversation []Message) bool {
+			for _, message := range conversation {
+				if role != "" && message.Role != role {
+					continue
+				}
+				if match(message.Content) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchers(t *testing.T) {
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "i want a refund"},
+		{Role: MessageRoleAssistant, Content: "Your refund ID is R-123."},
+		{Role: MessageRoleAssistant, Content: "Anything else?", ToolCalls: []ToolCall{{
+			Type:     ToolTypeFunction,
+			Function: &ToolCallFunction{Name: "lookup_order"},
+		}}},
+	}
+
+	tests := []struct {
+		name        string
+		matcher     Matcher
+		match       bool
+		description string
+	}{
+		{"Contains", Contains("refund ID"), true, `a message contains "refund ID"`},
+		{"Contains no match", Contains("cancel"), false, `a message contains "cancel"`},
+		{"MatchesRegexp", MatchesRegexp(`R-\d+`), true, `a message matches "R-\\d+"`},
+		{"Not", Not(Contains("cancel")), true, `not (a message contains "cancel")`},
+		{"Role Contains", Role(MessageRoleUser).Contains("refund ID"), false, `a user message contains "refund ID"`},
+		{"Role MatchesRegexp", Role(MessageRoleAssistant).MatchesRegexp(`^Anything`), true, `a assistant message matches "^Anything"`},
+		{"Role Count AtMost", Role(MessageRoleAssistant).Count().AtMost(1), false, "at most 1 assistant messages"},
+		{"Role Count AtLeast", Role(MessageRoleAssistant).Count().AtLeast(2), true, "at least 2 assistant messages"},
+		{"Role Count Exactly", Role(MessageRoleUser).Count().Exactly(1), true, "exactly 1 user messages"},
+		{"ToolCalled Never", ToolCalled("send_email").Never(), true, `no calls to tool "send_email"`},
+		{"ToolCalled AtLeast", ToolCalled("lookup_order").AtLeast(1), true, `at least 1 calls to tool "lookup_order"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.matcher.Match(conversation))
+			assert.Equal(t, tt.description, tt.matcher.String())
+		})
+	}
+}
+
+func TestMatchesRegexp_InvalidPattern(t *testing.T) {
+	assert.Panics(t, func() {
+		MatchesRegexp("(")
+	})
+}
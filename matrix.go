@@ -0,0 +1,141 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"text/tabwriter"
+)
+
+// AgentConfig is an agent configuration of a matrix run, e.g. a model, a prompt version or a
+// feature flag.
+type AgentConfig struct {
+	// Name is the name of the configuration in the comparison grid.
+	Name string
+
+	// Options are applied to every scenario for the configuration, e.g. WithAgent with the
+	// agent built for the configuration.
+	Options []ScenarioOption
+}
+
+// MatrixCell is the run of a scenario with an agent configuration.
+type MatrixCell struct {
+	// Result is the result of the run, nil if it failed with an error.
+	Result *Result
+
+	// Err is the error of the run.
+	Err error
+}
+
+// MatrixResult is the comparison grid of a matrix run.
+type MatrixResult struct {
+	// Scenarios are the IDs of the scenarios, the rows of the grid.
+	Scenarios []string
+
+	// Configs are the names of the agent configurations, the columns of the grid.
+	Configs []string
+
+	// Cells are the runs, indexed by scenario then configuration.
+	Cells [][]MatrixCell
+}
+
+// RunMatrix runs every scenario with every agent configuration. The configurations of a
+// scenario share the seed of the simulator, set with WithSeed or drawn once per scenario, so
+// they are compared on the same simulated users. Scenarios must have been created with
+// NewScenario, the options of the configurations are applied to a copy of each of them.
+func RunMatrix(ctx context.Context, scenarios []Scenario, configs []AgentConfig) (*MatrixResult, error) {
+	matrix := &MatrixResult{
+		Scenarios: make([]string, len(scenarios)),
+		Configs:   make([]string, len(configs)),
+		Cells:     make([][]MatrixCell, len(scenarios)),
+	}
+	for j, config := range configs {
+		matrix.Configs[j] = config.Name
+	}
+
+	for i, sc := range scenarios {
+		s, ok := sc.(*scenario)
+		if !ok {
+			return nil, errors.New("matrix runs require scenarios created with NewScenario")
+		}
+		matrix.Scenarios[i] = s.scenarioID()
+
+		seed := rand.Int64()
+		if s.seed != nil {
+			seed = *s.seed
+		}
+		matrix.Cells[i] = make([]MatrixCell, len(configs))
+		for j, config := range configs {
+			cell := s.withOptions(append(config.Options, WithSeed(seed))...)
+			result, err := cell.Run(ctx)
+			if err != nil {
+				matrix.Cells[i][j] = MatrixCell{Err: err}
+				continue
+			}
+			matrix.Cells[i][j] = MatrixCell{Result: result}
+		}
+	}
+
+	return matrix, nil
+}
+
+// PassRates returns the ratio of successful runs of every agent configuration, runs failing
+// with an error counting as unsuccessful.
+func (m *MatrixResult) PassRates() map[string]float64 {
+	rates := make(map[string]float64, len(m.Configs))
+	for j, config := range m.Configs {
+		passed := 0
+		for i := range m.Scenarios {
+			if result := m.Cells[i][j].Result; result != nil && result.Success {
+				passed++
+			}
+		}
+		if len(m.Scenarios) > 0 {
+			rates[config] = float64(passed) / float64(len(m.Scenarios))
+		}
+	}
+	return rates
+}
+
+// WriteGrid writes the comparison grid as an aligned text table, one row per scenario and one
+// column per agent configuration, with a final row of pass rates.
+func (m *MatrixResult) WriteGrid(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "scenario\t%s\n", strings.Join(m.Configs, "\t"))
+	for i, scenarioID := range m.Scenarios {
+		cells := make([]string, len(m.Configs))
+		for j := range m.Configs {
+			switch cell := m.Cells[i][j]; {
+			case cell.Err != nil:
+				cells[j] = "error"
+			case cell.Result.Success:
+				cells[j] = "pass"
+			default:
+				cells[j] = "fail"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", scenarioID, strings.Join(cells, "\t"))
+	}
+
+	rates := m.PassRates()
+	cells := make([]string, len(m.Configs))
+	for j, config := range m.Configs {
+		cells[j] = fmt.Sprintf("%.0f%%", rates[config]*100)
+	}
+	fmt.Fprintf(tw, "pass rate\t%s\n", strings.Join(cells, "\t"))
+
+	return tw.Flush()
+}
+
+// withOptions returns a copy of the scenario with the options applied, to run it in another
+// configuration.
+func (s *scenario) withOptions(opts ...ScenarioOption) *scenario {
+	clone := s.clone()
+	for _, opt := range opts {
+		opt(clone)
+	}
+	return clone
+}
@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMatrix(t *testing.T) {
+	newTestingAgent := func(success bool) TestingAgent {
+		return &mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				if firstMessage {
+					msg := "User message"
+					return &msg, nil, nil
+				}
+				return nil, &Result{Success: success}, nil
+			},
+		}
+	}
+	configs := []AgentConfig{
+		{Name: "v1", Options: []ScenarioOption{WithAgent(&mockAgent{})}},
+		{Name: "v2", Options: []ScenarioOption{WithAgent(&mockAgent{})}},
+		{Name: "v3", Options: []ScenarioOption{WithAgent(&mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return nil, errors.New("agent down")
+		}})}},
+	}
+	scenarios := []Scenario{
+		NewScenario(WithID("passing"), WithTestingAgent(newTestingAgent(true))),
+		NewScenario(WithID("failing"), WithTestingAgent(newTestingAgent(false)), WithSeed(42)),
+	}
+
+	matrix, err := RunMatrix(context.Background(), scenarios, configs)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"passing", "failing"}, matrix.Scenarios)
+	assert.Equal(t, []string{"v1", "v2", "v3"}, matrix.Configs)
+	require.NotNil(t, matrix.Cells[0][0].Result)
+	assert.True(t, matrix.Cells[0][0].Result.Success)
+	assert.Equal(t, matrix.Cells[0][0].Result.Seed, matrix.Cells[0][1].Result.Seed, "configurations share the seed")
+	assert.ErrorContains(t, matrix.Cells[0][2].Err, "agent down")
+	assert.Equal(t, int64(42), matrix.Cells[1][0].Result.Seed)
+	assert.Equal(t, map[string]float64{"v1": 0.5, "v2": 0.5, "v3": 0}, matrix.PassRates())
+
+	var buf bytes.Buffer
+	require.NoError(t, matrix.WriteGrid(&buf))
+	assert.Equal(t, "scenario   v1    v2    v3\npassing    pass  pass  error\nfailing    fail  fail  error\npass rate  50%   50%   0%\n", buf.String())
+}
+
+func TestRunMatrix_RequiresNewScenario(t *testing.T) {
+	_, err := RunMatrix(context.Background(), []Scenario{struct{ Scenario }{}}, nil)
+
+	assert.ErrorContains(t, err, "created with NewScenario")
+}
+
+func TestRunMatrix_CellsDoNotShareOptions(t *testing.T) {
+	free, pro := &mockFixtureLoader{}, &mockFixtureLoader{}
+	configs := []AgentConfig{
+		{Name: "free", Options: []ScenarioOption{WithAgent(free), WithFixture("plan", "free"), WithEventAt(0, Message{Role: MessageRoleUser, Content: "free trial started"})}},
+		{Name: "pro", Options: []ScenarioOption{WithAgent(pro), WithFixture("plan", "pro")}},
+	}
+	base := NewScenario(WithTestingAgent(&mockTestingAgent{}), WithFixture("account", "jane"), WithMaxTurns(1))
+
+	matrix, err := RunMatrix(context.Background(), []Scenario{base}, configs)
+
+	require.NoError(t, err)
+	require.NoError(t, matrix.Cells[0][0].Err)
+	require.NoError(t, matrix.Cells[0][1].Err)
+	assert.Equal(t, map[string]any{"account": "jane", "plan": "free"}, free.fixtures)
+	assert.Equal(t, map[string]any{"account": "jane", "plan": "pro"}, pro.fixtures)
+	assert.Equal(t, "free trial started", matrix.Cells[0][0].Result.Conversation[0].Content)
+	assert.NotEqual(t, "free trial started", matrix.Cells[0][1].Result.Conversation[0].Content, "events of a cell are not delivered to its siblings")
+	assert.Equal(t, map[string]any{"account": "jane"}, base.(*scenario).fixtures, "the base scenario is not modified")
+	assert.Empty(t, base.(*scenario).events)
+}
@@ -0,0 +1,220 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PersonaMemory is a past conversation a simulated user remembers, see WithPersonaMemory.
+type PersonaMemory struct {
+	// ScenarioID is the identifier of the scenario of the conversation.
+	ScenarioID string `json:"scenario_id"`
+
+	// RunID is the identifier of the run of the conversation.
+	RunID string `json:"run_id"`
+
+	// Time is when the conversation ended.
+	Time time.Time `json:"time"`
+
+	// Conversation is the conversation.
+	Conversation []Message `json:"conversation"`
+}
+
+// PersonaMemoryStore persists the memories of simulated users across scenarios, e.g. the same
+// customer interacting with different features of the agent in the scenarios of a suite, see
+// WithPersonaMemory. Implementations must be safe for concurrent use.
+type PersonaMemoryStore interface {
+	// Recall returns the memories of the user, oldest first.
+	Recall(user string) ([]PersonaMemory, error)
+
+	// Remember adds a memory to the user.
+	Remember(user string, memory PersonaMemory) error
+}
+
+// inMemoryPersonaMemoryStore is a PersonaMemoryStore keeping the memories in memory.
+type inMemoryPersonaMemoryStore struct {
+	mu       sync.Mutex
+	memories map[string][]PersonaMemory
+}
+
+// NewInMemoryPersonaMemoryStore creates a persona memory store keeping the memories in memory,
+// for the lifetime of the process.
+func NewInMemoryPersonaMemoryStore() PersonaMemoryStore {
+	return &inMemoryPersonaMemoryStore{memories: map[string][]PersonaMemory{}}
+}
+
+func (m *inMemoryPersonaMemoryStore) Recall(user string) ([]PersonaMemory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.memories[user]), nil
+}
+
+func (m *inMemoryPersonaMemoryStore) Remember(user string, memory PersonaMemory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.memories[user] = append(m.memories[user], memory)
+	return nil
+}
+
+// blobPersonaMemoryStore is a PersonaMemoryStore storing the memories as JSON blobs in a blob
+// store.
+type blobPersonaMemoryStore struct {
+	store BlobStore
+}
+
+// NewBlobPersonaMemoryStore creates a persona memory store storing the memories as JSON blobs
+// in the store, at <user>/<run id>.json, persisting them across processes. The user is escaped
+// to a single path segment, e.g. "a/b" is stored at a%2Fb.
+func NewBlobPersonaMemoryStore(store BlobStore) PersonaMemoryStore {
+	return &blobPersonaMemoryStore{store: store}
+}
+
+// NewDirPersonaMemoryStore creates a persona memory store storing the memories as JSON files
+// laid out as dir/<user>/<run id>.json, persisting them across processes. The user is escaped
+// to a single path segment, so names such as "../etc" stay within dir.
+func NewDirPersonaMemoryStore(dir string) PersonaMemoryStore {
+	return NewBlobPersonaMemoryStore(NewDirBlobStore(dir))
+}
+
+// userPrefix returns the prefix of the keys of the memories of the user, escaped to a single
+// path segment.
+func (b *blobPersonaMemoryStore) userPrefix(user string) (string, error) {
+	prefix := url.PathEscape(user)
+	if prefix == "" || prefix == "." || prefix == ".." {
+		return "", fmt.Errorf("invalid persona memory user %q", user)
+	}
+	return prefix, nil
+}
+
+func (b *blobPersonaMemoryStore) Recall(user string) ([]PersonaMemory, error) {
+	prefix, err := b.userPrefix(user)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := b.store.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	var memories []PersonaMemory
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".json") {
+			continue
+		}
+		data, err := b.store.Get(key)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var memory PersonaMemory
+		if err := json.Unmarshal(data, &memory); err != nil {
+			return nil, fmt.Errorf("failed to decode persona memory %s: %w", key, err)
+		}
+		memories = append(memories, memory)
+	}
+	slices.SortStableFunc(memories, func(a, b PersonaMemory) int {
+		return a.Time.Compare(b.Time)
+	})
+	return memories, nil
+}
+
+func (b *blobPersonaMemoryStore) Remember(user string, memory PersonaMemory) error {
+	data, err := json.Marshal(memory)
+	if err != nil {
+		return err
+	}
+	prefix, err := b.userPrefix(user)
+	if err != nil {
+		return err
+	}
+	return b.store.Put(path.Join(prefix, url.PathEscape(memory.RunID)+".json"), data)
+}
+
+// memoryUser returns the user the memories of the run are stored for: the user set with
+// WithPersonaMemory, or the name of the sampled persona.
+func (s *scenario) memoryUser() (string, error) {
+	if s.memoryUserName != "" {
+		return s.memoryUserName, nil
+	}
+	if s.persona != nil && s.persona.Name != "" {
+		return s.persona.Name, nil
+	}
+	return "", errors.New("persona memory set without a user nor a named persona")
+}
+
+// recallMemories loads the memories of the simulated user of the run.
+func (s *scenario) recallMemories() error {
+	s.memories = nil
+	if s.memoryStore == nil {
+		return nil
+	}
+
+	user, err := s.memoryUser()
+	if err != nil {
+		return err
+	}
+	memories, err := s.memoryStore.Recall(user)
+	if err != nil {
+		return fmt.Errorf("failed to recall the memories of %s: %w", user, err)
+	}
+	s.memories = memories
+
+	return nil
+}
+
+// rememberRun stores the conversation of the run as a memory of the simulated user. A failure
+// is recorded as a diagnostic of the result.
+func (s *scenario) rememberRun(ctx context.Context, result *Result) {
+	if s.memoryStore == nil || result.Cached || len(result.Conversation) == 0 {
+		return
+	}
+
+	user, err := s.memoryUser()
+	if err == nil {
+		err = s.memoryStore.Remember(user, PersonaMemory{
+			ScenarioID:   result.ScenarioID,
+			RunID:        result.RunID,
+			Time:         time.Now(),
+			Conversation: result.Conversation,
+		})
+	}
+	if err != nil {
+		s.recordResultDiagnostic(ctx, result, Diagnostic{
+			Code:    DiagnosticMemoryStoreFailed,
+			Message: err.Error(),
+			Turn:    len(s.turns),
+		})
+	}
+}
+
+// memoryInstructions returns the past conversations of the simulated user for the testing
+// agent.
+func (s *scenario) memoryInstructions() string {
+	var b strings.Builder
+	b.WriteString("<memory>\n")
+	for i, memory := range s.memories {
+		fmt.Fprintf(&b, "Conversation %d (%s):\n", i+1, memory.ScenarioID)
+		for _, message := range memory.Conversation {
+			if message.Content == "" || (message.Role != MessageRoleUser && message.Role != MessageRoleAssistant) {
+				continue
+			}
+			fmt.Fprintf(&b, "%s: %s\n", message.Role, message.Content)
+		}
+	}
+	b.WriteString("</memory>\n")
+	b.WriteString("These are your past conversations with the agent, you are the same user. Stay consistent with them, e.g. with the details you gave, and refer to them when relevant.")
+
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_PersonaMemory(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryPersonaMemoryStore()
+
+	var strategies []string
+	testingAgent := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			strategies = append(strategies, strategy)
+			if firstMessage {
+				msg := "my order 42 is late"
+				return &msg, nil, nil
+			}
+			return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+		},
+	}
+
+	first, err := NewScenario(
+		WithID("late-order"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(testingAgent),
+		WithPersonaMemory(store, "alice"),
+	).Run(ctx)
+	require.NoError(t, err)
+	assert.NotContains(t, strategies[0], "<memory>")
+
+	strategies = nil
+	_, err = NewScenario(
+		WithID("refund"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(testingAgent),
+		WithPersonaMemory(store, "alice"),
+	).Run(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, strategies[0], "<memory>\nConversation 1 (late-order):\nuser: my order 42 is late\nassistant: Agent response to: my order 42 is late\n</memory>")
+
+	memories, err := store.Recall("alice")
+	require.NoError(t, err)
+	require.Len(t, memories, 2)
+	assert.Equal(t, first.RunID, memories[0].RunID)
+	assert.Equal(t, "refund", memories[1].ScenarioID)
+
+	memories, err = store.Recall("bob")
+	require.NoError(t, err)
+	assert.Empty(t, memories)
+}
+
+func TestScenario_Run_PersonaMemoryUser(t *testing.T) {
+	ctx := context.Background()
+	store := NewInMemoryPersonaMemoryStore()
+
+	_, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithPersonaMemory(store, ""),
+	).Run(ctx)
+	require.Error(t, err)
+
+	_, err = NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithPersonaPool([]Persona{{Name: "retired-novice"}}, SamplingUniform),
+		WithPersonaMemory(store, ""),
+	).Run(ctx)
+	require.NoError(t, err)
+
+	memories, err := store.Recall("retired-novice")
+	require.NoError(t, err)
+	assert.Len(t, memories, 1)
+}
+
+type failingPersonaMemoryStore struct {
+	PersonaMemoryStore
+}
+
+func (failingPersonaMemoryStore) Remember(user string, memory PersonaMemory) error {
+	return errors.New("disk full")
+}
+
+func TestScenario_Run_PersonaMemoryStoreFailure(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithPersonaMemory(failingPersonaMemoryStore{NewInMemoryPersonaMemoryStore()}, "alice"),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, DiagnosticMemoryStoreFailed, result.Diagnostics[0].Code)
+	assert.Equal(t, "disk full", result.Diagnostics[0].Message)
+}
+
+func TestDirPersonaMemoryStore(t *testing.T) {
+	store := NewDirPersonaMemoryStore(t.TempDir())
+	start := time.Now()
+
+	memories, err := store.Recall("alice")
+	require.NoError(t, err)
+	assert.Empty(t, memories)
+
+	require.NoError(t, store.Remember("alice", PersonaMemory{
+		ScenarioID:   "refund",
+		RunID:        "b",
+		Time:         start.Add(time.Minute),
+		Conversation: []Message{{Role: MessageRoleUser, Content: "refund please"}},
+	}))
+	require.NoError(t, store.Remember("alice", PersonaMemory{
+		ScenarioID:   "late-order",
+		RunID:        "c",
+		Time:         start,
+		Conversation: []Message{{Role: MessageRoleUser, Content: "my order is late"}},
+	}))
+
+	memories, err = store.Recall("alice")
+	require.NoError(t, err)
+	require.Len(t, memories, 2)
+	assert.Equal(t, "late-order", memories[0].ScenarioID)
+	assert.Equal(t, "refund please", memories[1].Conversation[0].Content)
+}
+
+func TestDirPersonaMemoryStore_UserEscaped(t *testing.T) {
+	dir := t.TempDir()
+	store := NewDirPersonaMemoryStore(filepath.Join(dir, "memories"))
+
+	require.NoError(t, store.Remember("../../etc", PersonaMemory{ScenarioID: "refund", RunID: "r1"}))
+	require.NoError(t, store.Remember("team/alice", PersonaMemory{ScenarioID: "refund", RunID: "r2"}))
+
+	_, err := os.Stat(filepath.Join(dir, "memories", "..%2F..%2Fetc", "r1.json"))
+	assert.NoError(t, err, "the user is escaped within the store directory")
+	_, err = os.Stat(filepath.Join(dir, "memories", "team%2Falice", "r2.json"))
+	assert.NoError(t, err, "the user is a single directory")
+	memories, err := store.Recall("../../etc")
+	require.NoError(t, err)
+	require.Len(t, memories, 1)
+	assert.Equal(t, "r1", memories[0].RunID)
+	memories, err = store.Recall("team")
+	require.NoError(t, err)
+	assert.Empty(t, memories)
+
+	assert.ErrorContains(t, store.Remember("..", PersonaMemory{RunID: "r3"}), `invalid persona memory user ".."`)
+	_, err = store.Recall("")
+	assert.ErrorContains(t, err, "invalid persona memory user")
+}
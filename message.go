@@ -15,6 +15,9 @@ const (
 
 	// MessageRoleDeveloper is the role of a developer.
 	MessageRoleDeveloper MessageRole = "developer"
+
+	// MessageRoleTool is the role of the result of a tool call.
+	MessageRoleTool MessageRole = "tool"
 )
 
 // ToolType is the type of a tool.
@@ -28,47 +31,53 @@ const (
 // Message is a message in a conversation.
 type Message struct {
 	// Role is the role of the message.
-	Role MessageRole
+	Role MessageRole `json:"role"`
 
 	// Content is the content of the message.
-	Content string
+	Content string `json:"content"`
 
 	// Tools contains the tools available to the message.
-	Tools []Tool
+	Tools []Tool `json:"tools,omitempty"`
+
+	// ToolCalls contains the tool calls made in the message.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID is the ID of the tool call a tool message is the result of.
+	ToolCallID string `json:"tool_call_id,omitempty"`
 }
 
 // Tool represents a tool that can be used in a message.
 type Tool struct {
 	// Type is the type of the tool.
-	Type ToolType
+	Type ToolType `json:"type"`
 
 	// Function defines the function to call.
-	Function *ToolFunction
+	Function *ToolFunction `json:"function,omitempty"`
 }
 
 // ToolFunction represents the function definition of a tool.
 type ToolFunction struct {
 	// Name is the name of the function.
-	Name string
+	Name string `json:"name"`
 
 	// Description is the description of the function.
-	Description string
+	Description string `json:"description,omitempty"`
 
 	// Strict is whether the function is strict.
-	Strict bool
+	Strict bool `json:"strict,omitempty"`
 
 	// Parameters is the parameters of the function.
-	Parameters map[string]any
+	Parameters map[string]any `json:"parameters,omitempty"`
 }
 
 // ToolCall is a tool call in a message.
 type ToolCall struct {
-	ID       string
-	Type     ToolType
-	Function *ToolCallFunction
+	ID       string            `json:"id"`
+	Type     ToolType          `json:"type"`
+	Function *ToolCallFunction `json:"function,omitempty"`
 }
 
 type ToolCallFunction struct {
-	Name      string
-	Arguments map[string]any
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
 }
@@ -0,0 +1,216 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// TurnStats are the statistics of a turn of the conversation, recorded by the runner.
+type TurnStats struct {
+	// Turn is the zero-based index of the turn.
+	Turn int `json:"turn"`
+
+	// AgentDuration is the time the agent took to respond.
+	AgentDuration time.Duration `json:"agent_duration_ns"`
+
+	// TimeToFirstToken is the time until the first token of the response of a StreamingAgent,
+	// 0 for agents not streaming.
+	TimeToFirstToken time.Duration `json:"time_to_first_token_ns,omitempty"`
+
+	// TokensPerSecond is the generation throughput of the response of a StreamingAgent,
+	// estimated from the length of the content streamed after the first chunk between the
+	// first and the last token, 0 when not measurable: for agents not streaming and responses
+	// streamed in a single chunk.
+	TokensPerSecond float64 `json:"tokens_per_second,omitempty"`
+
+	// ResponseLength is the number of characters of the messages the agent responded with.
+	ResponseLength int `json:"response_length"`
+
+	// ResponseMessages is the number of messages the agent responded with.
+	ResponseMessages int `json:"response_messages"`
+
+	// EmotionalState is the emotional state of the simulated user at the turn, empty without
+	// WithEmotionalArc.
+	EmotionalState EmotionalState `json:"emotional_state,omitempty"`
+}
+
+// newTurnStats computes the statistics of a turn from the messages the agent responded with.
+func newTurnStats(turn int, agentDuration time.Duration, messages []Message) TurnStats {
+	stats := TurnStats{
+		Turn:             turn,
+		AgentDuration:    agentDuration,
+		ResponseMessages: len(messages),
+	}
+	for _, message := range messages {
+		stats.ResponseLength += utf8.RuneCountInString(message.Content)
+	}
+	return stats
+}
+
+// MetricCriterion is a programmatic criterion over the statistics of the turns of a run.
+type MetricCriterion interface {
+	// Check reports whether the statistics of the turns satisfy the criterion.
+	Check(turns []TurnStats) bool
+
+	// String describes the criterion, it is used as the criterion in results.
+	String() string
+}
+
+// metricCriterionFunc is a MetricCriterion backed by a function.
+type metricCriterionFunc struct {
+	description string
+	check       func(turns []TurnStats) bool
+}
+
+func (c metricCriterionFunc) Check(turns []TurnStats) bool {
+	return c.check(turns)
+}
+
+func (c metricCriterionFunc) String() string {
+	return c.description
+}
+
+// Metric is a per-turn metric, use one of its methods to turn its trend or a threshold into a
+// MetricCriterion. Turns where the metric is not measured, e.g. TimeToFirstToken for agents not
+// streaming, are skipped. Trends need at least two measured turns and thresholds one, criteria
+// are unmet otherwise.
+type Metric struct {
+	name  string
+	value func(TurnStats) float64
+
+	// measured reports whether the metric is measured at the turn, every turn when nil
+	measured func(TurnStats) bool
+}
+
+// ResponseLength is the number of characters the agent responds with per turn.
+func ResponseLength() Metric {
+	return Metric{name: "agent response length", value: func(s TurnStats) float64 {
+		return float64(s.ResponseLength)
+	}}
+}
+
+// AgentLatency is the time the agent takes to respond per turn.
+func AgentLatency() Metric {
+	return Metric{name: "agent latency", value: func(s TurnStats) float64 {
+		return float64(s.AgentDuration)
+	}}
+}
+
+// TimeToFirstToken is the time until the first token of the response of a StreamingAgent per
+// turn.
+func TimeToFirstToken() Metric {
+	return Metric{name: "time to first token", value: func(s TurnStats) float64 {
+		return float64(s.TimeToFirstToken)
+	}, measured: func(s TurnStats) bool {
+		return s.TimeToFirstToken > 0
+	}}
+}
+
+// TokensPerSecond is the generation throughput of the response of a StreamingAgent per turn,
+// turns without a measurable throughput being skipped, see TurnStats.TokensPerSecond.
+func TokensPerSecond() Metric {
+	return Metric{name: "tokens per second", value: func(s TurnStats) float64 {
+		return s.TokensPerSecond
+	}, measured: func(s TurnStats) bool {
+		return s.TokensPerSecond > 0
+	}}
+}
+
+// AtLeast is met when the metric is at least min at every turn, e.g. a throughput SLO with
+// TokensPerSecond. Unlike trends, it is checked from the first turn.
+func (m Metric) AtLeast(min float64) MetricCriterion {
+	return m.threshold(fmt.Sprintf("%s is at least %g at every turn", m.name, min), func(value float64) bool {
+		return value >= min
+	})
+}
+
+// AtMost is met when the metric is at most max at every turn, e.g. a latency SLO with
+// AgentLatency in nanoseconds. Unlike trends, it is checked from the first turn.
+func (m Metric) AtMost(max float64) MetricCriterion {
+	return m.threshold(fmt.Sprintf("%s is at most %g at every turn", m.name, max), func(value float64) bool {
+		return value <= max
+	})
+}
+
+// Decreasing is met when the metric decreases over time, the slope of its linear regression
+// over the turns being negative. Individual turns may still go up.
+func (m Metric) Decreasing() MetricCriterion {
+	return m.criterion(fmt.Sprintf("%s decreases over time", m.name), func(values []float64) bool {
+		return slope(values) < 0
+	})
+}
+
+// Increasing is met when the metric increases over time, the slope of its linear regression
+// over the turns being positive. Individual turns may still go down.
+func (m Metric) Increasing() MetricCriterion {
+	return m.criterion(fmt.Sprintf("%s increases over time", m.name), func(values []float64) bool {
+		return slope(values) > 0
+	})
+}
+
+// NotMonotonicallyIncreasing is met when the metric does not grow at every turn.
+func (m Metric) NotMonotonicallyIncreasing() MetricCriterion {
+	return m.criterion(fmt.Sprintf("%s does not grow monotonically", m.name), func(values []float64) bool {
+		for i := 1; i < len(values); i++ {
+			if values[i] <= values[i-1] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (m Metric) criterion(description string, check func(values []float64) bool) MetricCriterion {
+	return metricCriterionFunc{
+		description: description,
+		check: func(turns []TurnStats) bool {
+			values := m.values(turns)
+			if len(values) < 2 {
+				return false
+			}
+			return check(values)
+		},
+	}
+}
+
+func (m Metric) threshold(description string, check func(value float64) bool) MetricCriterion {
+	return metricCriterionFunc{
+		description: description,
+		check: func(turns []TurnStats) bool {
+			values := m.values(turns)
+			for _, value := range values {
+				if !check(value) {
+					return false
+				}
+			}
+			return len(values) > 0
+		},
+	}
+}
+
+// values returns the values of the metric at the turns it is measured.
+func (m Metric) values(turns []TurnStats) []float64 {
+	values := make([]float64, 0, len(turns))
+	for _, turn := range turns {
+		if m.measured == nil || m.measured(turn) {
+			values = append(values, m.value(turn))
+		}
+	}
+	return values
+}
+
+// slope returns the slope of the least squares linear regression of the values over their
+// indexes.
+func slope(values []float64) float64 {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
@@ -0,0 +1,95 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricCriteria(t *testing.T) {
+	turns := func(lengths ...int) []TurnStats {
+		stats := make([]TurnStats, len(lengths))
+		for i, length := range lengths {
+			stats[i] = TurnStats{Turn: i, ResponseLength: length, AgentDuration: time.Duration(length) * time.Millisecond}
+		}
+		return stats
+	}
+	throughputs := func(rates ...float64) []TurnStats {
+		stats := make([]TurnStats, len(rates))
+		for i, rate := range rates {
+			stats[i] = TurnStats{Turn: i, TokensPerSecond: rate}
+		}
+		return stats
+	}
+
+	tests := []struct {
+		name        string
+		criterion   MetricCriterion
+		turns       []TurnStats
+		met         bool
+		description string
+	}{
+		{"Decreasing", ResponseLength().Decreasing(), turns(300, 320, 200, 100), true, "agent response length decreases over time"},
+		{"Decreasing flat", ResponseLength().Decreasing(), turns(100, 100, 100), false, "agent response length decreases over time"},
+		{"Increasing", AgentLatency().Increasing(), turns(100, 90, 200, 300), true, "agent latency increases over time"},
+		{"NotMonotonicallyIncreasing", AgentLatency().NotMonotonicallyIncreasing(), turns(100, 200, 150), true, "agent latency does not grow monotonically"},
+		{"NotMonotonicallyIncreasing growing", AgentLatency().NotMonotonicallyIncreasing(), turns(100, 200, 300), false, "agent latency does not grow monotonically"},
+		{"single turn", ResponseLength().Decreasing(), turns(100), false, "agent response length decreases over time"},
+		{"AtLeast", ResponseLength().AtLeast(100), turns(100, 150), true, "agent response length is at least 100 at every turn"},
+		{"AtLeast single turn", ResponseLength().AtLeast(100), turns(120), true, "agent response length is at least 100 at every turn"},
+		{"AtLeast below", ResponseLength().AtLeast(100), turns(150, 90), false, "agent response length is at least 100 at every turn"},
+		{"AtMost", AgentLatency().AtMost(float64(200 * time.Millisecond)), turns(100, 250), false, "agent latency is at most 2e+08 at every turn"},
+		{"AtLeast no turns", TokensPerSecond().AtLeast(20), turns(), false, "tokens per second is at least 20 at every turn"},
+		{"AtLeast unmeasured turns", TokensPerSecond().AtLeast(20), throughputs(0, 50, 0), true, "tokens per second is at least 20 at every turn"},
+		{"AtLeast unmeasured turns below", TokensPerSecond().AtLeast(20), throughputs(0, 50, 10), false, "tokens per second is at least 20 at every turn"},
+		{"AtLeast no measured turns", TokensPerSecond().AtLeast(20), throughputs(0, 0), false, "tokens per second is at least 20 at every turn"},
+		{"AtMost not streaming", TimeToFirstToken().AtMost(float64(time.Second)), turns(100, 200), false, "time to first token is at most 1e+09 at every turn"},
+		{"Decreasing unmeasured turns", TokensPerSecond().Decreasing(), throughputs(50, 0, 40), true, "tokens per second decreases over time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.met, tt.criterion.Check(tt.turns))
+			assert.Equal(t, tt.description, tt.criterion.String())
+		})
+	}
+}
+
+func TestScenario_Run_MetricCriteria(t *testing.T) {
+	ctx := context.Background()
+	turn := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			turn++
+			return []Message{{Role: MessageRoleAssistant, Content: strings.Repeat("a", 100*turn)}}, nil
+		},
+	}
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if lastMessage {
+				return nil, NewSuccessPartialResult(conversation, "Done", []string{}), nil
+			}
+			msg := "summarize"
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithMaxTurns(3),
+		WithMetricCriteria(ResponseLength().Decreasing(), ResponseLength().NotMonotonicallyIncreasing()),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Turns, 3)
+	assert.Equal(t, []int{100, 200, 300}, []int{result.Turns[0].ResponseLength, result.Turns[1].ResponseLength, result.Turns[2].ResponseLength})
+	assert.Equal(t, []string{"agent response length decreases over time", "agent response length does not grow monotonically"}, result.UnmetCriteria)
+}
@@ -0,0 +1,73 @@
+package scenario
+
+import "strings"
+
+// Normalizer transforms a conversation, see NormalizeConversation.
+type Normalizer func(conversation []Message) []Message
+
+// NormalizeConversation applies the normalizers to the conversation in order, e.g. to clean
+// up transcripts imported from external sources before judging them. The input conversation
+// is never modified.
+func NormalizeConversation(conversation []Message, normalizers ...Normalizer) []Message {
+	normalized := append([]Message{}, conversation...)
+	for _, normalize := range normalizers {
+		normalized = normalize(normalized)
+	}
+	return normalized
+}
+
+// StripSystemMessages removes the system and developer messages from the conversation.
+func StripSystemMessages(conversation []Message) []Message {
+	stripped := make([]Message, 0, len(conversation))
+	for _, message := range conversation {
+		if message.Role == MessageRoleSystem || message.Role == MessageRoleDeveloper {
+			continue
+		}
+		stripped = append(stripped, message)
+	}
+	return stripped
+}
+
+// MergeConsecutiveAssistantMessages merges runs of assistant messages into a single message,
+// joining their contents with blank lines and keeping all of their tool calls.
+func MergeConsecutiveAssistantMessages(conversation []Message) []Message {
+	merged := make([]Message, 0, len(conversation))
+	for _, message := range conversation {
+		last := len(merged) - 1
+		if last < 0 || message.Role != MessageRoleAssistant || merged[last].Role != MessageRoleAssistant {
+			merged = append(merged, message)
+			continue
+		}
+
+		switch {
+		case merged[last].Content == "":
+			merged[last].Content = message.Content
+		case message.Content != "":
+			merged[last].Content += "\n\n" + message.Content
+		}
+		merged[last].ToolCalls = append(merged[last].ToolCalls[:len(merged[last].ToolCalls):len(merged[last].ToolCalls)], message.ToolCalls...)
+	}
+	return merged
+}
+
+// TrimWhitespace trims the leading and trailing whitespace of the content of every message.
+func TrimWhitespace(conversation []Message) []Message {
+	trimmed := make([]Message, len(conversation))
+	for i, message := range conversation {
+		message.Content = strings.TrimSpace(message.Content)
+		trimmed[i] = message
+	}
+	return trimmed
+}
+
+// DropEmptyMessages removes the messages without content nor tool calls.
+func DropEmptyMessages(conversation []Message) []Message {
+	kept := make([]Message, 0, len(conversation))
+	for _, message := range conversation {
+		if strings.TrimSpace(message.Content) == "" && len(message.ToolCalls) == 0 {
+			continue
+		}
+		kept = append(kept, message)
+	}
+	return kept
+}
@@ -0,0 +1,41 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeConversation(t *testing.T) {
+	toolCall := ToolCall{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "lookup_order"}}
+	conversation := []Message{
+		{Role: MessageRoleSystem, Content: "you are a support agent"},
+		{Role: MessageRoleUser, Content: "  where is my order?\n"},
+		{Role: MessageRoleAssistant, Content: "", ToolCalls: []ToolCall{toolCall}},
+		{Role: MessageRoleAssistant, Content: " let me check "},
+		{Role: MessageRoleAssistant, Content: "it ships tomorrow"},
+		{Role: MessageRoleUser, Content: "   "},
+	}
+
+	normalized := NormalizeConversation(conversation,
+		StripSystemMessages,
+		TrimWhitespace,
+		DropEmptyMessages,
+		MergeConsecutiveAssistantMessages,
+	)
+
+	assert.Equal(t, []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, Content: "let me check\n\nit ships tomorrow", ToolCalls: []ToolCall{toolCall}},
+	}, normalized)
+	assert.Equal(t, "  where is my order?\n", conversation[1].Content, "the input conversation is not modified")
+	assert.Equal(t, conversation, NormalizeConversation(conversation))
+}
+
+func TestStripSystemMessages(t *testing.T) {
+	conversation := []Message{
+		{Role: MessageRoleDeveloper, Content: "be brief"},
+		{Role: MessageRoleUser, Content: "hi"},
+	}
+	assert.Equal(t, []Message{{Role: MessageRoleUser, Content: "hi"}}, StripSystemMessages(conversation))
+}
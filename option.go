@@ -1,8 +1,29 @@
 package scenario
 
+import (
+	"context"
+	"maps"
+	"regexp"
+	"slices"
+	"time"
+)
+
 type ScenarioOption func(*scenario)
 
-// WithDescription sets the scenario's description.
+// WithID sets the identifier of the scenario, recorded on its results and used by exports,
+// persona memories and the result cache to match runs of the same scenario. IDs can be
+// namespaced with slashes, e.g. "billing/refund-happy-path". When not set, an ID is generated
+// from the description, which changes whenever the description is edited, so runs from before
+// and after an edit no longer match: set an ID on every scenario whose runs are compared over
+// time.
+func WithID(id string) ScenarioOption {
+	return func(s *scenario) {
+		s.id = id
+	}
+}
+
+// WithDescription sets the scenario's description. The description, strategy and criteria can
+// use Go template placeholders resolved at every run, see TemplateData.
 func WithDescription(description string) ScenarioOption {
 	return func(s *scenario) {
 		s.description = description
@@ -41,6 +62,30 @@ func WithTestingAgent(testingAgent TestingAgent) ScenarioOption {
 func WithSuccessCriteria(criteria ...string) ScenarioOption {
 	return func(s *scenario) {
 		s.successCriteria = criteria
+		s.successWeights = nil
+	}
+}
+
+// WithWeightedSuccessCriteria sets the scenario's success criteria with their weights in the
+// weighted score of the result, see Result.Score. Pass the scenario on a weighted score with
+// WithPassingScore rather than requiring all criteria.
+func WithWeightedSuccessCriteria(criteria map[string]float64) ScenarioOption {
+	return func(s *scenario) {
+		s.successCriteria = slices.Sorted(maps.Keys(criteria))
+		s.successWeights = make([]float64, len(s.successCriteria))
+		for i, criterion := range s.successCriteria {
+			s.successWeights[i] = criteria[criterion]
+		}
+	}
+}
+
+// WithPassingScore passes the scenario when the weighted score of the success criteria met is
+// at least the score, from 0 to 1, and no failure criteria were triggered, rather than
+// requiring all success criteria, e.g. 0.8 to pass at 80%. Success assertions, format
+// validators and metric criteria are still required.
+func WithPassingScore(score float64) ScenarioOption {
+	return func(s *scenario) {
+		s.passingScore = &score
 	}
 }
 
@@ -50,3 +95,446 @@ func WithFailureCriteria(criteria ...string) ScenarioOption {
 		s.failureCriteria = criteria
 	}
 }
+
+// WithEventAt injects an out-of-band event (e.g. "payment webhook arrived") into the
+// conversation before the user message of the given zero-based turn. The event is
+// delivered to agents implementing EventAgent and is visible to the testing agent.
+func WithEventAt(turn int, event Message) ScenarioOption {
+	return func(s *scenario) {
+		if s.events == nil {
+			s.events = map[int][]Message{}
+		}
+		s.events[turn] = append(s.events[turn], event)
+	}
+}
+
+// WithCriteriaLeakGuard regenerates the messages of the simulated user quoting the scenario
+// description or the criteria verbatim, which would trivially help the agent pass. The run fails
+// if the message still leaks after maxRegenerations attempts.
+func WithCriteriaLeakGuard(maxRegenerations int) ScenarioOption {
+	return func(s *scenario) {
+		s.leakRegenerations = &maxRegenerations
+	}
+}
+
+// WithRetryPolicy retries the calls of the agent and of the testing agent failing with
+// transient errors, e.g. a rate limit of the LLM provider, instead of failing the scenario.
+// Agents keeping state across turns must be able to run the same message again after an
+// error.
+func WithRetryPolicy(policy RetryPolicy) ScenarioOption {
+	return func(s *scenario) {
+		s.retryPolicy = policy
+	}
+}
+
+// WithRunningSummary keeps long conversations coherent and within the context of the
+// simulated user: once the conversation exceeds the token budget, its oldest turns are folded
+// into a running summary generated by the summarizer, usually a cheap model, shown to the
+// simulated user in their place. The summary is updated every time the conversation exceeds
+// the budget again. Verdicts are still given on the whole conversation.
+func WithRunningSummary(summarizer LLMCompletion, tokenBudget int) ScenarioOption {
+	return func(s *scenario) {
+		s.summarizer = summarizer
+		s.summaryBudget = tokenBudget
+	}
+}
+
+// WithJudgeVotes samples the verdict of the testing agent n times and keeps the verdict of the
+// majority, as verdicts on flaky criteria vary from one call to the next. The first verdict of
+// the majority becomes the result, the others disagreeing being kept in
+// Result.DissentingVerdicts. A tie fails the scenario, use an odd n.
+func WithJudgeVotes(n int) ScenarioOption {
+	return func(s *scenario) {
+		s.judgeVotes = n
+	}
+}
+
+// WithSecondOpinion asks the judge for a second opinion when the testing agent gives a verdict
+// with a confidence below threshold. The verdict of the judge becomes the result, the low
+// confidence verdict being kept in Result.FirstOpinion.
+func WithSecondOpinion(judge TestingAgent, threshold float64) ScenarioOption {
+	return func(s *scenario) {
+		s.secondOpinion = judge
+		s.secondOpinionThreshold = threshold
+	}
+}
+
+// WithCanaryJudge asks the canary judge for its verdict on the conversation judged by the
+// testing agent, without affecting the result, e.g. to evaluate a cheaper judge model on live
+// suites before switching to it. The verdict of the canary is kept in Result.CanaryVerdict, a
+// disagreement being recorded as a diagnostic.
+func WithCanaryJudge(judge TestingAgent) ScenarioOption {
+	return func(s *scenario) {
+		s.canaryJudge = judge
+	}
+}
+
+// WithFixture declares state the agent is seeded with before the run, e.g. a user account or
+// an order history. The agent must implement FixtureLoader.
+func WithFixture(name string, value any) ScenarioOption {
+	return func(s *scenario) {
+		if s.fixtures == nil {
+			s.fixtures = map[string]any{}
+		}
+		s.fixtures[name] = value
+	}
+}
+
+// WithConversationSeed starts every run from a conversation sampled from the corpus with the
+// seed of the run, so repetitions start from different realistic contexts. The agent must
+// implement HistoryAgent.
+func WithConversationSeed(corpus *ConversationCorpus) ScenarioOption {
+	return func(s *scenario) {
+		s.conversationSeed = corpus
+	}
+}
+
+// WithInitialConversation starts every run mid-conversation, e.g. after a prior onboarding
+// exchange, to test follow-up behaviors without replaying the whole flow. The messages are
+// passed to the agent, which must implement HistoryAgent, and shown to the testing agent as
+// the start of the conversation.
+func WithInitialConversation(messages ...Message) ScenarioOption {
+	return func(s *scenario) {
+		s.initialConversation = messages
+	}
+}
+
+// WithEmotionalArc sets the emotional trajectory of the simulated user. The emotional state
+// of the current and previous turns is included in the strategy given to the testing agent,
+// both to simulate the user and to judge the conversation, and recorded in Result.Turns.
+func WithEmotionalArc(arc EmotionalArc) ScenarioOption {
+	return func(s *scenario) {
+		s.emotionalArc = &arc
+	}
+}
+
+// WithImpatience makes the simulated user push back, or abandon the conversation, once the
+// agent asked more clarifying questions than it tolerates. Questions are counted with a
+// heuristic: every sentence of the agent ending with a question mark is a question, so a message
+// asking two questions counts twice, while question marks in URLs and code blocks are ignored.
+// The testing agent is told when the user runs out of patience, so it is taken into account in
+// the verdict.
+func WithImpatience(impatience Impatience) ScenarioOption {
+	return func(s *scenario) {
+		s.impatience = &impatience
+	}
+}
+
+// WithAllowedTopics classifies every turn against the allowed topics of the guard and fails
+// the scenario, or warns, once the conversation drifts off them for consecutive turns, e.g. for
+// sales and support agents that must stay on topic, as the final verdict often forgives drift.
+func WithAllowedTopics(guard TopicGuard) ScenarioOption {
+	return func(s *scenario) {
+		s.topicGuard = &guard
+	}
+}
+
+// WithJudgeSampling only makes the per-turn judge calls, the topic guard of WithAllowedTopics
+// and the separate verdict of WithBlindSimulator, on the turns sampled by the policy, the final
+// turn being always judged, e.g. to bound the judging cost of long scenarios. Consecutive
+// off-topic turns are counted over the judged turns. The policy is recorded in
+// Result.JudgeSampling.
+func WithJudgeSampling(sampling JudgeSampling) ScenarioOption {
+	return func(s *scenario) {
+		s.judgeSampling = &sampling
+	}
+}
+
+// WithSeed sets the seed used for the randomized behaviors of the scenario, allowing a
+// previous run to be reproduced. When not set, a random seed is generated for each run.
+func WithSeed(seed int64) ScenarioOption {
+	return func(s *scenario) {
+		s.seed = &seed
+	}
+}
+
+// WithStopCondition ends the conversation once any of the matchers matches, asking the
+// testing agent for its final verdict.
+func WithStopCondition(matchers ...Matcher) ScenarioOption {
+	return func(s *scenario) {
+		s.stopConditions = matchers
+	}
+}
+
+// WithSuccessAssertions sets programmatic success criteria, checked against the final
+// conversation. Any unmet assertion fails the scenario.
+func WithSuccessAssertions(matchers ...Matcher) ScenarioOption {
+	return func(s *scenario) {
+		s.successAssertions = matchers
+	}
+}
+
+// WithFailureAssertions sets programmatic failure criteria, checked after every agent turn.
+// The scenario fails immediately once any of them matches.
+func WithFailureAssertions(matchers ...Matcher) ScenarioOption {
+	return func(s *scenario) {
+		s.failureAssertions = matchers
+	}
+}
+
+// WithMetricCriteria sets programmatic success criteria over the trends of per-turn
+// statistics, e.g. ResponseLength().Decreasing(), checked once the testing agent gives its
+// verdict. Any unmet criterion fails the scenario.
+func WithMetricCriteria(criteria ...MetricCriterion) ScenarioOption {
+	return func(s *scenario) {
+		s.metricCriteria = criteria
+	}
+}
+
+// WithFinalAnswerFormat checks the final answer of the agent with the validators once the
+// testing agent gives its verdict. Any failing validator fails the scenario, recording its
+// diagnostic in the unmet criteria.
+func WithFinalAnswerFormat(validators ...FormatValidator) ScenarioOption {
+	return func(s *scenario) {
+		s.formatValidators = validators
+	}
+}
+
+// WithAllowedTools restricts the tools the agent may call, as seen in the tool calls of its
+// messages. Calling any other tool immediately fails the scenario, without names the agent may
+// not call any tool.
+func WithAllowedTools(names ...string) ScenarioOption {
+	return func(s *scenario) {
+		s.allowedTools = append([]string{}, names...)
+	}
+}
+
+// WithForbiddenTools lists tools the agent must not call, e.g. "send_email" in a read-only
+// scenario. Calling any of them immediately fails the scenario.
+func WithForbiddenTools(names ...string) ScenarioOption {
+	return func(s *scenario) {
+		s.forbiddenTools = names
+	}
+}
+
+// WithJudgeProgress reports the progress of the calls of the testing agent to its LLM to fn,
+// e.g. to show activity during long verdicts. LLMCompletion adapters supporting it stream their
+// responses to report the tokens received so far.
+func WithJudgeProgress(fn func(JudgeProgress)) ScenarioOption {
+	return func(s *scenario) {
+		s.judgeProgress = fn
+	}
+}
+
+// WithWatcher evaluates the regular expression on every message as it is appended to the
+// conversation, e.g. to catch stack traces or "as an AI language model" leaking into responses,
+// and logs, tags or fails fast on a match. It panics if the pattern cannot be compiled.
+func WithWatcher(pattern string, action WatchAction) ScenarioOption {
+	re := regexp.MustCompile(pattern)
+	return func(s *scenario) {
+		s.watchers = append(s.watchers, watcher{pattern: re, action: action})
+	}
+}
+
+// WithHooks calls the hooks from the run loop, e.g. to log every turn to an observability
+// stack or to add per-turn assertions. The hooks of every WithHooks option are called in order.
+func WithHooks(hooks Hooks) ScenarioOption {
+	return func(s *scenario) {
+		s.hooks = append(s.hooks, hooks)
+	}
+}
+
+// WithCallObserver calls fn for every outbound call of the run as it is recorded in the audit
+// log, with the context of the call, e.g. to emit OpenTelemetry spans named
+// AuditEntry.GenAISpanName with the AuditEntry.GenAIAttributes of the call, starting at its
+// Time and lasting its Duration.
+func WithCallObserver(fn func(ctx context.Context, entry AuditEntry)) ScenarioOption {
+	return func(s *scenario) {
+		s.callObserver = fn
+	}
+}
+
+// WithLanguageCheck fails the scenario as soon as the agent replies in a different language
+// than the user, see LanguageMismatch.
+func WithLanguageCheck() ScenarioOption {
+	return func(s *scenario) {
+		s.languageCheck = true
+	}
+}
+
+// TestingAgentOption configures a testing agent created with NewTestingAgent.
+type TestingAgentOption func(*testingAgent)
+
+// WithVerdictSchema sets the schema of the tool the testing agent calls to give its final
+// verdict, see SchemaPresetOpenAIStrict and SchemaPresetLoose.
+func WithVerdictSchema(schema VerdictSchema) TestingAgentOption {
+	return func(t *testingAgent) {
+		t.verdictSchema = schema
+	}
+}
+
+// WithUserStyle sets the tone and register of the simulated user, which defaults to very short
+// lowercase inputs as typed in a search engine.
+func WithUserStyle(style UserStyle) TestingAgentOption {
+	return func(t *testingAgent) {
+		t.userStyle = style
+	}
+}
+
+// WithBlindSimulator hides the success and failure criteria from the prompt generating the
+// messages of the simulated user, so it cannot steer the agent straight toward them. The
+// conversation is judged with the criteria in a separate call before each message is
+// generated.
+func WithBlindSimulator() TestingAgentOption {
+	return func(t *testingAgent) {
+		t.blindSimulator = true
+	}
+}
+
+// WithUserSelfReport asks the simulated user to report with the verdict, from their own
+// perspective and regardless of the criteria, whether they got what they needed. The report
+// is in Result.UserSelfReport.
+func WithUserSelfReport() TestingAgentOption {
+	return func(t *testingAgent) {
+		t.selfReport = true
+	}
+}
+
+// WithJudgeStrictness sets how strictly the testing agent reads the criteria when judging,
+// JudgeStrictnessBalanced by default. The strictness is recorded in Result.JudgeStrictness so
+// verdicts of different strictness can be told apart.
+func WithJudgeStrictness(strictness JudgeStrictness) TestingAgentOption {
+	return func(t *testingAgent) {
+		t.strictness = strictness
+	}
+}
+
+// WithVerdictProtocol sets how the testing agent gives its final verdict. Use
+// VerdictProtocolText for judge models known to lack tool calling, others are detected from the
+// errors of the provider once per scenario run.
+func WithVerdictProtocol(protocol VerdictProtocol) TestingAgentOption {
+	return func(t *testingAgent) {
+		t.textVerdict = protocol == VerdictProtocolText
+	}
+}
+
+// WithUserKnowledge gives the simulated user facts it knows (order number, account email,
+// device model), which it only reveals when asked. The testing agent also checks that the
+// agent elicited them properly.
+func WithUserKnowledge(knowledge string) ScenarioOption {
+	return func(s *scenario) {
+		s.userKnowledge = knowledge
+	}
+}
+
+// WithExpectedRefusal turns the scenario into a negative scenario, where success means the
+// agent declines the request for the given reason, politely, explaining why and offering an
+// alternative.
+func WithExpectedRefusal(reason string) ScenarioOption {
+	return func(s *scenario) {
+		s.expectedRefusal = reason
+	}
+}
+
+// WithDeveloperMessage injects a developer message at the start of the conversation. It is
+// delivered to agents implementing EventAgent and is visible to the testing agent.
+func WithDeveloperMessage(content string) ScenarioOption {
+	return WithEventAt(0, Message{
+		Role:    MessageRoleDeveloper,
+		Content: content,
+	})
+}
+
+// WithAbortSignal aborts the run when the signal channel is closed or receives a value, as
+// AbortRun does. The run returns its partial result with Aborted set.
+func WithAbortSignal(signal <-chan struct{}) ScenarioOption {
+	return func(s *scenario) {
+		s.abortSignal = signal
+	}
+}
+
+// WithTruncation applies the truncation policies in order to the conversation shown to the
+// testing agent, e.g. KeepLastAgentMessages, CollapseToolCalls or TruncateLongMessages, so
+// noisy agent turns do not degrade the verdict. Result.Conversation keeps the full
+// conversation, the judged one is in Result.JudgedConversation.
+func WithTruncation(policies ...Normalizer) ScenarioOption {
+	return func(s *scenario) {
+		s.truncation = policies
+	}
+}
+
+// WithVerdictPostProcessor adds a post-processor applied to the result of every run before it
+// is returned, once the run metadata is filled in, e.g. to apply an organization-specific
+// policy such as ignoring a known flaky criterion. Post-processors are applied in the order
+// they are added, each receiving the result returned by the previous one.
+func WithVerdictPostProcessor(postProcess func(*Result) *Result) ScenarioOption {
+	return func(s *scenario) {
+		s.postProcessors = append(s.postProcessors, postProcess)
+	}
+}
+
+// WithResultCache skips running the scenario when the cache already holds a result for its
+// ID, the agent version and the seed, returning the cached result with Cached set. Completed
+// runs are stored in the cache, aborted and timed out runs are not. It is meant for
+// deterministic agents and a fixed seed set with WithSeed, bump the agent version whenever the
+// agent changes.
+func WithResultCache(cache ResultCache, agentVersion string) ScenarioOption {
+	return func(s *scenario) {
+		s.resultCache = cache
+		s.agentVersion = agentVersion
+	}
+}
+
+// WithPersonaMemory gives the simulated user the memory of its past conversations stored in
+// the store, and stores the conversation of every run, so the same user can interact with
+// different features of the agent across scenarios, e.g. to check the agent remembers a
+// previous ticket. The user identifies the simulated user in the store, it defaults to the
+// name of the persona sampled with WithPersonaPool.
+func WithPersonaMemory(store PersonaMemoryStore, user string) ScenarioOption {
+	return func(s *scenario) {
+		s.memoryStore = store
+		s.memoryUserName = user
+	}
+}
+
+// WithArtifactTextLimit sets how many bytes of each text artifact (plain text, HTML, JSON,
+// emails) of an ArtifactProducer agent are shown to the testing agent, so criteria can be
+// about them, e.g. "the confirmation email contains the refund amount". Longer artifacts are
+// truncated, 0 only references the artifacts. It defaults to 2000 bytes.
+func WithArtifactTextLimit(maxBytes int) ScenarioOption {
+	return func(s *scenario) {
+		s.artifactTextLimit = &maxBytes
+	}
+}
+
+// WithPersonaPool samples the persona of the simulated user of every run from the pool with
+// the sampling strategy, so repetitions cover diverse users. Random sampling is derived from
+// the seed of the run, making it reproducible with WithSeed. The sampled persona is in
+// Result.Persona.
+func WithPersonaPool(personas []Persona, sampling SamplingStrategy) ScenarioOption {
+	return func(s *scenario) {
+		s.personaPool = personas
+		s.personaSampling = sampling
+	}
+}
+
+// WithSoftDeadline sets a soft deadline to the run: once the deadline has elapsed since the
+// start of the run, no new turn is started and the testing agent is asked for its verdict on
+// the conversation so far, with Result.DeadlineReached set. The verdict must be generated
+// within the grace period, or the run fails with ErrTimeout. A zero grace period leaves the
+// verdict unbounded.
+func WithSoftDeadline(deadline, grace time.Duration) ScenarioOption {
+	return func(s *scenario) {
+		s.softDeadline = deadline
+		s.gracePeriod = grace
+	}
+}
+
+// WithHardTimeout sets a hard timeout to the run: once the timeout has elapsed since the start
+// of the run, the context of the agent and testing agent calls is cancelled and the run fails
+// with ErrTimeout, without a verdict. It can be combined with WithSoftDeadline.
+func WithHardTimeout(timeout time.Duration) ScenarioOption {
+	return func(s *scenario) {
+		s.hardTimeout = timeout
+	}
+}
+
+// WithPartialJudgment asks the testing agent for a best-effort verdict on the partial
+// conversation of a run aborted or timed out with ErrTimeout, within the timeout, and
+// attaches it to the result as Result.PartialVerdict. Timed out runs then return their partial
+// result along with ErrTimeout.
+func WithPartialJudgment(timeout time.Duration) ScenarioOption {
+	return func(s *scenario) {
+		s.partialJudgment = timeout
+	}
+}
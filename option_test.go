@@ -241,3 +241,34 @@ func TestNilOption(t *testing.T) {
 		}
 	})
 }
+
+func TestWithEventAt(t *testing.T) {
+	s := newTestScenario()
+	sc := s.(*scenario)
+	event := Message{Role: MessageRoleSystem, Content: "payment webhook arrived"}
+	WithEventAt(1, event)(sc)
+	WithEventAt(1, event)(sc)
+	assert.Equal(t, map[int][]Message{1: {event, event}}, sc.events)
+}
+
+func TestWithSeed(t *testing.T) {
+	s := newTestScenario()
+	sc := s.(*scenario)
+	WithSeed(42)(sc)
+	require.NotNil(t, sc.seed)
+	assert.Equal(t, int64(42), *sc.seed)
+}
+
+func TestWithID(t *testing.T) {
+	s := newTestScenario()
+	sc := s.(*scenario)
+	WithID("billing/refund")(sc)
+	assert.Equal(t, "billing/refund", sc.id)
+}
+
+func TestWithDeveloperMessage(t *testing.T) {
+	s := newTestScenario()
+	sc := s.(*scenario)
+	WithDeveloperMessage("respond in french")(sc)
+	assert.Equal(t, map[int][]Message{0: {{Role: MessageRoleDeveloper, Content: "respond in french"}}}, sc.events)
+}
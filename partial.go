@@ -0,0 +1,61 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// abortedRun returns the partial result of a run aborted at the turn.
+func (s *scenario) abortedRun(ctx context.Context, turn int) (*Result, error) {
+	result := abortedResult(s.conversation, turn)
+	s.judgePartial(ctx, result, turn)
+
+	return s.finishResult(ctx, result), nil
+}
+
+// timedOutRun returns the error of a run timed out at the turn, along with its partial result
+// when WithPartialJudgment is set.
+func (s *scenario) timedOutRun(ctx context.Context, turn int, err error) (*Result, error) {
+	if s.partialJudgment <= 0 {
+		return &Result{Success: false}, err
+	}
+
+	result := &Result{
+		Success:           false,
+		TimedOut:          true,
+		Conversation:      s.conversation,
+		Reasoning:         fmt.Sprintf("The run timed out at turn %d.", turn),
+		MetCriteria:       []string{},
+		UnmetCriteria:     []string{},
+		TriggeredFailures: []string{},
+	}
+	s.judgePartial(ctx, result, turn)
+
+	return s.finishResult(ctx, result), err
+}
+
+// judgePartial asks the testing agent for its verdict on the partial conversation of an
+// aborted or timed out run when WithPartialJudgment is set, ignoring the cancellation of the
+// run. A failure is recorded as a diagnostic.
+func (s *scenario) judgePartial(ctx context.Context, result *Result, turn int) {
+	if s.partialJudgment <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.partialJudgment)
+	defer cancel()
+
+	_, verdict, err := s.generate(s.judgeContext(ctx, turn, true), s.turnStrategy(turn), s.judgedConversation(), false, true)
+	if err == nil && verdict == nil {
+		err = errors.New("no verdict generated")
+	}
+	if err != nil {
+		RecordDiagnostic(withAuditScope(ctx, ComponentJudge, turn), Diagnostic{
+			Code:    DiagnosticPartialJudgmentFailed,
+			Message: err.Error(),
+		})
+		return
+	}
+	result.PartialVerdict = verdict
+}
@@ -0,0 +1,139 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPartialJudgmentTestingAgent returns a testing agent sending a first message, and
+// judging the partial conversation with the given error.
+func newPartialJudgmentTestingAgent(judgeErr error) *mockTestingAgent {
+	return &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "hi"
+				return &msg, nil, nil
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+			if judgeErr != nil {
+				return nil, nil, judgeErr
+			}
+			return nil, NewFailurePartialResult(conversation, "The agent never answered.", nil, []string{"answers the user"}, nil), nil
+		},
+	}
+}
+
+// blockingAgent blocks until the run is cancelled.
+var blockingAgent = &mockAgent{
+	runFunc: func(ctx context.Context, message string) ([]Message, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	},
+}
+
+func TestScenario_Run_PartialJudgmentAborted(t *testing.T) {
+	signal := make(chan struct{})
+	close(signal)
+
+	result, err := NewScenario(
+		WithAgent(blockingAgent),
+		WithTestingAgent(newPartialJudgmentTestingAgent(nil)),
+		WithAbortSignal(signal),
+		WithPartialJudgment(time.Second),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Aborted)
+	assert.False(t, result.Success)
+	require.NotNil(t, result.PartialVerdict)
+	assert.Equal(t, "The agent never answered.", result.PartialVerdict.Reasoning)
+	assert.Equal(t, []string{"answers the user"}, result.PartialVerdict.UnmetCriteria)
+}
+
+func TestScenario_Run_PartialJudgmentTimedOut(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(blockingAgent),
+		WithTestingAgent(newPartialJudgmentTestingAgent(nil)),
+		WithHardTimeout(10*time.Millisecond),
+		WithPartialJudgment(time.Second),
+	).Run(context.Background())
+
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.False(t, result.Success)
+	assert.Len(t, result.Conversation, 1)
+	assert.Contains(t, result.Reasoning, "timed out at turn 0")
+	assert.NotEmpty(t, result.RunID)
+	require.NotNil(t, result.PartialVerdict)
+	assert.Equal(t, "The agent never answered.", result.PartialVerdict.Reasoning)
+}
+
+func TestScenario_Run_PartialJudgmentFailed(t *testing.T) {
+	signal := make(chan struct{})
+	close(signal)
+
+	result, err := NewScenario(
+		WithAgent(blockingAgent),
+		WithTestingAgent(newPartialJudgmentTestingAgent(errors.New("judge unavailable"))),
+		WithAbortSignal(signal),
+		WithPartialJudgment(time.Second),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Aborted)
+	assert.Nil(t, result.PartialVerdict)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, DiagnosticPartialJudgmentFailed, result.Diagnostics[0].Code)
+	assert.Equal(t, ComponentJudge, result.Diagnostics[0].Component)
+}
+
+func TestScenario_Run_WithoutPartialJudgment(t *testing.T) {
+	signal := make(chan struct{})
+	close(signal)
+
+	result, err := NewScenario(
+		WithAgent(blockingAgent),
+		WithTestingAgent(newPartialJudgmentTestingAgent(nil)),
+		WithAbortSignal(signal),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Aborted)
+	assert.Nil(t, result.PartialVerdict)
+}
+
+func TestScenario_Run_PartialJudgmentTimedOutNotCached(t *testing.T) {
+	cache := NewDirResultCache(t.TempDir())
+
+	result, err := NewScenario(
+		WithID("timeout"),
+		WithAgent(blockingAgent),
+		WithTestingAgent(newPartialJudgmentTestingAgent(nil)),
+		WithHardTimeout(10*time.Millisecond),
+		WithPartialJudgment(time.Second),
+		WithResultCache(cache, "v1"),
+		WithSeed(1),
+	).Run(context.Background())
+
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.True(t, result.TimedOut)
+
+	result, err = NewScenario(
+		WithID("timeout"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithResultCache(cache, "v1"),
+		WithSeed(1),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, result.Cached, "the partial result of the timed out run is not replayed")
+	assert.False(t, result.TimedOut)
+	assert.True(t, result.Success)
+}
@@ -0,0 +1,101 @@
+package scenario
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// Persona is a simulated user profile sampled from a pool with WithPersonaPool.
+type Persona struct {
+	// Name identifies the persona in results, e.g. "retired-novice".
+	Name string `json:"name"`
+
+	// Age is the age of the user, 0 when not relevant.
+	Age int `json:"age,omitempty"`
+
+	// Expertise is the expertise of the user in the domain of the agent, e.g. "novice".
+	Expertise string `json:"expertise,omitempty"`
+
+	// Patience is how patient the user is, e.g. "gives up after one unhelpful answer".
+	Patience string `json:"patience,omitempty"`
+
+	// Traits are any other traits of the user, e.g. "non-native English speaker".
+	Traits string `json:"traits,omitempty"`
+
+	// Weight is the relative probability of the persona with SamplingWeighted, 1 when zero.
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// instructions returns the persona instructions for the testing agent.
+func (p Persona) instructions() string {
+	var lines []string
+	if p.Age > 0 {
+		lines = append(lines, fmt.Sprintf("Age: %d", p.Age))
+	}
+	if p.Expertise != "" {
+		lines = append(lines, "Expertise: "+p.Expertise)
+	}
+	if p.Patience != "" {
+		lines = append(lines, "Patience: "+p.Patience)
+	}
+	if p.Traits != "" {
+		lines = append(lines, "Traits: "+p.Traits)
+	}
+
+	return "<persona>\n" + strings.Join(lines, "\n") + "\n</persona>\n" +
+		"Play the user as this persona throughout the conversation."
+}
+
+// SamplingStrategy is how a persona is picked from the pool of WithPersonaPool for each run.
+type SamplingStrategy int
+
+const (
+	// SamplingUniform picks a persona uniformly at random from the seed of the run.
+	SamplingUniform SamplingStrategy = iota
+
+	// SamplingWeighted picks a persona at random from the seed of the run, proportionally to
+	// their weights.
+	SamplingWeighted
+
+	// SamplingRoundRobin picks the personas in order, one per run of the scenario, so
+	// repetitions cover the whole pool.
+	SamplingRoundRobin
+)
+
+// samplePersona picks the persona of the run from the pool.
+func (s *scenario) samplePersona(rng *rand.Rand) (*Persona, error) {
+	var index int
+	switch s.personaSampling {
+	case SamplingUniform:
+		index = rng.IntN(len(s.personaPool))
+	case SamplingWeighted:
+		total := 0.0
+		for _, persona := range s.personaPool {
+			total += personaWeight(persona)
+		}
+		pick := rng.Float64() * total
+		for index = 0; index < len(s.personaPool)-1; index++ {
+			pick -= personaWeight(s.personaPool[index])
+			if pick < 0 {
+				break
+			}
+		}
+	case SamplingRoundRobin:
+		index = s.personaRuns % len(s.personaPool)
+		s.personaRuns++
+	default:
+		return nil, fmt.Errorf("unknown persona sampling strategy %d", s.personaSampling)
+	}
+
+	persona := s.personaPool[index]
+	return &persona, nil
+}
+
+// personaWeight returns the weight of the persona with SamplingWeighted.
+func personaWeight(persona Persona) float64 {
+	if persona.Weight <= 0 {
+		return 1
+	}
+	return persona.Weight
+}
@@ -0,0 +1,92 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_PersonaPool(t *testing.T) {
+	ctx := context.Background()
+	var strategies []string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			strategies = append(strategies, strategy)
+			if lastMessage {
+				return nil, &Result{Success: true}, nil
+			}
+			msg := "User message"
+			return &msg, nil, nil
+		},
+	}
+	pool := []Persona{
+		{Name: "novice", Age: 72, Expertise: "novice", Patience: "very patient"},
+		{Name: "expert", Expertise: "expert", Patience: "gives up quickly"},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithPersonaPool(pool, SamplingUniform),
+		WithSeed(42),
+		WithMaxTurns(1),
+	)
+
+	first, err := s.Run(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, first.Persona)
+	assert.Contains(t, strategies[0], "<persona>")
+	assert.Contains(t, strategies[0], "Expertise: "+first.Persona.Expertise)
+
+	second, err := s.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first.Persona, second.Persona)
+}
+
+func TestScenario_Run_PersonaPool_RoundRobin(t *testing.T) {
+	pool := []Persona{{Name: "a"}, {Name: "b"}}
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithPersonaPool(pool, SamplingRoundRobin),
+	)
+
+	var names []string
+	for range 3 {
+		result, err := s.Run(context.Background())
+		require.NoError(t, err)
+		names = append(names, result.Persona.Name)
+	}
+
+	assert.Equal(t, []string{"a", "b", "a"}, names)
+}
+
+func TestScenario_Run_PersonaPool_Weighted(t *testing.T) {
+	pool := []Persona{{Name: "never", Weight: 1e-9}, {Name: "always", Weight: 1e9}}
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithPersonaPool(pool, SamplingWeighted),
+	)
+
+	for range 5 {
+		result, err := s.Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "always", result.Persona.Name)
+	}
+}
+
+func TestScenario_Run_PersonaPool_Empty(t *testing.T) {
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithPersonaPool([]Persona{}, SamplingUniform),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Persona)
+}
@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/langwatch/scenario-go/internal/ptr"
+)
+
+// PreflightCheck is the outcome of a single preflight check.
+type PreflightCheck struct {
+	// Name is the name of the check.
+	Name string
+
+	// Latency is how long the check took.
+	Latency time.Duration
+
+	// Skipped is true if the check could not be performed.
+	Skipped bool
+
+	// Err is the error of the check, nil if it passed.
+	Err error
+}
+
+// PreflightReport is the outcome of Preflight.
+type PreflightReport struct {
+	// Checks is the outcome of every check performed.
+	Checks []PreflightCheck
+}
+
+// Preflight checks that the agent and the LLM of the testing agent are reachable before
+// running scenarios, so an expired API key or an unreachable endpoint fails early with a
+// clear diagnostic instead of failing every scenario identically. The agent is only checked
+// if it implements HealthCheckAgent, the LLM is checked with a cheap completion. The
+// returned error joins the errors of every failed check.
+func Preflight(ctx context.Context, agent Agent, llmCompletion LLMCompletion) (*PreflightReport, error) {
+	report := &PreflightReport{}
+
+	agentCheck := PreflightCheck{Name: "agent"}
+	if healthCheckAgent, ok := agent.(HealthCheckAgent); ok {
+		start := time.Now()
+		agentCheck.Err = healthCheckAgent.HealthCheck(ctx)
+		agentCheck.Latency = time.Since(start)
+	} else {
+		agentCheck.Skipped = true
+	}
+	report.Checks = append(report.Checks, agentCheck)
+
+	llmCheck := PreflightCheck{Name: "llm completion"}
+	start := time.Now()
+	resp, err := llmCompletion.Completion(ctx, []Message{{Role: MessageRoleUser, Content: "ping"}}, ptr.Ptr(0.0), ptr.Ptr(int64(1)), nil, nil)
+	llmCheck.Latency = time.Since(start)
+	if err != nil {
+		llmCheck.Err = err
+	} else if resp == nil || len(resp.Choices) == 0 {
+		llmCheck.Err = errors.New("no choices returned")
+	}
+	report.Checks = append(report.Checks, llmCheck)
+
+	return report, report.Err()
+}
+
+// Err joins the errors of the failed checks, it returns nil if every check passed.
+func (r *PreflightReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if check.Err != nil {
+			errs = append(errs, fmt.Errorf("preflight check %q failed after %s: %w", check.Name, check.Latency, check.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
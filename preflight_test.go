@@ -0,0 +1,55 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockHealthCheckAgent is a mock implementation of the HealthCheckAgent interface.
+type mockHealthCheckAgent struct {
+	mockAgent
+	err error
+}
+
+func (m *mockHealthCheckAgent) HealthCheck(ctx context.Context) error {
+	return m.err
+}
+
+func TestPreflight(t *testing.T) {
+	ctx := context.Background()
+	healthyLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			require.NotNil(t, maxTokens)
+			assert.Equal(t, int64(1), *maxTokens)
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{}}}, nil
+		},
+	}
+
+	report, err := Preflight(ctx, &mockAgent{}, healthyLLM)
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 2)
+	assert.True(t, report.Checks[0].Skipped)
+	assert.False(t, report.Checks[1].Skipped)
+
+	report, err = Preflight(ctx, &mockHealthCheckAgent{}, healthyLLM)
+	require.NoError(t, err)
+	assert.False(t, report.Checks[0].Skipped)
+
+	agentErr := errors.New("connection refused")
+	llmErr := errors.New("invalid api key")
+	failingLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			return nil, llmErr
+		},
+	}
+
+	_, err = Preflight(ctx, &mockHealthCheckAgent{err: agentErr}, failingLLM)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, agentErr)
+	assert.ErrorIs(t, err, llmErr)
+	assert.ErrorContains(t, err, `preflight check "llm completion" failed`)
+}
@@ -0,0 +1,39 @@
+package scenario
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string][]ScenarioOption{}
+)
+
+// RegisterProfile registers a named configuration profile, a set of options (testing agent,
+// budgets, assertions...) shared by scenarios with UseProfile. This lets teams share scenarios
+// with different operational policies. Registering a profile again replaces it.
+func RegisterProfile(name string, opts ...ScenarioOption) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+
+	profiles[name] = opts
+}
+
+// UseProfile applies the options of the profile registered with RegisterProfile. Options given
+// after it override the profile. Running a scenario using an unknown profile fails.
+func UseProfile(name string) ScenarioOption {
+	return func(s *scenario) {
+		profilesMu.RLock()
+		opts, ok := profiles[name]
+		profilesMu.RUnlock()
+
+		if !ok {
+			s.optionErr = fmt.Errorf("unknown profile %q", name)
+			return
+		}
+		for _, opt := range opts {
+			opt(s)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseProfile(t *testing.T) {
+	ctx := context.Background()
+	var conversationLengths []int
+	RegisterProfile("test-prod-safe",
+		WithMaxTurns(2),
+		WithForbiddenTools("send_email"),
+		WithTestingAgent(&mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				conversationLengths = append(conversationLengths, len(conversation))
+				msg := "User message"
+				return &msg, nil, nil
+			},
+		}),
+	)
+
+	s := NewScenario(
+		UseProfile("test-prod-safe"),
+		WithAgent(&mockAgent{}),
+		WithMaxTurns(1),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, []int{0, 2}, conversationLengths, "options after the profile override it")
+	assert.Equal(t, `forbidden tool "send_email" was called`, s.(*scenario).runFailureAssertions()[0].String())
+
+	s = NewScenario(UseProfile("missing"), WithAgent(&mockAgent{}))
+	_, err = s.Run(ctx)
+	assert.EqualError(t, err, `unknown profile "missing"`)
+}
@@ -0,0 +1,44 @@
+package scenario
+
+import "context"
+
+// JudgeProgress reports the progress of a call of the testing agent to its LLM, so that long
+// calls can be shown as active rather than hung.
+type JudgeProgress struct {
+	// Turn is the zero-based turn the testing agent is generating a message or verdict for.
+	Turn int
+
+	// Tokens is the number of tokens received so far.
+	Tokens int64
+
+	// Done is set on the last report of the call.
+	Done bool
+}
+
+type completionProgressContextKey struct{}
+
+// completionProgressFunc receives the progress of an LLM call.
+type completionProgressFunc func(tokens int64, done bool)
+
+// withCompletionProgress returns a context reporting the progress of the LLM calls made with it
+// to fn.
+func withCompletionProgress(ctx context.Context, fn completionProgressFunc) context.Context {
+	return context.WithValue(ctx, completionProgressContextKey{}, fn)
+}
+
+// completionProgressRequested reports whether the progress of the LLM calls made with the
+// context is reported, in which case LLMCompletion adapters should stream their responses.
+func completionProgressRequested(ctx context.Context) bool {
+	_, ok := ctx.Value(completionProgressContextKey{}).(completionProgressFunc)
+	return ok
+}
+
+// ReportCompletionProgress reports the number of tokens received so far by a streaming LLM
+// call, done being set once the response is complete. It is a no-op when no progress is
+// requested. LLMCompletion implementations supporting streaming should call it as chunks
+// arrive.
+func ReportCompletionProgress(ctx context.Context, tokens int64, done bool) {
+	if fn, ok := ctx.Value(completionProgressContextKey{}).(completionProgressFunc); ok {
+		fn(tokens, done)
+	}
+}
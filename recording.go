@@ -0,0 +1,200 @@
+package scenario
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"sync"
+)
+
+// ErrCompletionNotRecorded is returned by a replay completion for a request that was not
+// recorded in its store.
+var ErrCompletionNotRecorded = errors.New("completion not recorded")
+
+// CompletionRecording is a request made to an LLMCompletion and its response, recorded with
+// NewRecordingCompletion.
+type CompletionRecording struct {
+	Messages    []Message              `json:"messages"`
+	Temperature *float64               `json:"temperature,omitempty"`
+	MaxTokens   *int64                 `json:"max_tokens,omitempty"`
+	Tools       []Tool                 `json:"tools,omitempty"`
+	ToolChoice  *string                `json:"tool_choice,omitempty"`
+	Response    *LLMCompletionResponse `json:"response,omitempty"`
+}
+
+// CompletionStore stores the recordings of NewRecordingCompletion for NewReplayCompletion.
+// Implementations must be safe for concurrent use.
+type CompletionStore interface {
+	// Load returns the recording stored for the key, or false if there is none.
+	Load(key string) (*CompletionRecording, bool, error)
+
+	// Store stores the recording for the key.
+	Store(key string, recording *CompletionRecording) error
+}
+
+// blobCompletionStore is a CompletionStore storing the recordings as JSON blobs in a blob
+// store.
+type blobCompletionStore struct {
+	store BlobStore
+}
+
+// NewBlobCompletionStore creates a completion store storing the recordings as JSON blobs in the
+// store, at <key>.json.
+func NewBlobCompletionStore(store BlobStore) CompletionStore {
+	return &blobCompletionStore{store: store}
+}
+
+// NewDirCompletionStore creates a completion store storing the recordings as JSON files laid
+// out as dir/<key>.json, e.g. in the testdata directory of the package to commit them.
+func NewDirCompletionStore(dir string) CompletionStore {
+	return NewBlobCompletionStore(NewDirBlobStore(dir))
+}
+
+func (b *blobCompletionStore) Load(key string) (*CompletionRecording, bool, error) {
+	data, err := b.store.Get(key + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var recording CompletionRecording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, false, fmt.Errorf("failed to decode recording %s.json: %w", key, err)
+	}
+	return &recording, true, nil
+}
+
+func (b *blobCompletionStore) Store(key string, recording *CompletionRecording) error {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.store.Put(key+".json", data)
+}
+
+// recordingKeys derives the keys of the requests of a recording or replay completion. The key
+// of a request is the hash of the request followed by the number of times the same request
+// was made before, so a request repeated for another response, such as a regenerated message
+// of the simulated user, replays the responses in order.
+type recordingKeys struct {
+	mu    sync.Mutex
+	count map[string]int
+}
+
+// key returns the key of the request of the recording.
+func (k *recordingKeys) key(recording *CompletionRecording) (string, error) {
+	request := *recording
+	request.Response = nil
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	hash := sha256.Sum256(payload)
+	digest := hex.EncodeToString(hash[:16])
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.count == nil {
+		k.count = map[string]int{}
+	}
+	n := k.count[digest]
+	k.count[digest]++
+
+	return digest + "-" + strconv.Itoa(n), nil
+}
+
+// recordingCompletion is an LLMCompletion recording the requests made to another one.
+type recordingCompletion struct {
+	inner LLMCompletion
+	store CompletionStore
+	keys  recordingKeys
+}
+
+// NewRecordingCompletion wraps an LLMCompletion to record the requests made to it and their
+// responses in the store, to replay them with NewReplayCompletion. Failed requests are not
+// recorded.
+func NewRecordingCompletion(inner LLMCompletion, store CompletionStore) LLMCompletion {
+	return &recordingCompletion{inner: inner, store: store}
+}
+
+func (r *recordingCompletion) Completion(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+	recording := &CompletionRecording{
+		Messages:    messages,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+		Tools:       tools,
+		ToolChoice:  toolChoice,
+	}
+	key, err := r.keys.key(recording)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.inner.Completion(ctx, messages, temperature, maxTokens, tools, toolChoice)
+	if err != nil {
+		return nil, err
+	}
+	recording.Response = response
+	if err := r.store.Store(key, recording); err != nil {
+		return nil, fmt.Errorf("failed to record completion: %w", err)
+	}
+
+	return response, nil
+}
+
+// Model returns the model of the wrapped LLMCompletion, empty when it does not implement
+// ModelNamer.
+func (r *recordingCompletion) Model() string {
+	return completionModel(r.inner)
+}
+
+// replayCompletion is an LLMCompletion replaying recorded responses.
+type replayCompletion struct {
+	store CompletionStore
+	model string
+	keys  recordingKeys
+}
+
+// NewReplayCompletion creates an LLMCompletion responding with the responses recorded in the
+// store by NewRecordingCompletion, without any outbound call, so scenario tests are
+// deterministic and free in CI. Requests that were not recorded fail with
+// ErrCompletionNotRecorded. The model is the model of the recorded adapter, the testing agent
+// adapting its prompts to its capabilities, empty if it did not implement ModelNamer.
+func NewReplayCompletion(store CompletionStore, model string) LLMCompletion {
+	return &replayCompletion{store: store, model: model}
+}
+
+func (r *replayCompletion) Completion(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+	key, err := r.keys.key(&CompletionRecording{
+		Messages:    messages,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+		Tools:       tools,
+		ToolChoice:  toolChoice,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recording, ok, err := r.store.Load(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recorded completion: %w", err)
+	}
+	if !ok || recording.Response == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCompletionNotRecorded, key)
+	}
+
+	return recording.Response, nil
+}
+
+// Model returns the model of the recorded adapter.
+func (r *replayCompletion) Model() string {
+	return r.model
+}
@@ -0,0 +1,74 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingCompletion_Replay(t *testing.T) {
+	ctx := context.Background()
+	store := NewDirCompletionStore(t.TempDir())
+
+	calls := 0
+	inner := &mockNamedLLMCompletion{
+		mockLLMCompletion: mockLLMCompletion{
+			completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+				calls++
+				return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+					Message: LLMCompletionResponseChoiceMessage{Content: fmt.Sprintf("response %d to %s", calls, messages[0].Content)},
+				}}}, nil
+			},
+		},
+		model: "gpt-4o",
+	}
+	recording := NewRecordingCompletion(inner, store)
+	assert.Equal(t, "gpt-4o", recording.(ModelNamer).Model())
+
+	temperature := 0.0
+	toolChoice := "required"
+	tools := []Tool{{Type: ToolTypeFunction, Function: &ToolFunction{Name: "finish_test", Parameters: map[string]any{"type": "object"}}}}
+	var recorded []string
+	for _, content := range []string{"hello", "hello", "bye"} {
+		response, err := recording.Completion(ctx, []Message{{Role: MessageRoleUser, Content: content}}, &temperature, nil, tools, &toolChoice)
+		require.NoError(t, err)
+		recorded = append(recorded, response.Choices[0].Message.Content)
+	}
+	assert.Equal(t, []string{"response 1 to hello", "response 2 to hello", "response 3 to bye"}, recorded)
+
+	replay := NewReplayCompletion(store, "gpt-4o")
+	assert.Equal(t, "gpt-4o", replay.(ModelNamer).Model())
+	for i, content := range []string{"hello", "hello", "bye"} {
+		response, err := replay.Completion(ctx, []Message{{Role: MessageRoleUser, Content: content}}, &temperature, nil, tools, &toolChoice)
+		require.NoError(t, err)
+		assert.Equal(t, recorded[i], response.Choices[0].Message.Content)
+	}
+	assert.Equal(t, 3, calls)
+
+	_, err := replay.Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hello"}}, &temperature, nil, tools, &toolChoice)
+	assert.ErrorIs(t, err, ErrCompletionNotRecorded)
+	_, err = NewReplayCompletion(store, "").Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hello"}}, nil, nil, tools, &toolChoice)
+	assert.ErrorIs(t, err, ErrCompletionNotRecorded)
+}
+
+func TestRecordingCompletion_Error(t *testing.T) {
+	ctx := context.Background()
+	store := NewDirCompletionStore(t.TempDir())
+	errProvider := errors.New("rate limited")
+	recording := NewRecordingCompletion(&mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			return nil, errProvider
+		},
+	}, store)
+	assert.Empty(t, recording.(ModelNamer).Model())
+
+	_, err := recording.Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hello"}}, nil, nil, nil, nil)
+	require.ErrorIs(t, err, errProvider)
+
+	_, err = NewReplayCompletion(store, "").Completion(ctx, []Message{{Role: MessageRoleUser, Content: "hello"}}, nil, nil, nil, nil)
+	assert.ErrorIs(t, err, ErrCompletionNotRecorded)
+}
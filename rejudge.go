@@ -0,0 +1,123 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MessageEdit replaces a message of a conversation.
+type MessageEdit struct {
+	// Index is the index of the message to replace in the conversation.
+	Index int
+
+	// Message is the replacement message.
+	Message Message
+}
+
+// Rejudge applies the edits to the conversation of a completed result and asks the testing
+// agent of the scenario for a new verdict over the modified transcript, without running the
+// agent or simulating new turns. It answers questions such as "would it have passed if the
+// agent had said X at turn 3?". The verdict goes through the votes, second opinion, canary,
+// truncation and assertions of the scenario, as in Run. The scenario must have been created
+// with NewScenario, it is not modified.
+func Rejudge(ctx context.Context, sc Scenario, result *Result, edits ...MessageEdit) (*Result, error) {
+	base, ok := sc.(*scenario)
+	if !ok {
+		return nil, errors.New("rejudging requires a scenario created with NewScenario")
+	}
+
+	s := base.clone()
+	s.runSeed = result.Seed
+	if err := s.resolveTemplates(); err != nil {
+		return nil, err
+	}
+
+	conversation := append([]Message{}, result.Conversation...)
+	for _, edit := range edits {
+		if edit.Index < 0 || edit.Index >= len(conversation) {
+			return nil, fmt.Errorf("edit index %d out of range for conversation of %d messages", edit.Index, len(conversation))
+		}
+		conversation[edit.Index] = edit.Message
+	}
+	s.conversation = conversation
+	s.turns = result.Turns
+	s.persona = result.Persona
+	s.emotionalStates = nil
+	for _, turn := range result.Turns {
+		if turn.EmotionalState != "" {
+			s.emotionalStates = append(s.emotionalStates, turn.EmotionalState)
+		}
+	}
+
+	ctx, s.auditLog = withAuditLog(ctx, s.callObserver)
+	ctx, s.diagnostics = withDiagnostics(ctx)
+	turn := len(s.turns)
+	_, rejudged, err := s.generate(s.judgeContext(ctx, turn, true), s.turnStrategy(turn), s.judgedConversation(), false, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rejudge conversation: %w", err)
+	}
+	if rejudged == nil {
+		return nil, errors.New("testing agent did not give a verdict")
+	}
+	rejudged, err = s.judgeVerdict(ctx, rejudged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rejudge conversation: %w", err)
+	}
+
+	rejudged.ScenarioID = result.ScenarioID
+	rejudged.RunID = result.RunID
+	rejudged.Seed = result.Seed
+	rejudged.AuditLog = s.auditLog.Entries()
+	rejudged.Diagnostics = s.diagnostics.Diagnostics()
+	return rejudged, nil
+}
+
+// CriteriaImpact is the outcome of a historical result rejudged under updated criteria.
+type CriteriaImpact struct {
+	// Before is the historical result.
+	Before *Result
+
+	// After is the result rejudged under the updated criteria.
+	After *Result
+}
+
+// Changed reports whether the updated criteria change the success of the result.
+func (c CriteriaImpact) Changed() bool {
+	return c.Before.Success != c.After.Success
+}
+
+// RejudgeWithCriteria rejudges the historical results of the scenario under updated success
+// and failure criteria, without simulating new conversations, to show how past runs would
+// score before the criteria are rolled out. The rejudged results keep the scenario and run IDs
+// of the historical results, and the scenario is not modified. Aggregate the After results with
+// NewCriteriaReport for the impact per criterion.
+func RejudgeWithCriteria(ctx context.Context, sc Scenario, results []*Result, successCriteria, failureCriteria []string) ([]CriteriaImpact, error) {
+	s, ok := sc.(*scenario)
+	if !ok {
+		return nil, errors.New("rejudging requires a scenario created with NewScenario")
+	}
+	updated := s.clone()
+	if s.templates != nil {
+		updated.description = s.templates.description
+		updated.strategy = s.templates.strategy
+		updated.templates = nil
+	}
+	updated.successCriteria = successCriteria
+	updated.successWeights = nil
+	updated.failureCriteria = failureCriteria
+	if err := updated.parseTemplates(); err != nil {
+		return nil, err
+	}
+
+	impacts := make([]CriteriaImpact, len(results))
+	for i, result := range results {
+		after, err := Rejudge(ctx, updated, result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rejudge result %d: %w", i, err)
+		}
+		impacts[i] = CriteriaImpact{Before: result, After: after}
+	}
+
+	return impacts, nil
+}
@@ -0,0 +1,121 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejudge(t *testing.T) {
+	ctx := context.Background()
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			assert.True(t, lastMessage)
+			assert.Equal(t, []string{"Agent offers a refund"}, successCriteria)
+			for _, message := range conversation {
+				if strings.Contains(message.Content, "refund") && message.Role == MessageRoleAssistant {
+					return nil, NewSuccessPartialResult(conversation, "Refund offered", successCriteria), nil
+				}
+			}
+			return nil, NewFailurePartialResult(conversation, "No refund", nil, successCriteria, nil), nil
+		},
+	}
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithSuccessCriteria("Agent offers a refund"),
+	)
+	original := &Result{
+		Success: false,
+		Conversation: []Message{
+			{Role: MessageRoleUser, Content: "my order is broken"},
+			{Role: MessageRoleAssistant, Content: "sorry to hear that"},
+		},
+		Seed: 42,
+	}
+
+	rejudged, err := Rejudge(ctx, s, original, MessageEdit{
+		Index:   1,
+		Message: Message{Role: MessageRoleAssistant, Content: "sorry, here is a refund"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, rejudged.Success)
+	assert.Equal(t, "sorry, here is a refund", rejudged.Conversation[1].Content)
+	assert.Equal(t, int64(42), rejudged.Seed)
+	assert.Equal(t, "sorry to hear that", original.Conversation[1].Content)
+
+	_, err = Rejudge(ctx, s, original, MessageEdit{Index: 2})
+	assert.ErrorContains(t, err, "edit index 2 out of range")
+}
+
+func TestRejudge_VerdictPipeline(t *testing.T) {
+	ctx := context.Background()
+	votes := 0
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				votes++
+				return nil, NewSuccessPartialResult(conversation, "ok", successCriteria), nil
+			},
+		}),
+		WithDescription("Order {{.Seed}}"),
+		WithSuccessCriteria("Agent offers a refund"),
+		WithJudgeVotes(3),
+		WithSuccessAssertions(ToolCalled("refund").AtLeast(1)),
+	)
+	original := &Result{
+		Conversation: []Message{{Role: MessageRoleUser, Content: "broken"}, {Role: MessageRoleAssistant, Content: "here is a refund"}},
+		Seed:         42,
+	}
+
+	rejudged, err := Rejudge(ctx, s, original)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, votes, "the votes of the scenario are sampled")
+	assert.False(t, rejudged.Success, "the assertions of the scenario are applied")
+	assert.Equal(t, []string{`at least 1 calls to tool "refund"`}, rejudged.UnmetCriteria)
+	assert.Equal(t, int64(0), s.(*scenario).runSeed, "the scenario is not modified")
+	assert.Equal(t, "Order {{.Seed}}", s.(*scenario).description)
+	assert.Nil(t, s.(*scenario).conversation)
+}
+
+func TestRejudgeWithCriteria(t *testing.T) {
+	ctx := context.Background()
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			for _, criterion := range successCriteria {
+				if criterion == "Agent apologizes" && !strings.Contains(conversation[1].Content, "sorry") {
+					return nil, NewFailurePartialResult(conversation, "No apology", nil, []string{criterion}, nil), nil
+				}
+			}
+			return nil, NewSuccessPartialResult(conversation, "All met", successCriteria), nil
+		},
+	}
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithSuccessCriteria("Agent offers a refund"),
+		WithFailureCriteria("Agent is rude"),
+	)
+	results := []*Result{
+		{ScenarioID: "refunds/broken-order", RunID: "01J9ZQ3X5V8K2M4N6P8R0T2W4Y", Success: true, Conversation: []Message{{Role: MessageRoleUser, Content: "broken"}, {Role: MessageRoleAssistant, Content: "sorry, here is a refund"}}},
+		{Success: true, Conversation: []Message{{Role: MessageRoleUser, Content: "broken"}, {Role: MessageRoleAssistant, Content: "here is a refund"}}},
+	}
+
+	impacts, err := RejudgeWithCriteria(ctx, s, results, []string{"Agent offers a refund", "Agent apologizes"}, nil)
+
+	require.NoError(t, err)
+	require.Len(t, impacts, 2)
+	assert.False(t, impacts[0].Changed())
+	assert.True(t, impacts[1].Changed())
+	assert.Equal(t, []string{"Agent apologizes"}, impacts[1].After.UnmetCriteria)
+	assert.Equal(t, "refunds/broken-order", impacts[0].After.ScenarioID)
+	assert.Equal(t, "01J9ZQ3X5V8K2M4N6P8R0T2W4Y", impacts[0].After.RunID)
+	assert.Equal(t, []string{"Agent offers a refund"}, s.(*scenario).successCriteria, "the scenario is not modified")
+	assert.Equal(t, []string{"Agent is rude"}, s.(*scenario).failureCriteria, "the scenario is not modified")
+}
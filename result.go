@@ -1,35 +1,184 @@
 package scenario
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
 // Result is the result of a scenario.
 type Result struct {
+	// ScenarioID is the identifier of the scenario, set with WithID or generated from the
+	// description. Generated IDs change whenever the description is edited, see WithID.
+	ScenarioID string `json:"scenario_id"`
+
+	// RunID is the unique identifier of the run, a ULID sorting by start time.
+	RunID string `json:"run_id"`
+
 	// Success is true if the scenario was successful.
-	Success bool
+	Success bool `json:"success"`
+
+	// Cached is true if the result was loaded from the cache set with WithResultCache instead
+	// of running the scenario.
+	Cached bool `json:"cached,omitempty"`
+
+	// Aborted is true if the run was aborted with AbortRun or WithAbortSignal before a
+	// verdict, the result holding the partial conversation.
+	Aborted bool `json:"aborted,omitempty"`
+
+	// TimedOut is true if the run timed out before a verdict, the result holding the partial
+	// conversation, returned with WithPartialJudgment along with ErrTimeout.
+	TimedOut bool `json:"timed_out,omitempty"`
+
+	// DeadlineReached is true if the soft deadline set with WithSoftDeadline was reached, the
+	// verdict judging the conversation so far.
+	DeadlineReached bool `json:"deadline_reached,omitempty"`
 
 	// Conversation is the conversation between the user and the assistant.
-	Conversation []Message
+	Conversation []Message `json:"conversation"`
 
 	// Reasoning is the reasoning for the result given by the assistant.
-	Reasoning string
+	Reasoning string `json:"reasoning"`
 
 	// MetCriteria is the criteria that were met by the assistant.
-	MetCriteria []string
+	MetCriteria []string `json:"met_criteria"`
 
 	// UnmetCriteria is the criteria that were not met by the assistant.
-	UnmetCriteria []string
+	UnmetCriteria []string `json:"unmet_criteria"`
 
 	// TriggeredFailures is the failures that were triggered by the assistant.
-	TriggeredFailures []string
+	TriggeredFailures []string `json:"triggered_failures"`
+
+	// CriteriaScores is the score of every success criterion given to the testing agent, 1
+	// when met and 0 otherwise, nil when the result is not a verdict of the testing agent.
+	CriteriaScores map[string]float64 `json:"criteria_scores,omitempty"`
+
+	// Score is the weighted score of the success criteria met, from 0 to 1, weighted with
+	// WithWeightedSuccessCriteria, 1 when there are no success criteria.
+	Score float64 `json:"score"`
+
+	// ConversationStats are the statistics of the conversation.
+	ConversationStats ConversationStatistics `json:"conversation_stats"`
+
+	// JudgedConversation is the conversation shown to the testing agent when truncation
+	// policies are set with WithTruncation, nil otherwise.
+	JudgedConversation []Message `json:"judged_conversation,omitempty"`
+
+	// Evidence is the zero-based indices of the messages of the judged conversation the
+	// testing agent cited for every criterion of its verdict, see JudgedMessages.
+	Evidence map[string][]int `json:"evidence,omitempty"`
+
+	// Tags are the tags added during the run, e.g. by WatchTag watchers.
+	Tags []string `json:"tags,omitempty"`
+
+	// Confidence is the confidence of the testing agent in its verdict, from 0 to 1, nil when
+	// not reported.
+	Confidence *float64 `json:"confidence,omitempty"`
+
+	// JudgeStrictness is the strictness of the testing agent that gave the verdict, set with
+	// WithJudgeStrictness, empty for testing agents not created with NewTestingAgent.
+	JudgeStrictness JudgeStrictness `json:"judge_strictness,omitempty"`
+
+	// UserSelfReport is the assessment of the conversation by the simulated user, requested
+	// with WithUserSelfReport, nil otherwise.
+	UserSelfReport *UserSelfReport `json:"user_self_report,omitempty"`
+
+	// DissentingVerdicts are the verdicts of the testing agent that disagreed with the
+	// majority when voting with WithJudgeVotes, nil otherwise.
+	DissentingVerdicts []*Result `json:"dissenting_verdicts,omitempty"`
+
+	// FirstOpinion is the low confidence verdict a second opinion was asked for with
+	// WithSecondOpinion, the result holding the second opinion. It is nil otherwise.
+	FirstOpinion *Result `json:"first_opinion,omitempty"`
+
+	// CanaryVerdict is the verdict of the canary judge set with WithCanaryJudge, which does
+	// not affect the result, nil otherwise.
+	CanaryVerdict *Result `json:"canary_verdict,omitempty"`
+
+	// PartialVerdict is the provisional verdict of the testing agent on the partial
+	// conversation of a run aborted or timed out, requested with WithPartialJudgment, nil
+	// otherwise. It is not the verdict of the scenario, which failed.
+	PartialVerdict *Result `json:"partial_verdict,omitempty"`
 
 	// TotalDurationNSec is the total duration of the scenario, in nanoseconds.
-	TotalDurationNSec time.Duration
+	TotalDurationNSec time.Duration `json:"total_duration_ns"`
 
 	// AgentDurationNSec is the duration of your agent within the scenario, in nanoseconds.
-	AgentDurationNSec time.Duration
+	AgentDurationNSec time.Duration `json:"agent_duration_ns"`
+
+	// Turns are the statistics of every turn of the conversation.
+	Turns []TurnStats `json:"turns"`
+
+	// Artifacts are the artifacts produced by agents implementing ArtifactProducer, write them
+	// next to the run with WriteArtifacts.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+
+	// Persona is the persona of the simulated user sampled with WithPersonaPool, nil otherwise.
+	Persona *Persona `json:"persona,omitempty"`
+
+	// Seed is the seed used for the randomized behaviors of the run, pass it to WithSeed to reproduce the run.
+	Seed int64 `json:"seed"`
+
+	// JudgeSampling is the sampling of the turns judged by the per-turn judge calls, set with
+	// WithJudgeSampling, nil when every turn is judged.
+	JudgeSampling *JudgeSampling `json:"judge_sampling,omitempty"`
+
+	// Diagnostics are the non-fatal anomalies encountered during the run, such as provider
+	// retries or truncations.
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+
+	// AuditLog is the log of every outbound call made by the package during the run.
+	AuditLog []AuditEntry `json:"audit_log,omitempty"`
+}
+
+// CriterionStatus is the outcome of a criterion.
+type CriterionStatus string
+
+const (
+	// CriterionMet is the status of a met success criterion.
+	CriterionMet CriterionStatus = "met"
+
+	// CriterionUnmet is the status of an unmet success criterion.
+	CriterionUnmet CriterionStatus = "unmet"
+
+	// CriterionTriggered is the status of a triggered failure criterion.
+	CriterionTriggered CriterionStatus = "triggered"
+)
+
+// CriterionResult is the outcome of a criterion with the evidence supporting it.
+type CriterionResult struct {
+	// Criterion is the criterion.
+	Criterion string `json:"criterion"`
+
+	// Status is the outcome of the criterion.
+	Status CriterionStatus `json:"status"`
+
+	// Evidence is the zero-based indices of the messages of the conversation supporting the
+	// outcome, empty when none were cited.
+	Evidence []int `json:"evidence,omitempty"`
+}
+
+// CriterionResults returns the outcome of every met, unmet and triggered criterion of the
+// result with its evidence.
+func (r *Result) CriterionResults() []CriterionResult {
+	var results []CriterionResult
+	add := func(criteria []string, status CriterionStatus) {
+		for _, criterion := range criteria {
+			results = append(results, CriterionResult{
+				Criterion: criterion,
+				Status:    status,
+				Evidence:  r.Evidence[criterion],
+			})
+		}
+	}
+	add(r.MetCriteria, CriterionMet)
+	add(r.UnmetCriteria, CriterionUnmet)
+	add(r.TriggeredFailures, CriterionTriggered)
+
+	return results
 }
 
 // NewSuccessPartialResult creates a new success result without the total time elapsed and agent time elapsed.
@@ -82,6 +231,75 @@ func NewInconclusivePartialResult(
 	}
 }
 
+// JudgedMessages returns the conversation the testing agent judged, which the indices of
+// Evidence refer to: JudgedConversation when truncation policies were set, Conversation
+// otherwise.
+func (r *Result) JudgedMessages() []Message {
+	if r.JudgedConversation != nil {
+		return r.JudgedConversation
+	}
+	return r.Conversation
+}
+
+// ComponentUsage is the usage of the outbound calls made for a component of a run.
+type ComponentUsage struct {
+	// Calls is the number of calls.
+	Calls int `json:"calls"`
+
+	// PromptTokens is the number of prompt tokens of the calls.
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CompletionTokens is the number of completion tokens of the calls.
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// UsageByComponent returns the usage of the outbound calls of the audit log of the result by
+// component, calls not attributed to a component being under the empty component.
+func (r *Result) UsageByComponent() map[Component]ComponentUsage {
+	usage := map[Component]ComponentUsage{}
+	for _, entry := range r.AuditLog {
+		u := usage[entry.Component]
+		u.Calls++
+		u.PromptTokens += entry.PromptTokens
+		u.CompletionTokens += entry.CompletionTokens
+		usage[entry.Component] = u
+	}
+	return usage
+}
+
+// RunDir returns the directory of the artifacts of the run under base, laid out as
+// base/<scenario id>/<run id>.
+func (r *Result) RunDir(base string) string {
+	return filepath.Join(base, filepath.FromSlash(r.ScenarioID), r.RunID)
+}
+
+// WriteJSON writes the result as indented JSON, e.g. to archive the transcript of a run and
+// diff it between CI runs. Read it back with ReadResultJSON.
+func (r *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	return nil
+}
+
+// ReadResultJSON reads a result written with WriteJSON, decompressing it if it was written
+// through a gzip.Writer, see DecompressReader.
+func ReadResultJSON(r io.Reader) (*Result, error) {
+	r, err := DecompressReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	var result Result
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	return &result, nil
+}
+
 // LogResultDetails logs detailed information about the Result struct. It's useful to call
 // this in your tests on failure to get more context about the result, which will aid you
 // with debugging.
@@ -89,11 +307,65 @@ func (r *Result) LogResultDetails(t *testing.T) {
 	t.Helper()
 
 	t.Logf("Test Result Details:")
+	t.Logf("Scenario ID: %s", r.ScenarioID)
+	t.Logf("Run ID: %s", r.RunID)
 	t.Logf("Success: %v", r.Success)
+	if r.Aborted {
+		t.Logf("Aborted: true")
+	}
+	if r.TimedOut {
+		t.Logf("Timed out: true")
+	}
+	if r.DeadlineReached {
+		t.Logf("Deadline reached: true")
+	}
+	if r.Cached {
+		t.Logf("Cached: true")
+	}
 	t.Logf("Reasoning: %s", r.Reasoning)
 	t.Logf("Met Criteria: %v", r.MetCriteria)
 	t.Logf("Unmet Criteria: %v", r.UnmetCriteria)
 	t.Logf("Triggered Failures: %v", r.TriggeredFailures)
+	if r.CriteriaScores != nil {
+		t.Logf("Score: %.2f", r.Score)
+	}
+	for _, criterion := range r.CriterionResults() {
+		if len(criterion.Evidence) > 0 {
+			t.Logf("Evidence (%s) %s: messages %v", criterion.Status, criterion.Criterion, criterion.Evidence)
+		}
+	}
+	if r.Confidence != nil {
+		t.Logf("Confidence: %.2f", *r.Confidence)
+	}
+	if r.UserSelfReport != nil {
+		t.Logf("User Self Report: goal achieved=%v, satisfaction=%d/5, comment=%s", r.UserSelfReport.GoalAchieved, r.UserSelfReport.Satisfaction, r.UserSelfReport.Comment)
+	}
+	for _, dissenting := range r.DissentingVerdicts {
+		t.Logf("Dissenting Verdict: success=%v, reasoning=%s", dissenting.Success, dissenting.Reasoning)
+	}
+	if r.FirstOpinion != nil {
+		t.Logf("First Opinion: success=%v, reasoning=%s", r.FirstOpinion.Success, r.FirstOpinion.Reasoning)
+	}
+	if r.CanaryVerdict != nil {
+		t.Logf("Canary Verdict: success=%v, reasoning=%s", r.CanaryVerdict.Success, r.CanaryVerdict.Reasoning)
+	}
+	if r.PartialVerdict != nil {
+		t.Logf("Partial Verdict: success=%v, reasoning=%s", r.PartialVerdict.Success, r.PartialVerdict.Reasoning)
+	}
 	t.Logf("Total Duration (ns): %v", r.TotalDurationNSec)
 	t.Logf("Agent Duration (ns): %v", r.AgentDurationNSec)
+	if r.JudgeStrictness != "" {
+		t.Logf("Judge strictness: %s", r.JudgeStrictness)
+	}
+	if r.Persona != nil {
+		t.Logf("Persona: %s", r.Persona.Name)
+	}
+	t.Logf("Seed: %d", r.Seed)
+	t.Logf("Outbound Calls: %d", len(r.AuditLog))
+	for component, usage := range r.UsageByComponent() {
+		t.Logf("Usage (%s): %d calls, %d prompt tokens, %d completion tokens", component, usage.Calls, usage.PromptTokens, usage.CompletionTokens)
+	}
+	for _, diagnostic := range r.Diagnostics {
+		t.Logf("Diagnostic (%s, %s, turn %d): %s", diagnostic.Code, diagnostic.Component, diagnostic.Turn, diagnostic.Message)
+	}
 }
@@ -1,8 +1,13 @@
 package scenario
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestResult_LogResultDetails tests that LogResultDetails runs without errors for various result types.
@@ -71,3 +76,49 @@ func TestResult_LogResultDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestResult_WriteJSON(t *testing.T) {
+	confidence := 0.8
+	result := &Result{
+		ScenarioID: "checkout",
+		RunID:      "01J0000000000000000000000",
+		Success:    true,
+		Conversation: []Message{
+			{Role: MessageRoleUser, Content: "where is my order?"},
+			{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{
+				ID:       "call_1",
+				Type:     ToolTypeFunction,
+				Function: &ToolCallFunction{Name: "lookup_order", Arguments: map[string]any{"id": "42"}},
+			}}},
+			{Role: MessageRoleTool, Content: "shipped", ToolCallID: "call_1"},
+		},
+		Reasoning:         "The agent looked the order up.",
+		MetCriteria:       []string{"looks the order up"},
+		UnmetCriteria:     []string{},
+		TriggeredFailures: []string{},
+		Evidence:          map[string][]int{"looks the order up": {1}},
+		Confidence:        &confidence,
+		TotalDurationNSec: 2 * time.Second,
+		Turns:             []TurnStats{{Turn: 0, AgentDuration: time.Second, ResponseMessages: 2}},
+		Diagnostics:       []Diagnostic{{Code: DiagnosticProviderRetry, Component: ComponentAgent, Message: "retrying"}},
+		Seed:              42,
+	}
+	result.ConversationStats = ConversationStats(result.Conversation)
+
+	var buf bytes.Buffer
+	require.NoError(t, result.WriteJSON(&buf))
+
+	assert.Contains(t, buf.String(), `"scenario_id": "checkout"`)
+	assert.Contains(t, buf.String(), `"tool_call_id": "call_1"`)
+	assert.Contains(t, buf.String(), `"total_duration_ns": 2000000000`)
+	assert.NotContains(t, buf.String(), `"first_opinion"`)
+
+	decoded, err := ReadResultJSON(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, result, decoded)
+}
+
+func TestReadResultJSON_Invalid(t *testing.T) {
+	_, err := ReadResultJSON(strings.NewReader("{"))
+	assert.ErrorContains(t, err, "failed to decode result")
+}
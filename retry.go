@@ -0,0 +1,162 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+// ErrAgentUnavailable is returned by runs aborted because the agent kept failing with the same
+// error, see RetryPolicy.MaxIdenticalAgentErrors.
+var ErrAgentUnavailable = errors.New("agent unavailable")
+
+// RetryPolicy retries the calls of the agent and the testing agent failing with transient
+// errors, such as rate limits, see WithRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a call, including the first one. Calls
+	// are not retried when it is 0 or 1.
+	MaxAttempts int
+
+	// Backoff returns the delay before the given retry, starting from 1, e.g.
+	// ExponentialBackoff. Calls are retried immediately when it is nil.
+	Backoff func(retry int) time.Duration
+
+	// RetryableErrors reports whether a call failing with the error is retried. It defaults to
+	// IsTransientError.
+	RetryableErrors func(err error) bool
+
+	// MaxIdenticalAgentErrors is the number of consecutive attempts of a call of the agent
+	// failing with the same error after which the run is aborted with ErrAgentUnavailable,
+	// rather than retried up to MaxAttempts, so a service that is down is not hammered, 0 for
+	// no limit. Errors are the same when they have the same status code of an LLM provider,
+	// are both network errors, or have the same message.
+	MaxIdenticalAgentErrors int
+}
+
+// ExponentialBackoff returns a backoff doubling from base at every retry, up to max.
+func ExponentialBackoff(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		delay := base
+		for i := 1; i < retry && delay < max; i++ {
+			delay *= 2
+		}
+		return min(delay, max)
+	}
+}
+
+// IsTransientError reports whether the error is worth retrying: a rate limit or server error
+// of the OpenAI or Anthropic APIs, or a network error.
+func IsTransientError(err error) bool {
+	if errors.Is(err, ErrDestinationNotAllowed) {
+		return false
+	}
+
+	var openAIErr *openai.Error
+	if errors.As(err, &openAIErr) {
+		return openAIErr.StatusCode == http.StatusTooManyRequests || openAIErr.StatusCode >= http.StatusInternalServerError
+	}
+	var anthropicErr *AnthropicError
+	if errors.As(err, &anthropicErr) {
+		return anthropicErr.retryable()
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// retry calls fn until it succeeds, fails with an error that is not retryable or the attempts
+// of the retry policy are exhausted. Retries are recorded as diagnostics.
+func (s *scenario) retry(ctx context.Context, fn func() error) error {
+	retryable := s.retryPolicy.RetryableErrors
+	if retryable == nil {
+		retryable = IsTransientError
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= s.retryPolicy.MaxAttempts || ctx.Err() != nil || errors.Is(err, ErrAgentUnavailable) || !retryable(err) {
+			return err
+		}
+
+		var delay time.Duration
+		if s.retryPolicy.Backoff != nil {
+			delay = s.retryPolicy.Backoff(attempt)
+		}
+		RecordDiagnostic(ctx, Diagnostic{
+			Code:      DiagnosticCallRetried,
+			Message:   fmt.Sprintf("retrying after %s, attempt %d of %d: %v", delay, attempt+1, s.retryPolicy.MaxAttempts, err),
+			Retryable: true,
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// identicalErrors counts the consecutive attempts of a call failing with the same error.
+type identicalErrors struct {
+	category string
+	count    int
+}
+
+// check records the error of an attempt, returning an error wrapping ErrAgentUnavailable
+// once max consecutive attempts failed with the same error.
+func (e *identicalErrors) check(err error, max int) error {
+	if err == nil {
+		*e = identicalErrors{}
+		return nil
+	}
+
+	category := errorCategory(err)
+	if category != e.category {
+		*e = identicalErrors{category: category}
+	}
+	e.count++
+	if max > 0 && e.count >= max {
+		return fmt.Errorf("%w: %d consecutive attempts failed with: %w", ErrAgentUnavailable, e.count, err)
+	}
+	return err
+}
+
+// errorCategory returns what tells errors of different causes apart: the status code of the
+// errors of LLM providers, the kind of network errors, or else the message.
+func errorCategory(err error) string {
+	var openAIErr *openai.Error
+	if errors.As(err, &openAIErr) {
+		return fmt.Sprintf("openai %d", openAIErr.StatusCode)
+	}
+	var anthropicErr *AnthropicError
+	if errors.As(err, &anthropicErr) {
+		return fmt.Sprintf("anthropic %d %s", anthropicErr.StatusCode, anthropicErr.Type)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return "network"
+	}
+	return err.Error()
+}
+
+// generate asks the testing agent for the next message or its verdict on the conversation,
+// retrying with the retry policy.
+func (s *scenario) generate(ctx context.Context, strategy string, conversation []Message, firstMessage, lastMessage bool) (*string, *Result, error) {
+	var message *string
+	var result *Result
+	err := s.retry(ctx, func() error {
+		var err error
+		message, result, err = s.testingAgent.GenerateNextMessage(ctx, s.description, strategy, s.runSuccessCriteria(), s.runFailureCriteria(), conversation, firstMessage, lastMessage)
+		return err
+	})
+
+	return message, result, err
+}
@@ -0,0 +1,184 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_RetryPolicy(t *testing.T) {
+	rateLimited := &AnthropicError{StatusCode: http.StatusTooManyRequests, Type: "rate_limit_error", Message: "slow down"}
+	agentCalls := 0
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			agentCalls++
+			if agentCalls == 1 {
+				return nil, fmt.Errorf("agent failed: %w", rateLimited)
+			}
+			return []Message{{Role: MessageRoleAssistant, Content: "hello"}}, nil
+		},
+	}
+	judgeCalls := 0
+	testingAgent := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			judgeCalls++
+			if firstMessage {
+				msg := "hi"
+				return &msg, nil, nil
+			}
+			if judgeCalls < 4 {
+				return nil, nil, rateLimited
+			}
+			return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(testingAgent),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 3, Backoff: ExponentialBackoff(time.Millisecond, 2*time.Millisecond)}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 2, agentCalls)
+	assert.Equal(t, 4, judgeCalls)
+	require.Len(t, result.Diagnostics, 3)
+	for _, diagnostic := range result.Diagnostics {
+		assert.Equal(t, DiagnosticCallRetried, diagnostic.Code)
+	}
+	assert.Equal(t, ComponentAgent, result.Diagnostics[0].Component)
+	assert.Equal(t, ComponentSimulator, result.Diagnostics[1].Component)
+	assert.Contains(t, result.Diagnostics[2].Message, "attempt 3 of 3")
+}
+
+func TestScenario_Run_RetryPolicyExhausted(t *testing.T) {
+	errNotRetryable := errors.New("invalid request")
+	tests := []struct {
+		name  string
+		err   error
+		calls int
+	}{
+		{"transient", &openai.Error{StatusCode: http.StatusServiceUnavailable}, 2},
+		{"not retryable", errNotRetryable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, err := NewScenario(
+				WithAgent(&mockAgent{
+					runFunc: func(ctx context.Context, message string) ([]Message, error) {
+						calls++
+						return nil, tt.err
+					},
+				}),
+				WithTestingAgent(&mockTestingAgent{}),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 2}),
+			).Run(context.Background())
+
+			require.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.calls, calls)
+		})
+	}
+}
+
+func TestScenario_Run_RetryPolicyRetryableErrors(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	calls := 0
+	_, err := NewScenario(
+		WithAgent(&mockAgent{
+			runFunc: func(ctx context.Context, message string) ([]Message, error) {
+				calls++
+				if calls == 1 {
+					return nil, errFlaky
+				}
+				return []Message{{Role: MessageRoleAssistant, Content: "hello"}}, nil
+			},
+		}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts: 2,
+			RetryableErrors: func(err error) bool {
+				return errors.Is(err, errFlaky)
+			},
+		}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestScenario_Run_RetryPolicyIdenticalAgentErrors(t *testing.T) {
+	serverErr := func(status int) error {
+		return &AnthropicError{StatusCode: status, Type: "api_error", Message: "request " + strconv.Itoa(status)}
+	}
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "same error",
+			errs:      []error{serverErr(http.StatusBadGateway), serverErr(http.StatusBadGateway), serverErr(http.StatusBadGateway)},
+			wantCalls: 3,
+			wantErr:   ErrAgentUnavailable,
+		},
+		{
+			name:      "different errors",
+			errs:      []error{serverErr(http.StatusBadGateway), serverErr(http.StatusServiceUnavailable), serverErr(http.StatusBadGateway), serverErr(http.StatusServiceUnavailable), serverErr(http.StatusBadGateway)},
+			wantCalls: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			agent := &mockAgent{
+				runFunc: func(ctx context.Context, message string) ([]Message, error) {
+					calls++
+					return nil, tt.errs[(calls-1)%len(tt.errs)]
+				},
+			}
+
+			_, err := NewScenario(
+				WithAgent(agent),
+				WithTestingAgent(&mockTestingAgent{}),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 5, MaxIdenticalAgentErrors: 3}),
+			).Run(context.Background())
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NotErrorIs(t, err, ErrAgentUnavailable)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	assert.Equal(t, 100*time.Millisecond, backoff(1))
+	assert.Equal(t, 200*time.Millisecond, backoff(2))
+	assert.Equal(t, 800*time.Millisecond, backoff(4))
+	assert.Equal(t, time.Second, backoff(5))
+	assert.Equal(t, time.Second, backoff(50))
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, IsTransientError(&openai.Error{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, IsTransientError(&openai.Error{StatusCode: http.StatusBadRequest}))
+	assert.True(t, IsTransientError(fmt.Errorf("wrapped: %w", &AnthropicError{StatusCode: http.StatusBadGateway})))
+	assert.False(t, IsTransientError(&AnthropicError{StatusCode: http.StatusUnauthorized}))
+	assert.False(t, IsTransientError(fmt.Errorf("%w: example.com", ErrDestinationNotAllowed)))
+	assert.False(t, IsTransientError(errors.New("invalid request")))
+}
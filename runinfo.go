@@ -0,0 +1,136 @@
+package scenario
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RunInfo is the metadata of the scenario run a context belongs to, see RunInfoFromContext.
+type RunInfo struct {
+	// RunID is the unique identifier of the run, see Result.RunID.
+	RunID string
+
+	// ScenarioID is the identifier of the scenario, see Result.ScenarioID.
+	ScenarioID string
+
+	// Turn is the zero-based turn of the run the call is made at.
+	Turn int
+
+	// Component is the component of the run the call is made for, e.g. ComponentAgent.
+	Component Component
+
+	// Deadline is when the run is cancelled, set with WithHardTimeout or the deadline of the
+	// context of the run, zero when there is none.
+	Deadline time.Time
+
+	// SoftDeadline is when the run wraps up to a verdict, set with WithSoftDeadline, zero when
+	// there is none.
+	SoftDeadline time.Time
+}
+
+// runInfo is the metadata of a run carried by its context.
+type runInfo struct {
+	runID        string
+	scenarioID   string
+	softDeadline time.Time
+
+	// textVerdictFallback is set once the testing agent falls back to the text verdict
+	// protocol, so the rest of the run skips the rejected tool call
+	textVerdictFallback *atomic.Bool
+}
+
+type runInfoContextKey struct{}
+
+// withRunInfo returns a context carrying the metadata of the run.
+func withRunInfo(ctx context.Context, info runInfo) context.Context {
+	return context.WithValue(ctx, runInfoContextKey{}, info)
+}
+
+// RunInfoFromContext returns the metadata of the scenario run of the context, e.g. within
+// Agent.Run to tag the telemetry of the agent with the run and turn. It returns false outside
+// of a scenario run.
+func RunInfoFromContext(ctx context.Context) (RunInfo, bool) {
+	run, ok := ctx.Value(runInfoContextKey{}).(runInfo)
+	if !ok {
+		return RunInfo{}, false
+	}
+
+	info := RunInfo{
+		RunID:        run.runID,
+		ScenarioID:   run.scenarioID,
+		SoftDeadline: run.softDeadline,
+	}
+	if scope, ok := ctx.Value(auditScopeContextKey{}).(auditScope); ok {
+		info.Turn = scope.turn
+		info.Component = scope.component
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		info.Deadline = deadline
+	}
+	return info, true
+}
+
+// Headers propagating the metadata of a scenario run to the agent, see SetRunHeaders.
+const (
+	// HeaderRunID is the header of the run ID, see RunInfo.RunID.
+	HeaderRunID = "X-Scenario-Run-Id"
+
+	// HeaderScenarioID is the header of the scenario ID, see RunInfo.ScenarioID.
+	HeaderScenarioID = "X-Scenario-Id"
+
+	// HeaderTurn is the header of the zero-based turn, see RunInfo.Turn.
+	HeaderTurn = "X-Scenario-Turn"
+)
+
+// Metadata returns the run ID, the scenario ID and the turn of the run keyed by their header
+// names in lower case, e.g. to propagate them as gRPC metadata with metadata.New.
+func (i RunInfo) Metadata() map[string]string {
+	return map[string]string{
+		strings.ToLower(HeaderRunID):      i.RunID,
+		strings.ToLower(HeaderScenarioID): i.ScenarioID,
+		strings.ToLower(HeaderTurn):       strconv.Itoa(i.Turn),
+	}
+}
+
+// SetRunHeaders sets the run ID, the scenario ID and the turn of the scenario run of the
+// context as headers of a request to the agent, so the logs and traces of the agent can be
+// correlated with the run. It returns false, leaving the headers unchanged, outside of a
+// scenario run.
+func SetRunHeaders(ctx context.Context, header http.Header) bool {
+	info, ok := RunInfoFromContext(ctx)
+	if !ok {
+		return false
+	}
+	header.Set(HeaderRunID, info.RunID)
+	header.Set(HeaderScenarioID, info.ScenarioID)
+	header.Set(HeaderTurn, strconv.Itoa(info.Turn))
+	return true
+}
+
+// runHeadersTransport is an http.RoundTripper setting the run headers on every request.
+type runHeadersTransport struct {
+	base http.RoundTripper
+}
+
+// NewRunHeadersTransport returns a transport setting the run headers of SetRunHeaders on every
+// request made within a scenario run before sending it with base, http.DefaultTransport when
+// nil, e.g. as the transport of the HTTP client of an agent calling its service.
+func NewRunHeadersTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &runHeadersTransport{base: base}
+}
+
+func (t *runHeadersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := RunInfoFromContext(req.Context()); ok {
+		// A RoundTripper must not modify the request it is given
+		req = req.Clone(req.Context())
+		SetRunHeaders(req.Context(), req.Header)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,119 @@
+package scenario
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInfoFromContext(t *testing.T) {
+	_, ok := RunInfoFromContext(context.Background())
+	assert.False(t, ok, "no run info outside of a scenario run")
+
+	var infos []RunInfo
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			info, ok := RunInfoFromContext(ctx)
+			require.True(t, ok)
+			infos = append(infos, info)
+			return []Message{{Role: MessageRoleAssistant, Content: "hello"}}, nil
+		},
+	}
+
+	start := time.Now()
+	result, err := NewScenario(
+		WithID("support/greeting"),
+		WithAgent(agent),
+		WithTestingAgent(chattyTestingAgent()),
+		WithMaxTurns(2),
+		WithHardTimeout(time.Minute),
+		WithSoftDeadline(30*time.Second, time.Second),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	for turn, info := range infos {
+		assert.Equal(t, result.RunID, info.RunID)
+		assert.Equal(t, "support/greeting", info.ScenarioID)
+		assert.Equal(t, turn, info.Turn)
+		assert.Equal(t, ComponentAgent, info.Component)
+		assert.WithinDuration(t, start.Add(time.Minute), info.Deadline, time.Second)
+		assert.WithinDuration(t, start.Add(30*time.Second), info.SoftDeadline, time.Second)
+	}
+}
+
+func TestRunInfoFromContext_NoDeadline(t *testing.T) {
+	var info RunInfo
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			info, _ = RunInfoFromContext(ctx)
+			return []Message{{Role: MessageRoleAssistant, Content: "hello"}}, nil
+		},
+	}
+
+	_, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, info.RunID)
+	assert.True(t, info.Deadline.IsZero())
+	assert.True(t, info.SoftDeadline.IsZero())
+}
+
+func TestNewRunHeadersTransport(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Clone())
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewRunHeadersTransport(nil)}
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Empty(t, req.Header, "the request of the agent is not modified")
+			return []Message{{Role: MessageRoleAssistant, Content: "hello"}}, nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithID("support/greeting"),
+		WithAgent(agent),
+		WithTestingAgent(chattyTestingAgent()),
+		WithMaxTurns(2),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, headers, 2)
+	for turn, header := range headers {
+		assert.Equal(t, result.RunID, header.Get(HeaderRunID))
+		assert.Equal(t, "support/greeting", header.Get(HeaderScenarioID))
+		assert.Equal(t, strconv.Itoa(turn), header.Get(HeaderTurn))
+	}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, headers[2].Get(HeaderRunID), "no headers outside of a scenario run")
+}
+
+func TestRunInfo_Metadata(t *testing.T) {
+	info := RunInfo{RunID: "01J", ScenarioID: "refund", Turn: 3}
+
+	assert.Equal(t, map[string]string{
+		"x-scenario-run-id": "01J",
+		"x-scenario-id":     "refund",
+		"x-scenario-turn":   "3",
+	}, info.Metadata())
+}
@@ -0,0 +1,49 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// RunT runs the scenario built from the options in a test, failing the test when the run
+// returns an error, or when the scenario fails, after logging the details of the result with
+// the transcript of the conversation. It returns the result for further assertions.
+//
+//	func TestRefunds(t *testing.T) {
+//		scenario.RunT(t,
+//			scenario.WithDescription("User asks for a refund of a late order"),
+//			scenario.WithAgent(&refundAgent{}),
+//			scenario.WithTestingAgent(scenario.NewTestingAgent(scenario.NewOpenAICompletion("gpt-4o-mini"))),
+//			scenario.WithSuccessCriteria("Agent refunds the order"),
+//		)
+//	}
+func RunT(t *testing.T, opts ...ScenarioOption) *Result {
+	t.Helper()
+
+	result, err := NewScenario(opts...).Run(t.Context())
+	if err != nil {
+		t.Fatalf("scenario failed to run: %v", err)
+	}
+	if !result.Success {
+		result.LogResultDetails(t)
+		t.Errorf("scenario failed: %s\n\n%s", result.Reasoning, transcript(result.Conversation))
+	}
+
+	return result
+}
+
+// transcript renders the conversation message by message, with the tool calls of the agent.
+func transcript(conversation []Message) string {
+	var sb strings.Builder
+	for _, message := range conversation {
+		fmt.Fprintf(&sb, "%s: %s\n", message.Role, message.Content)
+		for _, toolCall := range message.ToolCalls {
+			if toolCall.Function != nil {
+				fmt.Fprintf(&sb, "  -> %s(%v)\n", toolCall.Function.Name, toolCall.Function.Arguments)
+			}
+		}
+	}
+
+	return sb.String()
+}
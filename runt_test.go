@@ -0,0 +1,33 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunT(t *testing.T) {
+	result := RunT(t,
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+
+	assert.True(t, result.Success)
+	assert.Len(t, result.Conversation, 2)
+}
+
+func TestTranscript(t *testing.T) {
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{
+			Type:     ToolTypeFunction,
+			Function: &ToolCallFunction{Name: "track_order", Arguments: map[string]any{"id": "42"}},
+		}}},
+		{Role: MessageRoleAssistant, Content: "It ships tomorrow."},
+	}
+
+	assert.Equal(t, "user: where is my order?\n"+
+		"assistant: \n"+
+		"  -> track_order(map[id:42])\n"+
+		"assistant: It ships tomorrow.\n", transcript(conversation))
+}
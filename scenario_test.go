@@ -135,6 +135,29 @@ func TestScenario_Run_MaxTurns(t *testing.T) {
 	assert.Len(t, result.Conversation, maxTurns*2) // User msg + Agent response per turn
 }
 
+func TestScenario_Run_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	turns := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			turns++
+			cancel()
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+		},
+	}
+
+	_, err := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(chattyTestingAgent()),
+		WithMaxTurns(3),
+	).Run(ctx)
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "run cancelled before turn 1: context canceled")
+	assert.Equal(t, 1, turns, "the cancellation is noticed at the next turn")
+}
+
 // TestScenario_Run_Failure tests a scenario run that ends in failure.
 func TestScenario_Run_Failure(t *testing.T) {
 	ctx := context.Background()
@@ -335,3 +358,618 @@ func TestScenario_Run_AgentReturnsNoMessages(t *testing.T) {
 	// Conversation should contain only the initial user message
 	require.Len(t, result.Conversation, 0)
 }
+
+// mockEventAgent is a mock implementation of the EventAgent interface.
+type mockEventAgent struct {
+	mockAgent
+	events []Message
+}
+
+func (m *mockEventAgent) HandleEvent(ctx context.Context, event Message) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// TestScenario_Run_EventAt tests that injected events reach the agent and the conversation.
+func TestScenario_Run_EventAt(t *testing.T) {
+	ctx := context.Background()
+	agent := &mockEventAgent{}
+	event := Message{Role: MessageRoleSystem, Content: "human agent joined"}
+	var judgedConversation []Message
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "Initial user message"
+				return &msg, nil, nil
+			}
+			judgedConversation = conversation
+			return nil, NewSuccessPartialResult(conversation, "Test succeeded", []string{}), nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(mockTestingAgentInst),
+		WithEventAt(1, event),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, []Message{event}, agent.events)
+	require.Len(t, judgedConversation, 3)
+	assert.Equal(t, event, judgedConversation[2])
+}
+
+// mockResettableAgent is a mock implementation of the ResettableAgent interface.
+type mockResettableAgent struct {
+	mockAgent
+	resetErr   error
+	resetCalls int
+}
+
+func (m *mockResettableAgent) Reset(ctx context.Context) error {
+	m.resetCalls++
+	return m.resetErr
+}
+
+// TestScenario_Run_ResetsAgent tests that resettable agents are reset before every run.
+func TestScenario_Run_ResetsAgent(t *testing.T) {
+	ctx := context.Background()
+	agent := &mockResettableAgent{}
+
+	s := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+
+	_, err := s.Run(ctx)
+	require.NoError(t, err)
+	_, err = s.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, agent.resetCalls)
+
+	resetErr := errors.New("reset failed")
+	agent.resetErr = resetErr
+	result, err := s.Run(ctx)
+	require.ErrorIs(t, err, resetErr)
+	require.ErrorContains(t, err, "failed to reset agent:")
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+}
+
+// mockFixtureLoader is a mock implementation of the FixtureLoader interface.
+type mockFixtureLoader struct {
+	mockResettableAgent
+	fixtures map[string]any
+	calls    []string
+}
+
+func (m *mockFixtureLoader) Reset(ctx context.Context) error {
+	m.calls = append(m.calls, "reset")
+	return nil
+}
+
+func (m *mockFixtureLoader) LoadFixtures(ctx context.Context, fixtures map[string]any) error {
+	m.calls = append(m.calls, "load")
+	m.fixtures = fixtures
+	return nil
+}
+
+// TestScenario_Run_Fixtures tests that fixtures are loaded into the agent after it is reset.
+func TestScenario_Run_Fixtures(t *testing.T) {
+	ctx := context.Background()
+	agent := &mockFixtureLoader{}
+	orders := []map[string]any{{"id": "A-1", "status": "shipped"}}
+
+	s := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithFixture("account", map[string]any{"email": "jane@example.com"}),
+		WithFixture("orders", orders),
+	)
+
+	_, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reset", "load"}, agent.calls)
+	assert.Equal(t, map[string]any{
+		"account": map[string]any{"email": "jane@example.com"},
+		"orders":  orders,
+	}, agent.fixtures)
+
+	s = NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithFixture("orders", orders),
+	)
+	_, err = s.Run(ctx)
+	require.EqualError(t, err, "fixtures declared but agent does not implement FixtureLoader")
+}
+
+// TestScenario_Run_Seed tests that the seed of the run is recorded on the result.
+func TestScenario_Run_Seed(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithSeed(42),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, int64(42), result.Seed)
+}
+
+// TestScenario_Run_FailureAssertions tests that failure assertions fail the scenario without judging.
+func TestScenario_Run_FailureAssertions(t *testing.T) {
+	ctx := context.Background()
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "Initial user message"
+				return &msg, nil, nil
+			}
+			t.Fatal("GenerateNextMessage should not be called after a failure assertion matched")
+			return nil, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithFailureAssertions(Contains("Agent response")),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{`a message contains "Agent response"`}, result.TriggeredFailures)
+	assert.Len(t, result.Conversation, 2)
+}
+
+// TestScenario_Run_LanguageCheck tests that a reply in another language than the user fails the scenario.
+func TestScenario_Run_LanguageCheck(t *testing.T) {
+	ctx := context.Background()
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			msg := "hola, quiero una receta de cena"
+			return &msg, nil, nil
+		},
+	}
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, Content: "Here is a recipe for you, it is made with the best vegetables."}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithFailureAssertions(Contains("meat")),
+		WithLanguageCheck(),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{"agent replied in a different language than the user"}, result.TriggeredFailures)
+}
+
+// TestScenario_Run_ForbiddenTools tests that calling a forbidden or not allowed tool fails the scenario.
+func TestScenario_Run_ForbiddenTools(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, ToolCalls: []ToolCall{
+				{Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "lookup_order"}},
+				{Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "send_email"}},
+			}}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithAllowedTools("lookup_order", "search"),
+		WithForbiddenTools("send_email", "delete_account"),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{
+		`a tool outside of the allowed tools ["lookup_order" "search"] was called`,
+		`forbidden tool "send_email" was called`,
+	}, result.TriggeredFailures)
+}
+
+// TestScenario_Run_NoAllowedTools tests that no tool may be called when no tools are allowed.
+func TestScenario_Run_NoAllowedTools(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, ToolCalls: []ToolCall{
+				{Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "lookup_order"}},
+			}}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithAllowedTools(),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{`a tool outside of the allowed tools [] was called`}, result.TriggeredFailures)
+}
+
+// TestScenario_Run_JudgeProgress tests that the progress of the testing agent calls is reported with their turn.
+func TestScenario_Run_JudgeProgress(t *testing.T) {
+	ctx := context.Background()
+	turn := 0
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			ReportCompletionProgress(ctx, 5, true)
+			if turn++; turn > 2 {
+				return nil, NewSuccessPartialResult(conversation, "Done", []string{}), nil
+			}
+			msg := "User message"
+			return &msg, nil, nil
+		},
+	}
+
+	var progress []JudgeProgress
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithJudgeProgress(func(p JudgeProgress) {
+			progress = append(progress, p)
+		}),
+	)
+
+	_, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, []JudgeProgress{
+		{Turn: 0, Tokens: 5, Done: true},
+		{Turn: 1, Tokens: 5, Done: true},
+		{Turn: 2, Tokens: 5, Done: true},
+	}, progress)
+}
+
+// TestScenario_Run_SecondOpinion tests that low confidence verdicts are replaced by the verdict of the second opinion judge.
+func TestScenario_Run_SecondOpinion(t *testing.T) {
+	ctx := context.Background()
+	verdict := func(confidence float64) *mockTestingAgent {
+		return &mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				if firstMessage {
+					msg := "Initial user message"
+					return &msg, nil, nil
+				}
+				result := NewFailurePartialResult(conversation, "Borderline", []string{}, []string{"criterion"}, []string{})
+				result.Confidence = &confidence
+				return nil, result, nil
+			},
+		}
+	}
+	judge := &mockTestingAgent{}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(verdict(0.4)),
+		WithSecondOpinion(judge, 0.5),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Success, "the verdict of the judge is used")
+	require.NotNil(t, result.FirstOpinion)
+	assert.False(t, result.FirstOpinion.Success)
+	assert.Equal(t, "Borderline", result.FirstOpinion.Reasoning)
+
+	s = NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(verdict(0.9)),
+		WithSecondOpinion(judge, 0.5),
+	)
+
+	result, err = s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Nil(t, result.FirstOpinion)
+}
+
+// TestScenario_Run_SuccessAssertions tests that unmet success assertions fail a successful verdict.
+func TestScenario_Run_SuccessAssertions(t *testing.T) {
+	ctx := context.Background()
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithSuccessAssertions(Contains("Agent response"), ToolCalled("refund").AtLeast(1)),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.MetCriteria, `a message contains "Agent response"`)
+	assert.Equal(t, []string{`at least 1 calls to tool "refund"`}, result.UnmetCriteria)
+}
+
+// TestScenario_Run_VerdictPostProcessor tests that post-processors adjust the result in order.
+func TestScenario_Run_VerdictPostProcessor(t *testing.T) {
+	ctx := context.Background()
+	flaky := `at least 1 calls to tool "refund"`
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithSuccessAssertions(ToolCalled("refund").AtLeast(1)),
+		WithVerdictPostProcessor(func(result *Result) *Result {
+			if !result.Success && len(result.UnmetCriteria) == 1 && result.UnmetCriteria[0] == flaky && len(result.TriggeredFailures) == 0 {
+				result.Success = true
+				result.UnmetCriteria = []string{}
+				result.Reasoning += " Ignored the flaky criterion."
+			}
+			return result
+		}),
+		WithVerdictPostProcessor(func(result *Result) *Result {
+			result.Tags = append(result.Tags, "post-processed")
+			return result
+		}),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Empty(t, result.UnmetCriteria)
+	assert.Contains(t, result.Reasoning, "Ignored the flaky criterion.")
+	assert.Equal(t, []string{"post-processed"}, result.Tags)
+	assert.NotEmpty(t, result.RunID, "the run metadata is filled in before post-processing")
+}
+
+// TestScenario_Run_FinalAnswerFormat tests that format validators check the final answer of the agent.
+func TestScenario_Run_FinalAnswerFormat(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, Content: `{"name": "salad"}`}}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithFinalAnswerFormat(ValidJSON(), MaxLength(5)),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.MetCriteria, "final answer is valid JSON")
+	assert.Equal(t, []string{"final answer is at most 5 characters long (answer is 17 characters long)"}, result.UnmetCriteria)
+}
+
+// TestScenario_Run_StopCondition tests that a matching stop condition asks for the final verdict.
+func TestScenario_Run_StopCondition(t *testing.T) {
+	ctx := context.Background()
+	var lastMessages []bool
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			lastMessages = append(lastMessages, lastMessage)
+			if lastMessage {
+				return nil, NewSuccessPartialResult(conversation, "Stopped", []string{}), nil
+			}
+			msg := fmt.Sprintf("User message %d", len(lastMessages))
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithStopCondition(Role(MessageRoleAssistant).Count().AtLeast(2)),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, []bool{false, false, true}, lastMessages)
+}
+
+// TestScenario_Run_UserKnowledge tests that user knowledge reaches the testing agent with an elicitation criterion.
+func TestScenario_Run_UserKnowledge(t *testing.T) {
+	ctx := context.Background()
+	var strategies []string
+	var criteria [][]string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			strategies = append(strategies, strategy)
+			criteria = append(criteria, successCriteria)
+			if firstMessage {
+				msg := "Initial user message"
+				return &msg, nil, nil
+			}
+			return nil, NewSuccessPartialResult(conversation, "Test succeeded", successCriteria), nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithSuccessCriteria("success1"),
+		WithUserKnowledge("order number is 1234"),
+	)
+
+	_, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Contains(t, strategies[0], "<user_knowledge>\norder number is 1234\n</user_knowledge>")
+	require.Len(t, criteria[0], 2)
+	assert.Equal(t, "success1", criteria[0][0])
+	assert.Contains(t, criteria[0][1], "Agent asks the user for the information it needs")
+	assert.Equal(t, []string{"success1"}, s.(*scenario).successCriteria)
+}
+
+// TestScenario_Run_ExpectedRefusal tests that the refusal criteria reach the testing agent.
+func TestScenario_Run_ExpectedRefusal(t *testing.T) {
+	ctx := context.Background()
+	var strategies []string
+	var successCriteriaCalls, failureCriteriaCalls [][]string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			strategies = append(strategies, strategy)
+			successCriteriaCalls = append(successCriteriaCalls, successCriteria)
+			failureCriteriaCalls = append(failureCriteriaCalls, failureCriteria)
+			if firstMessage {
+				msg := "Initial user message"
+				return &msg, nil, nil
+			}
+			return nil, NewSuccessPartialResult(conversation, "Test succeeded", successCriteria), nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(mockTestingAgentInst),
+		WithFailureCriteria("failure1"),
+		WithExpectedRefusal("medical diagnosis is out of scope"),
+	)
+
+	_, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Contains(t, strategies[0], "The agent is expected to refuse this request: medical diagnosis is out of scope")
+	assert.Contains(t, successCriteriaCalls[0], "Agent declines the request (medical diagnosis is out of scope)")
+	assert.Contains(t, successCriteriaCalls[0], "Agent offers an alternative")
+	assert.Equal(t, []string{"failure1", "Agent complies with the request it should refuse"}, failureCriteriaCalls[0])
+}
+
+func TestScenario_Run_InitialConversation(t *testing.T) {
+	ctx := context.Background()
+	initial := []Message{
+		{Role: MessageRoleUser, Content: "I'd like to open an account"},
+		{Role: MessageRoleAssistant, Content: "Done, your account is open."},
+	}
+	agent := &mockHistoryAgent{}
+	var judged []Message
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				judged = append([]Message{}, conversation...)
+				msg := "How do I add a card?"
+				return &msg, nil, nil
+			}
+			return nil, &Result{Success: true, Conversation: conversation}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(mockTestingAgentInst),
+		WithInitialConversation(initial...),
+	)
+
+	for range 2 {
+		result, err := s.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, initial, agent.history)
+		assert.Equal(t, initial, judged)
+		assert.Len(t, result.Conversation, 4, "runs do not accumulate the conversations of previous runs")
+	}
+}
+
+func TestScenario_Run_InitialConversation_RequiresHistoryAgent(t *testing.T) {
+	s := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+		WithInitialConversation(Message{Role: MessageRoleUser, Content: "hi"}),
+	)
+
+	_, err := s.Run(context.Background())
+
+	assert.EqualError(t, err, "initial conversation set but agent does not implement HistoryAgent")
+}
+
+// TestScenario_Run_ToolCallsKeptInResult tests that the judged result keeps the tool calls of the agent.
+func TestScenario_Run_ToolCallsKeptInResult(t *testing.T) {
+	ctx := context.Background()
+	agent := &mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+		return []Message{
+			{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{
+				ID:       "call_1",
+				Type:     ToolTypeFunction,
+				Function: &ToolCallFunction{Name: "refund", Arguments: map[string]any{"order_id": "42"}},
+			}}},
+			{Role: MessageRoleTool, ToolCallID: "call_1", Content: "refunded"},
+			{Role: MessageRoleAssistant, Content: "Your order was refunded."},
+		}, nil
+	}}
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			if toolChoice == nil {
+				return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+					Message: LLMCompletionResponseChoiceMessage{Content: "refund my order 42"},
+				}}}, nil
+			}
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{ToolCalls: []ToolCall{{
+					Type: ToolTypeFunction,
+					Function: &ToolCallFunction{Name: "finish_test", Arguments: map[string]any{
+						"verdict":   "success",
+						"reasoning": "refunded",
+						"details": map[string]any{
+							"met_criteria":       []any{"the agent refunds the order"},
+							"unmet_criteria":     []any{},
+							"triggered_failures": []any{},
+						},
+					}},
+				}}},
+			}}}, nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(NewTestingAgent(mockLLM)),
+		WithSuccessCriteria("the agent refunds the order"),
+		WithSuccessAssertions(ToolCalled("refund").AtLeast(1)),
+		WithMaxTurns(1),
+	).Run(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, result.Success, "unmet criteria: %v", result.UnmetCriteria)
+	require.Len(t, result.Conversation, 4)
+	require.Len(t, result.Conversation[1].ToolCalls, 1)
+	assert.Equal(t, "refund", result.Conversation[1].ToolCalls[0].Function.Name)
+	assert.Equal(t, MessageRoleTool, result.Conversation[2].Role)
+	assert.Equal(t, "refunded", result.Conversation[2].Content)
+}
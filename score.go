@@ -0,0 +1,74 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// applyCriteriaScores scores the success criteria of the verdict of the testing agent with
+// their weights, passing or failing the result on the weighted score when a passing score is
+// set with WithPassingScore. Criteria are matched to the ones listed in the verdict with
+// criterionKey. Criteria the verdict lists neither as met nor as unmet are recorded as a
+// diagnostic, and a score lowered by them does not fail a successful verdict.
+func (s *scenario) applyCriteriaScores(ctx context.Context, result *Result) {
+	met := criterionKeys(result.MetCriteria)
+	unmet := criterionKeys(result.UnmetCriteria)
+
+	criteria := s.runSuccessCriteria()
+	result.CriteriaScores = make(map[string]float64, len(criteria))
+	var score, total float64
+	var unmatched []string
+	for i, criterion := range criteria {
+		weight := 1.0
+		if i < len(s.successWeights) {
+			weight = s.successWeights[i]
+		}
+		total += weight
+		result.CriteriaScores[criterion] = 0
+		switch key := criterionKey(criterion); {
+		case met[key]:
+			result.CriteriaScores[criterion] = 1
+			score += weight
+		case !unmet[key]:
+			unmatched = append(unmatched, criterion)
+		}
+	}
+
+	result.Score = 1
+	if total > 0 {
+		result.Score = score / total
+	}
+	if len(unmatched) > 0 {
+		RecordDiagnostic(withAuditScope(ctx, ComponentJudge, len(s.turns)), Diagnostic{
+			Code:    DiagnosticCriteriaUnmatched,
+			Message: fmt.Sprintf("the verdict lists none of %q as met or unmet, scored 0", unmatched),
+		})
+	}
+	if s.passingScore == nil {
+		return
+	}
+
+	success := len(result.TriggeredFailures) == 0 && result.Score >= *s.passingScore
+	if result.Success && !success && len(unmatched) > 0 && len(result.TriggeredFailures) == 0 {
+		// The score is not reliable enough to overturn the verdict
+		return
+	}
+	result.Success = success
+}
+
+// criterionKey normalizes a criterion to match the criteria echoed by the testing agent, which
+// may change their case, whitespace or trailing punctuation.
+func criterionKey(criterion string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(criterion), " "))
+	return strings.TrimRight(key, ".!")
+}
+
+// criterionKeys returns the set of the keys of the criteria.
+func criterionKeys(criteria []string) map[string]bool {
+	keys := make(map[string]bool, len(criteria))
+	for _, criterion := range criteria {
+		keys[criterionKey(criterion)] = true
+	}
+	return keys
+}
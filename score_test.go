@@ -0,0 +1,115 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verdictTestingAgent is a testing agent giving a failed verdict with the met and unmet
+// criteria after the first turn.
+func verdictTestingAgent(met, unmet, triggered []string) *mockTestingAgent {
+	return &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "hi"
+				return &msg, nil, nil
+			}
+			return nil, NewFailurePartialResult(conversation, "some criteria unmet", met, unmet, triggered), nil
+		},
+	}
+}
+
+func TestScenario_Run_WeightedSuccessCriteria(t *testing.T) {
+	criteria := map[string]float64{
+		"Agent greets the user":  1,
+		"Agent answers the user": 3,
+		"Agent says goodbye":     1,
+	}
+
+	for _, tc := range []struct {
+		name         string
+		passingScore float64
+		triggered    []string
+		success      bool
+	}{
+		{name: "above the passing score", passingScore: 0.8, success: true},
+		{name: "below the passing score", passingScore: 0.9, success: false},
+		{name: "triggered failure", passingScore: 0.5, triggered: []string{"Agent is rude"}, success: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := NewScenario(
+				WithAgent(&mockAgent{}),
+				WithTestingAgent(verdictTestingAgent(
+					[]string{"Agent greets the user", "Agent answers the user"},
+					[]string{"Agent says goodbye"},
+					tc.triggered,
+				)),
+				WithWeightedSuccessCriteria(criteria),
+				WithPassingScore(tc.passingScore),
+			).Run(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.success, result.Success)
+			assert.InDelta(t, 0.8, result.Score, 1e-9)
+			assert.Equal(t, map[string]float64{
+				"Agent greets the user":  1,
+				"Agent answers the user": 1,
+				"Agent says goodbye":     0,
+			}, result.CriteriaScores)
+		})
+	}
+}
+
+func TestScenario_Run_CriteriaScoresWithoutWeights(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(verdictTestingAgent([]string{"a"}, []string{"b"}, nil)),
+		WithSuccessCriteria("a", "b"),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, result.Success, "all criteria are required without a passing score")
+	assert.InDelta(t, 0.5, result.Score, 1e-9)
+	assert.Equal(t, map[string]float64{"a": 1, "b": 0}, result.CriteriaScores)
+}
+
+func TestScenario_Run_CriteriaScoresNormalized(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(verdictTestingAgent([]string{"  agent greets the  user."}, []string{"Agent says goodbye"}, nil)),
+		WithSuccessCriteria("Agent greets the user", "Agent says goodbye"),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"Agent greets the user": 1, "Agent says goodbye": 0}, result.CriteriaScores)
+	assert.Empty(t, result.Diagnostics)
+}
+
+func TestScenario_Run_CriteriaScoresUnmatched(t *testing.T) {
+	testingAgent := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "hi"
+				return &msg, nil, nil
+			}
+			return nil, NewSuccessPartialResult(conversation, "all good", []string{"Agent greets the user", "The agent answered"}), nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(testingAgent),
+		WithWeightedSuccessCriteria(map[string]float64{"Agent greets the user": 1, "Agent answers the user": 3}),
+		WithPassingScore(0.8),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success, "a score lowered by unmatched criteria does not fail a passing verdict")
+	assert.InDelta(t, 0.25, result.Score, 1e-9)
+	require.Len(t, result.Diagnostics, 1)
+	assert.Equal(t, DiagnosticCriteriaUnmatched, result.Diagnostics[0].Code)
+	assert.Contains(t, result.Diagnostics[0].Message, `"Agent answers the user"`)
+}
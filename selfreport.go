@@ -0,0 +1,68 @@
+package scenario
+
+// UserSelfReport is the assessment of the conversation by the simulated user, from their own
+// perspective, separate from the criteria-based verdict, see WithUserSelfReport.
+type UserSelfReport struct {
+	// GoalAchieved is whether the user got what they needed.
+	GoalAchieved bool `json:"goal_achieved"`
+
+	// Satisfaction is the satisfaction of the user, from 1 (very dissatisfied) to 5 (very
+	// satisfied).
+	Satisfaction int `json:"satisfaction"`
+
+	// Comment is the user's own words about the conversation.
+	Comment string `json:"comment"`
+}
+
+// selfReportProperty returns the JSON schema of the self-report in the verdict tool parameters.
+func selfReportProperty() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"goal_achieved": map[string]any{
+				"type":        "boolean",
+				"description": "Whether you, as the user, got what you needed",
+			},
+			"satisfaction": map[string]any{
+				"type":        "integer",
+				"description": "Your satisfaction as the user, from 1 (very dissatisfied) to 5 (very satisfied)",
+			},
+			"comment": map[string]any{
+				"type":        "string",
+				"description": "A short comment on the conversation, in your own words as the user",
+			},
+		},
+		"required":             []string{"goal_achieved", "satisfaction", "comment"},
+		"additionalProperties": false,
+		"description":          "Your own assessment as the user, regardless of the criteria",
+	}
+}
+
+// withSelfReport adds the self-report to the parameters of the verdict tool.
+func withSelfReport(tool Tool) Tool {
+	parameters := tool.Function.Parameters
+	if properties, ok := parameters["properties"].(map[string]any); ok {
+		properties["user_self_report"] = selfReportProperty()
+	}
+	if required, ok := parameters["required"].([]string); ok && tool.Function.Strict {
+		parameters["required"] = append(required, "user_self_report")
+	}
+	return tool
+}
+
+// extractSelfReport returns the self-report of the verdict arguments, nil when missing.
+func extractSelfReport(args map[string]any) *UserSelfReport {
+	raw, ok := args["user_self_report"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	report := &UserSelfReport{}
+	report.GoalAchieved, _ = raw["goal_achieved"].(bool)
+	if satisfaction, ok := raw["satisfaction"].(float64); ok {
+		report.Satisfaction = min(max(int(satisfaction), 1), 5)
+	}
+	report.Comment, _ = raw["comment"].(string)
+
+	return report
+}
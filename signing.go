@@ -0,0 +1,243 @@
+package scenario
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+)
+
+// ErrInvalidSignature is returned when the signature of a SignedResult does not match its result.
+var ErrInvalidSignature = errors.New("invalid result signature")
+
+const (
+	// SignatureAlgorithmHMACSHA256 is the HMAC-SHA256 signature algorithm.
+	SignatureAlgorithmHMACSHA256 = "hmac-sha256"
+
+	// SignatureAlgorithmEd25519 is the Ed25519 signature algorithm.
+	SignatureAlgorithmEd25519 = "ed25519"
+)
+
+// SignedResult is a result with a signature over its canonical JSON encoding, a variant of
+// RFC 8785 keeping integers exact, proving the result was not tampered with after the run.
+type SignedResult struct {
+	// Result is the signed result.
+	Result *Result `json:"result"`
+
+	// Algorithm is the algorithm used to sign the result.
+	Algorithm string `json:"algorithm"`
+
+	// Signature is the base64 encoded signature of the canonical JSON encoding of the result.
+	Signature string `json:"signature"`
+}
+
+// SignResultHMAC signs the result with HMAC-SHA256 using the given secret key.
+func SignResultHMAC(result *Result, key []byte) (*SignedResult, error) {
+	payload, err := canonicalResultJSON(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedResult{
+		Result:    result,
+		Algorithm: SignatureAlgorithmHMACSHA256,
+		Signature: base64.StdEncoding.EncodeToString(hmacSHA256(payload, key)),
+	}, nil
+}
+
+// SignResultEd25519 signs the result with Ed25519 using the given private key.
+func SignResultEd25519(result *Result, key ed25519.PrivateKey) (*SignedResult, error) {
+	payload, err := canonicalResultJSON(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedResult{
+		Result:    result,
+		Algorithm: SignatureAlgorithmEd25519,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}, nil
+}
+
+// VerifyHMAC verifies an HMAC-SHA256 signed result with the given secret key.
+func (s *SignedResult) VerifyHMAC(key []byte) error {
+	payload, signature, err := s.verificationInputs(SignatureAlgorithmHMACSHA256)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(signature, hmacSHA256(payload, key)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// VerifyEd25519 verifies an Ed25519 signed result with the given public key.
+func (s *SignedResult) VerifyEd25519(key ed25519.PublicKey) error {
+	payload, signature, err := s.verificationInputs(SignatureAlgorithmEd25519)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, payload, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func (s *SignedResult) verificationInputs(algorithm string) ([]byte, []byte, error) {
+	if s.Algorithm != algorithm {
+		return nil, nil, fmt.Errorf("result is signed with %s, not %s", s.Algorithm, algorithm)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	payload, err := canonicalResultJSON(s.Result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return payload, signature, nil
+}
+
+// canonicalResultJSON returns the canonical JSON encoding of a result, see canonicalJSON.
+func canonicalResultJSON(result *Result) ([]byte, error) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	return canonicalJSON(encoded)
+}
+
+// canonicalJSON re-encodes a JSON document following the JSON Canonicalization Scheme of
+// RFC 8785: no whitespace, object keys sorted by their UTF-16 code units, minimal string
+// escaping and numbers serialized as in ECMAScript. Unlike RFC 8785, integers are kept exact
+// rather than rounded to IEEE 754 doubles, so int64 fields such as the seed are covered by the
+// signature in full.
+func canonicalJSON(encoded []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	var b bytes.Buffer
+	if err := writeCanonicalJSON(&b, value); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func writeCanonicalJSON(b *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		b.WriteString(number)
+	case string:
+		writeCanonicalString(b, v)
+	case []any:
+		b.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(b, element); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case map[string]any:
+		keys := slices.SortedFunc(maps.Keys(v), func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+		b.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonicalString(b, key)
+			b.WriteByte(':')
+			if err := writeCanonicalJSON(b, v[key]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", value)
+	}
+	return nil
+}
+
+// canonicalNumber returns the canonical serialization of a JSON number, exact for integers.
+func canonicalNumber(number json.Number) (string, error) {
+	if i, err := number.Int64(); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+	f, err := number.Float64()
+	if err != nil {
+		return "", fmt.Errorf("invalid JSON number %s: %w", number, err)
+	}
+	// encoding/json serializes floats as ECMAScript does
+	encoded, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// writeCanonicalString writes a JSON string escaping only quotes, backslashes and control
+// characters, with the short escapes where they exist.
+func writeCanonicalString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+func hmacSHA256(payload, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
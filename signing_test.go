@@ -0,0 +1,106 @@
+package scenario
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSigningTestResult() *Result {
+	return &Result{
+		Success:      true,
+		Conversation: []Message{{Role: MessageRoleUser, Content: "hi"}, {Role: MessageRoleAssistant, Content: "hello"}},
+		Reasoning:    "All criteria met",
+		MetCriteria:  []string{"Agent greets the user"},
+	}
+}
+
+func TestSignResultHMAC(t *testing.T) {
+	key := []byte("secret")
+	signed, err := SignResultHMAC(newSigningTestResult(), key)
+	require.NoError(t, err)
+	assert.Equal(t, SignatureAlgorithmHMACSHA256, signed.Algorithm)
+
+	// Signatures survive a JSON round trip
+	encoded, err := json.Marshal(signed)
+	require.NoError(t, err)
+	var envelope map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(encoded, &envelope))
+	assert.ElementsMatch(t, []string{"result", "algorithm", "signature"}, slices.Collect(maps.Keys(envelope)))
+	var decoded SignedResult
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.NoError(t, decoded.VerifyHMAC(key))
+
+	assert.ErrorIs(t, decoded.VerifyHMAC([]byte("other")), ErrInvalidSignature)
+	decoded.Result.Success = false
+	assert.ErrorIs(t, decoded.VerifyHMAC(key), ErrInvalidSignature)
+}
+
+func TestSignResultEd25519(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	signed, err := SignResultEd25519(newSigningTestResult(), privateKey)
+	require.NoError(t, err)
+	require.NoError(t, signed.VerifyEd25519(publicKey))
+
+	signed.Result.Conversation[1].Content = "tampered"
+	assert.ErrorIs(t, signed.VerifyEd25519(publicKey), ErrInvalidSignature)
+
+	assert.ErrorContains(t, signed.VerifyHMAC([]byte("secret")), "result is signed with ed25519, not hmac-sha256")
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	// The example of RFC 8785 section 3.2.2
+	canonical, err := canonicalJSON([]byte(`{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(canonical))
+
+	// Keys are sorted by UTF-16 code units, integers are kept exact
+	canonical, err = canonicalJSON([]byte(`{"😀":1,"ﬁ":2,"seed":9007199254740993}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"seed":9007199254740993,"😀":1,"ﬁ":2}`, string(canonical))
+}
+
+func TestSignResultHMAC_Seed(t *testing.T) {
+	key := []byte("secret")
+	result := newSigningTestResult()
+	result.Seed = 1<<60 + 1
+	signed, err := SignResultHMAC(result, key)
+	require.NoError(t, err)
+
+	signed.Result.Seed = 1 << 60
+	assert.ErrorIs(t, signed.VerifyHMAC(key), ErrInvalidSignature)
+}
+
+func TestSignedResult_JSON(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signed, err := SignResultEd25519(newSigningTestResult(), privateKey)
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(signed)
+	require.NoError(t, err)
+	var envelope struct {
+		Result    map[string]any `json:"result"`
+		Algorithm string         `json:"algorithm"`
+		Signature string         `json:"signature"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &envelope))
+	assert.Equal(t, SignatureAlgorithmEd25519, envelope.Algorithm)
+	assert.Equal(t, signed.Signature, envelope.Signature)
+	assert.Equal(t, "All criteria met", envelope.Result["reasoning"])
+
+	var decoded SignedResult
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.NoError(t, decoded.VerifyEd25519(publicKey))
+}
@@ -0,0 +1,103 @@
+package scenario
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+)
+
+// stats are the counters of the scenario runs of the process, published with expvar under
+// "scenario".
+var stats = expvar.NewMap("scenario")
+
+// Stats is a snapshot of the counters of the scenario runs of the process.
+type Stats struct {
+	// ActiveRuns is the number of runs in flight.
+	ActiveRuns int64 `json:"active_runs"`
+
+	// CompletedRuns is the number of runs that reached a result.
+	CompletedRuns int64 `json:"completed_runs"`
+
+	// PassedRuns is the number of completed runs that succeeded.
+	PassedRuns int64 `json:"passed_runs"`
+
+	// FailedRuns is the number of completed runs that did not succeed, including aborted runs.
+	FailedRuns int64 `json:"failed_runs"`
+
+	// AbortedRuns is the number of runs aborted with AbortRun or WithAbortSignal.
+	AbortedRuns int64 `json:"aborted_runs"`
+
+	// CacheHits is the number of runs skipped because their result was in a result cache.
+	CacheHits int64 `json:"cache_hits"`
+
+	// LLMCalls is the number of outbound calls recorded in audit logs.
+	LLMCalls int64 `json:"llm_calls"`
+
+	// LLMErrors is the number of outbound calls recorded in audit logs that failed.
+	LLMErrors int64 `json:"llm_errors"`
+
+	// PromptTokens is the number of prompt tokens of the outbound calls.
+	PromptTokens int64 `json:"prompt_tokens"`
+
+	// CompletionTokens is the number of completion tokens of the outbound calls.
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// ReadStats returns a snapshot of the counters of the scenario runs of the process. The same
+// counters are published with expvar under "scenario", served on /debug/vars by
+// expvar.Handler.
+func ReadStats() Stats {
+	counter := func(name string) int64 {
+		if v, ok := stats.Get(name).(*expvar.Int); ok {
+			return v.Value()
+		}
+		return 0
+	}
+	return Stats{
+		ActiveRuns:       counter("active_runs"),
+		CompletedRuns:    counter("completed_runs"),
+		PassedRuns:       counter("passed_runs"),
+		FailedRuns:       counter("failed_runs"),
+		AbortedRuns:      counter("aborted_runs"),
+		CacheHits:        counter("cache_hits"),
+		LLMCalls:         counter("llm_calls"),
+		LLMErrors:        counter("llm_errors"),
+		PromptTokens:     counter("prompt_tokens"),
+		CompletionTokens: counter("completion_tokens"),
+	}
+}
+
+// StatsHandler returns an HTTP handler serving the counters of the scenario runs of the
+// process as JSON, for services that do not expose /debug/vars.
+func StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(ReadStats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// recordResultStats counts a completed run.
+func recordResultStats(result *Result) {
+	stats.Add("completed_runs", 1)
+	switch {
+	case result.Success:
+		stats.Add("passed_runs", 1)
+	case result.Aborted:
+		stats.Add("failed_runs", 1)
+		stats.Add("aborted_runs", 1)
+	default:
+		stats.Add("failed_runs", 1)
+	}
+}
+
+// recordCallStats counts an outbound call.
+func recordCallStats(entry AuditEntry) {
+	stats.Add("llm_calls", 1)
+	if entry.Error != "" {
+		stats.Add("llm_errors", 1)
+	}
+	stats.Add("prompt_tokens", entry.PromptTokens)
+	stats.Add("completion_tokens", entry.CompletionTokens)
+}
@@ -0,0 +1,53 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStats(t *testing.T) {
+	ctx := context.Background()
+	before := ReadStats()
+
+	var active int64
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			active = ReadStats().ActiveRuns
+			RecordAuditEntry(ctx, AuditEntry{Provider: "openai", PromptTokens: 10, CompletionTokens: 5})
+			return []Message{{Role: MessageRoleAssistant, Content: "Hello"}}, nil
+		},
+	}
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+
+	result, err := s.Run(ctx)
+	require.NoError(t, err)
+	require.True(t, result.Success)
+
+	after := ReadStats()
+	assert.Equal(t, before.ActiveRuns+1, active)
+	assert.Equal(t, before.ActiveRuns, after.ActiveRuns)
+	assert.Equal(t, before.CompletedRuns+1, after.CompletedRuns)
+	assert.Equal(t, before.PassedRuns+1, after.PassedRuns)
+	assert.Equal(t, before.FailedRuns, after.FailedRuns)
+	assert.Equal(t, before.LLMCalls+1, after.LLMCalls)
+	assert.Equal(t, before.PromptTokens+10, after.PromptTokens)
+	assert.Equal(t, before.CompletionTokens+5, after.CompletionTokens)
+}
+
+func TestStatsHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	StatsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/scenario", nil))
+
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var served Stats
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served))
+	assert.Equal(t, ReadStats(), served)
+}
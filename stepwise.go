@@ -0,0 +1,228 @@
+package scenario
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// stepAction is how a paused stepwise run should resume.
+type stepAction int
+
+const (
+	stepActionStep stepAction = iota
+	stepActionContinue
+	stepActionRedo
+)
+
+// errRedoTurn is returned by the beforeTurn callback to discard the previous turn and run it
+// again.
+var errRedoTurn = errors.New("redo the previous turn")
+
+// turnCheckpoint is the state of a run at the start of a turn, restored to redo the turn.
+type turnCheckpoint struct {
+	userMessage     string
+	conversation    int
+	turns           int
+	artifacts       int
+	tags            int
+	watched         int
+	offTopicTurns   int
+	emotionalStates int
+	summary         string
+	summarized      int
+	agentDuration   time.Duration
+}
+
+// checkpoint returns the state of the run at the start of the turn sending the user message.
+func (s *scenario) checkpoint(userMessage string) turnCheckpoint {
+	return turnCheckpoint{
+		userMessage:     userMessage,
+		conversation:    len(s.conversation),
+		turns:           len(s.turns),
+		artifacts:       len(s.artifacts),
+		tags:            len(s.tags),
+		watched:         s.watched,
+		offTopicTurns:   s.offTopicTurns,
+		emotionalStates: len(s.emotionalStates),
+		summary:         s.summary,
+		summarized:      s.summarized,
+		agentDuration:   s.agentDuration,
+	}
+}
+
+// restoreCheckpoint discards everything that happened in the run since the checkpoint. Agents
+// implementing HistoryAgent are reloaded with the conversation at the checkpoint.
+func (s *scenario) restoreCheckpoint(ctx context.Context, checkpoint turnCheckpoint) error {
+	s.conversation = s.conversation[:checkpoint.conversation:checkpoint.conversation]
+	s.turns = s.turns[:checkpoint.turns]
+	s.artifacts = s.artifacts[:checkpoint.artifacts]
+	s.tags = s.tags[:checkpoint.tags]
+	s.watched = checkpoint.watched
+	s.offTopicTurns = checkpoint.offTopicTurns
+	s.emotionalStates = s.emotionalStates[:checkpoint.emotionalStates]
+	s.summary, s.summarized = checkpoint.summary, checkpoint.summarized
+	s.agentDuration = checkpoint.agentDuration
+
+	if historyAgent, ok := s.agent.(HistoryAgent); ok {
+		return historyAgent.LoadHistory(ctx, append([]Message{}, s.conversation...))
+	}
+	return nil
+}
+
+// StepwiseRun is a scenario run that pauses before every turn, see RunStepwise.
+type StepwiseRun struct {
+	steps     chan *Step
+	done      chan struct{}
+	continued bool
+	result    *Result
+	err       error
+}
+
+// Step is a stepwise run paused before a turn. Exactly one of Step or Continue must be
+// called to resume the run.
+type Step struct {
+	// Turn is the zero-based turn the run is paused before.
+	Turn int
+
+	// Conversation is the conversation so far.
+	Conversation []Message
+
+	// UserMessage is the user message about to be sent to the agent.
+	UserMessage string
+
+	resume chan stepAction
+}
+
+// RunStepwise starts running the scenario in the background, pausing before every turn
+// until the run is resumed, allowing the conversation to be inspected and the next user
+// message to be edited. The scenario must have been created with NewScenario.
+func RunStepwise(ctx context.Context, sc Scenario) (*StepwiseRun, error) {
+	s, ok := sc.(*scenario)
+	if !ok {
+		return nil, errors.New("stepwise runs require a scenario created with NewScenario")
+	}
+
+	r := &StepwiseRun{
+		steps: make(chan *Step),
+		done:  make(chan struct{}),
+	}
+	s.beforeTurn = r.pause
+
+	go func() {
+		defer close(r.done)
+		defer func() { s.beforeTurn = nil }()
+		r.result, r.err = s.Run(ctx)
+	}()
+
+	return r, nil
+}
+
+// Next waits for the run to pause before the next turn, it returns false once the run is over.
+func (r *StepwiseRun) Next() (*Step, bool) {
+	select {
+	case step := <-r.steps:
+		return step, true
+	case <-r.done:
+		return nil, false
+	}
+}
+
+// Result waits for the run to be over and returns its result.
+func (r *StepwiseRun) Result() (*Result, error) {
+	<-r.done
+	return r.result, r.err
+}
+
+// Interact drives the run from a terminal, printing the conversation before every turn and
+// reading a command from in: an empty line steps to the next turn, "c" continues until the
+// end of the run, "e <message>" replaces the next user message before stepping and "r"
+// discards the previous turn to redo it.
+func (r *StepwiseRun) Interact(in io.Reader, out io.Writer) (*Result, error) {
+	scanner := bufio.NewScanner(in)
+	for {
+		step, ok := r.Next()
+		if !ok {
+			return r.Result()
+		}
+
+		fmt.Fprintf(out, "--- turn %d ---\n", step.Turn)
+		for _, message := range step.Conversation {
+			fmt.Fprintf(out, "%s: %s\n", message.Role, message.Content)
+		}
+		fmt.Fprintf(out, "next user message: %s\n[enter] step, [c] continue, [e <message>] edit and step, [r] redo previous turn > ", step.UserMessage)
+
+		command := ""
+		if scanner.Scan() {
+			command = strings.TrimSpace(scanner.Text())
+		}
+		switch {
+		case command == "c":
+			step.Continue()
+		case command == "r":
+			step.Redo()
+		case strings.HasPrefix(command, "e "):
+			step.SetUserMessage(strings.TrimPrefix(command, "e "))
+			step.Step()
+		default:
+			step.Step()
+		}
+	}
+}
+
+// SetUserMessage replaces the user message about to be sent to the agent.
+func (st *Step) SetUserMessage(message string) {
+	st.UserMessage = message
+}
+
+// Step resumes the run, pausing again before the next turn.
+func (st *Step) Step() {
+	st.resume <- stepActionStep
+}
+
+// Continue resumes the run without pausing again.
+func (st *Step) Continue() {
+	st.resume <- stepActionContinue
+}
+
+// Redo discards the previous turn and pauses again before it, with its user message, which
+// can be edited before stepping to run the turn again. Before the first turn it steps.
+// Agents keeping state across turns should implement HistoryAgent, they are reloaded with the
+// conversation before the discarded turn.
+func (st *Step) Redo() {
+	st.resume <- stepActionRedo
+}
+
+// pause is the beforeTurn callback of the scenario, it blocks until the step is resumed.
+func (r *StepwiseRun) pause(ctx context.Context, turn int, conversation []Message, message string) (string, error) {
+	if r.continued {
+		return message, nil
+	}
+
+	step := &Step{
+		Turn:         turn,
+		Conversation: append([]Message{}, conversation...),
+		UserMessage:  message,
+		resume:       make(chan stepAction, 1),
+	}
+	select {
+	case r.steps <- step:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	select {
+	case action := <-step.resume:
+		if action == stepActionRedo && turn > 0 {
+			return "", errRedoTurn
+		}
+		r.continued = action == stepActionContinue
+		return step.UserMessage, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
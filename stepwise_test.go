@@ -0,0 +1,186 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStepwiseTestScenario(maxTurns int) Scenario {
+	turn := 0
+	return NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				msg := fmt.Sprintf("User message %d", turn)
+				turn++
+				return &msg, nil, nil
+			},
+		}),
+		WithMaxTurns(maxTurns),
+	)
+}
+
+func TestRunStepwise(t *testing.T) {
+	run, err := RunStepwise(context.Background(), newStepwiseTestScenario(3))
+	require.NoError(t, err)
+
+	step, ok := run.Next()
+	require.True(t, ok)
+	assert.Equal(t, 0, step.Turn)
+	assert.Empty(t, step.Conversation)
+	assert.Equal(t, "User message 0", step.UserMessage)
+	step.SetUserMessage("Edited message")
+	step.Step()
+
+	step, ok = run.Next()
+	require.True(t, ok)
+	assert.Equal(t, 1, step.Turn)
+	require.Len(t, step.Conversation, 2)
+	assert.Equal(t, "Edited message", step.Conversation[0].Content)
+	assert.Equal(t, "Agent response to: Edited message", step.Conversation[1].Content)
+	step.Continue()
+
+	_, ok = run.Next()
+	assert.False(t, ok)
+
+	result, err := run.Result()
+	require.NoError(t, err)
+	assert.Len(t, result.Conversation, 6)
+}
+
+func TestRunStepwise_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	run, err := RunStepwise(ctx, newStepwiseTestScenario(3))
+	require.NoError(t, err)
+
+	_, ok := run.Next()
+	require.True(t, ok)
+	cancel()
+
+	_, err = run.Result()
+	require.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "run interrupted before turn 0")
+}
+
+func TestRunStepwise_Interact(t *testing.T) {
+	run, err := RunStepwise(context.Background(), newStepwiseTestScenario(3))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	result, err := run.Interact(strings.NewReader("e hello agent\nc\n"), &out)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello agent", result.Conversation[0].Content)
+	assert.Contains(t, out.String(), "--- turn 0 ---")
+	assert.Contains(t, out.String(), "next user message: User message 0")
+	assert.NotContains(t, out.String(), "--- turn 2 ---")
+}
+
+func TestRunStepwise_CustomScenario(t *testing.T) {
+	_, err := RunStepwise(context.Background(), struct{ Scenario }{})
+	require.Error(t, err)
+}
+
+func TestRunStepwise_Redo(t *testing.T) {
+	agent := &mockHistoryAgent{}
+	turn := 0
+	run, err := RunStepwise(context.Background(), NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				msg := fmt.Sprintf("User message %d", turn)
+				turn++
+				return &msg, nil, nil
+			},
+		}),
+		WithMaxTurns(2),
+	))
+	require.NoError(t, err)
+
+	step, ok := run.Next()
+	require.True(t, ok)
+	step.Redo()
+
+	step, ok = run.Next()
+	require.True(t, ok)
+	assert.Equal(t, 1, step.Turn)
+	step.Redo()
+
+	step, ok = run.Next()
+	require.True(t, ok)
+	assert.Equal(t, 0, step.Turn)
+	assert.Empty(t, step.Conversation)
+	assert.Equal(t, "User message 0", step.UserMessage)
+	assert.Empty(t, agent.history)
+	step.SetUserMessage("Edited message")
+	step.Continue()
+
+	result, err := run.Result()
+	require.NoError(t, err)
+	require.Len(t, result.Conversation, 4)
+	assert.Equal(t, "Edited message", result.Conversation[0].Content)
+	assert.Equal(t, "Agent response to: Edited message", result.Conversation[1].Content)
+	assert.Len(t, result.Turns, 2)
+}
+
+func TestRunStepwise_RedoRestoresRunningSummary(t *testing.T) {
+	var summarized []string
+	summarizer := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			summarized = append(summarized, messages[1].Content)
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{Content: fmt.Sprintf("summary %d", len(summarized))},
+			}}}, nil
+		},
+	}
+	calls := 0
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			calls++
+			if calls == 1 {
+				return []Message{{Role: MessageRoleAssistant, Content: strings.Repeat("a", 800)}}, nil
+			}
+			return []Message{{Role: MessageRoleAssistant, Content: "ok"}}, nil
+		},
+	}
+	run, err := RunStepwise(context.Background(), NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(&mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				msg := "hi"
+				return &msg, nil, nil
+			},
+		}),
+		WithMaxTurns(3),
+		WithRunningSummary(summarizer, 150),
+	))
+	require.NoError(t, err)
+
+	step, ok := run.Next()
+	require.True(t, ok)
+	step.Step()
+	step, ok = run.Next()
+	require.True(t, ok)
+	step.Step()
+	step, ok = run.Next()
+	require.True(t, ok)
+	assert.Equal(t, 2, step.Turn)
+	require.Len(t, summarized, 2)
+	step.Redo()
+
+	step, ok = run.Next()
+	require.True(t, ok)
+	assert.Equal(t, 1, step.Turn)
+	step.Continue()
+
+	_, err = run.Result()
+	require.NoError(t, err)
+	require.Len(t, summarized, 3, "the summary of the discarded turn is made again")
+	assert.True(t, strings.HasPrefix(summarized[2], "<summary>\nsummary 1\n</summary>\n"), "the summary is restored to the one before the discarded turn")
+}
@@ -0,0 +1,114 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MessageChunk is a chunk of a response streamed by a StreamingAgent.
+type MessageChunk struct {
+	// Index is the zero-based index of the message the chunk belongs to, among the messages
+	// of the response.
+	Index int
+
+	// Role is the role of the message, only needed on its first chunk, later chunks inherit
+	// it. Messages whose first chunk has no role are assistant messages.
+	Role MessageRole
+
+	// Content is the content appended to the message.
+	Content string
+
+	// ToolCalls are the tool calls appended to the message.
+	ToolCalls []ToolCall
+
+	// ToolCallID is the ID of the tool call answered by a tool message, only needed on its
+	// first chunk.
+	ToolCallID string
+
+	// Err is set when the stream failed, ending the turn with the error.
+	Err error
+}
+
+// streamTiming is the timing of a streamed response, relative to the start of the stream.
+type streamTiming struct {
+	// firstToken is when the first token was received, 0 for agents not streaming
+	firstToken time.Duration
+
+	// lastToken is when the last token was received
+	lastToken time.Duration
+
+	// generated is the number of characters of content received after the first chunk
+	generated int
+}
+
+// tokensPerSecond returns the generation throughput of the stream, estimated from the length of
+// the content received after the first chunk, between the first and the last token. It is 0
+// when there is no measurable rate: for agents not streaming and responses streamed in a
+// single chunk.
+func (t streamTiming) tokensPerSecond() float64 {
+	generation := t.lastToken - t.firstToken
+	if t.firstToken == 0 || generation <= 0 || t.generated == 0 {
+		return 0
+	}
+	return float64(estimateTokens(t.generated)) / generation.Seconds()
+}
+
+// runAgent runs the agent for a turn, streaming the response of agents implementing
+// StreamingAgent. It returns the messages of the response and the timing of the stream, zero
+// for agents not streaming.
+func (s *scenario) runAgent(ctx context.Context, message string) ([]Message, streamTiming, error) {
+	streamingAgent, ok := s.agent.(StreamingAgent)
+	if !ok {
+		messages, err := s.agent.Run(ctx, message)
+		return messages, streamTiming{}, err
+	}
+
+	start := time.Now()
+	chunks, err := streamingAgent.RunStream(ctx, message)
+	if err != nil {
+		return nil, streamTiming{}, err
+	}
+	return accumulateChunks(ctx, chunks, start)
+}
+
+// accumulateChunks accumulates the streamed chunks into messages until the channel is closed,
+// timing the first and last tokens from start.
+func accumulateChunks(ctx context.Context, chunks <-chan MessageChunk, start time.Time) ([]Message, streamTiming, error) {
+	var messages []Message
+	var timing streamTiming
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, timing, ctx.Err()
+		case chunk, ok := <-chunks:
+			if !ok {
+				return messages, timing, nil
+			}
+			if chunk.Err != nil {
+				return nil, timing, chunk.Err
+			}
+			if chunk.Index < 0 || chunk.Index > len(messages) {
+				return nil, timing, fmt.Errorf("chunk of message %d received after %d messages", chunk.Index, len(messages))
+			}
+			if chunk.Content != "" || len(chunk.ToolCalls) > 0 {
+				timing.lastToken = time.Since(start)
+				if timing.firstToken == 0 {
+					timing.firstToken = timing.lastToken
+				} else {
+					timing.generated += len(chunk.Content)
+				}
+			}
+
+			if chunk.Index == len(messages) {
+				role := chunk.Role
+				if role == "" {
+					role = MessageRoleAssistant
+				}
+				messages = append(messages, Message{Role: role, ToolCallID: chunk.ToolCallID})
+			}
+			messages[chunk.Index].Content += chunk.Content
+			messages[chunk.Index].ToolCalls = append(messages[chunk.Index].ToolCalls, chunk.ToolCalls...)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockStreamingAgent is a StreamingAgent streaming the chunks, Run is never called.
+type mockStreamingAgent struct {
+	mockAgent
+	chunks []MessageChunk
+	delay  time.Duration
+}
+
+func (m *mockStreamingAgent) RunStream(ctx context.Context, message string) (<-chan MessageChunk, error) {
+	chunks := make(chan MessageChunk)
+	go func() {
+		defer close(chunks)
+		time.Sleep(m.delay)
+		for _, chunk := range m.chunks {
+			chunks <- chunk
+		}
+	}()
+	return chunks, nil
+}
+
+func TestScenario_Run_StreamingAgent(t *testing.T) {
+	var conversation []Message
+	agent := &mockStreamingAgent{
+		mockAgent: mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return nil, errors.New("Run called on a streaming agent")
+		}},
+		chunks: []MessageChunk{
+			{Index: 0, Role: MessageRoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "search"}}}},
+			{Index: 1, Role: MessageRoleTool, ToolCallID: "call_1", Content: "found"},
+			{Index: 2, Role: MessageRoleAssistant, Content: "Hello"},
+			{Index: 2, Content: ", world"},
+		},
+		delay: 10 * time.Millisecond,
+	}
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, c []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "User message"
+				return &msg, nil, nil
+			}
+			conversation = c
+			return nil, &Result{Success: true, Conversation: c}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(mockTestingAgentInst),
+	)
+
+	result, err := s.Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, conversation, 4)
+	assert.Equal(t, "found", conversation[2].Content)
+	assert.Equal(t, "call_1", conversation[2].ToolCallID)
+	assert.Equal(t, Message{Role: MessageRoleAssistant, Content: "Hello, world"}, conversation[3])
+	require.Len(t, result.Turns, 1)
+	assert.GreaterOrEqual(t, result.Turns[0].TimeToFirstToken, 10*time.Millisecond)
+}
+
+func TestAccumulateChunks_Errors(t *testing.T) {
+	chunks := make(chan MessageChunk, 2)
+	chunks <- MessageChunk{Index: 0, Role: MessageRoleAssistant, Content: "partial"}
+	chunks <- MessageChunk{Err: errors.New("stream reset")}
+	close(chunks)
+
+	_, _, err := accumulateChunks(context.Background(), chunks, time.Now())
+	assert.EqualError(t, err, "stream reset")
+
+	chunks = make(chan MessageChunk, 1)
+	chunks <- MessageChunk{Index: 1, Role: MessageRoleAssistant}
+	close(chunks)
+
+	_, _, err = accumulateChunks(context.Background(), chunks, time.Now())
+	assert.EqualError(t, err, "chunk of message 1 received after 0 messages")
+}
+
+func TestAccumulateChunks_Roles(t *testing.T) {
+	chunks := make(chan MessageChunk, 5)
+	chunks <- MessageChunk{Index: 0, Content: "Let me "}
+	chunks <- MessageChunk{Index: 0, Content: "check."}
+	chunks <- MessageChunk{Index: 1, Role: MessageRoleTool, ToolCallID: "call_1", Content: "in stock"}
+	chunks <- MessageChunk{Index: 1, Content: ", 3 left"}
+	chunks <- MessageChunk{Index: 2, Content: "It is in stock."}
+	close(chunks)
+
+	messages, _, err := accumulateChunks(context.Background(), chunks, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, []Message{
+		{Role: MessageRoleAssistant, Content: "Let me check."},
+		{Role: MessageRoleTool, ToolCallID: "call_1", Content: "in stock, 3 left"},
+		{Role: MessageRoleAssistant, Content: "It is in stock."},
+	}, messages)
+}
+
+func TestStreamTiming_TokensPerSecond(t *testing.T) {
+	assert.InDelta(t, 200.0, streamTiming{firstToken: 100 * time.Millisecond, lastToken: 600 * time.Millisecond, generated: 400}.tokensPerSecond(), 0.001)
+	assert.Zero(t, streamTiming{}.tokensPerSecond(), "agents not streaming")
+	assert.Zero(t, streamTiming{firstToken: time.Second, lastToken: time.Second}.tokensPerSecond(), "responses streamed at once")
+}
+
+func TestAccumulateChunks_Timing(t *testing.T) {
+	chunks := make(chan MessageChunk, 1)
+	chunks <- MessageChunk{Content: strings.Repeat("a", 400)}
+	close(chunks)
+
+	_, timing, err := accumulateChunks(context.Background(), chunks, time.Now())
+
+	require.NoError(t, err)
+	assert.Positive(t, timing.firstToken)
+	assert.Zero(t, timing.tokensPerSecond(), "a single chunk has no measurable rate")
+
+	chunks = make(chan MessageChunk)
+	go func() {
+		defer close(chunks)
+		chunks <- MessageChunk{Content: strings.Repeat("a", 4000)}
+		time.Sleep(50 * time.Millisecond)
+		chunks <- MessageChunk{Content: strings.Repeat("b", 40)}
+	}()
+
+	_, timing, err = accumulateChunks(context.Background(), chunks, time.Now())
+
+	require.NoError(t, err)
+	assert.Equal(t, 40, timing.generated, "the content of the first chunk is not counted")
+	assert.Less(t, timing.tokensPerSecond(), 1000.0, "the first chunk would inflate the rate to about 20000")
+}
+
+// turnStreamingAgent is a StreamingAgent streaming the chunks of the turn, a turn without
+// chunks being answered by Run without streaming.
+type turnStreamingAgent struct {
+	turns [][]MessageChunk
+	turn  int
+}
+
+func (a *turnStreamingAgent) Run(ctx context.Context, message string) ([]Message, error) {
+	return nil, errors.New("Run called on a streaming agent")
+}
+
+func (a *turnStreamingAgent) RunStream(ctx context.Context, message string) (<-chan MessageChunk, error) {
+	turn := a.turns[a.turn]
+	a.turn++
+	chunks := make(chan MessageChunk)
+	go func() {
+		defer close(chunks)
+		for _, chunk := range turn {
+			time.Sleep(10 * time.Millisecond)
+			chunks <- chunk
+		}
+	}()
+	return chunks, nil
+}
+
+func TestScenario_Run_TokensPerSecondSingleChunkTurns(t *testing.T) {
+	agent := &turnStreamingAgent{turns: [][]MessageChunk{
+		{{Content: "Hello, world"}},
+		{{Content: "Hello"}, {Content: ", world, "}, {Content: strings.Repeat("a", 40)}},
+		{{Content: "Bye"}},
+	}}
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if lastMessage {
+				return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+			}
+			msg := "hi"
+			return &msg, nil, nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(mockTestingAgentInst),
+		WithMaxTurns(3),
+		WithMetricCriteria(TokensPerSecond().AtLeast(1)),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, result.Turns, 3)
+	assert.Zero(t, result.Turns[0].TokensPerSecond)
+	assert.Positive(t, result.Turns[1].TokensPerSecond)
+	assert.Zero(t, result.Turns[2].TokensPerSecond)
+	assert.True(t, result.Success, "turns streamed in a single chunk are skipped")
+}
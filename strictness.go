@@ -0,0 +1,36 @@
+package scenario
+
+import "fmt"
+
+// JudgeStrictness is how strictly the testing agent reads the criteria when judging.
+type JudgeStrictness string
+
+const (
+	// JudgeStrictnessBalanced is the default strictness, judging only on the criteria and
+	// withholding judgement when the conversation is not conclusive.
+	JudgeStrictnessBalanced JudgeStrictness = "balanced"
+
+	// JudgeStrictnessLenient gives the agent the benefit of the doubt, judging on the intent
+	// of the criteria rather than their exact wording.
+	JudgeStrictnessLenient JudgeStrictness = "lenient"
+
+	// JudgeStrictnessStrict reads the criteria literally, every part of a criterion having
+	// to be explicitly met in the conversation.
+	JudgeStrictnessStrict JudgeStrictness = "strict"
+)
+
+// strictnessInstructions are the instructions given to the testing agent for each strictness.
+var strictnessInstructions = map[JudgeStrictness]string{
+	JudgeStrictnessBalanced: "",
+	JudgeStrictnessLenient:  "Judge leniently: read the criteria for their intent rather than their exact wording, and give the agent the benefit of the doubt when a criterion is met in substance, even if imperfectly or implicitly.",
+	JudgeStrictnessStrict:   "Judge strictly: read the criteria literally, a criterion is met only if every part of it is explicitly met in the conversation, and any doubt means the criterion is unmet.",
+}
+
+// instructions returns the instructions given to the testing agent for the strictness.
+func (s JudgeStrictness) instructions() (string, error) {
+	instructions, ok := strictnessInstructions[s]
+	if !ok {
+		return "", fmt.Errorf("unknown judge strictness %q", s)
+	}
+	return instructions, nil
+}
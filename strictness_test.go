@@ -0,0 +1,38 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestingAgent_GenerateNextMessage_JudgeStrictness(t *testing.T) {
+	ctx := context.Background()
+	var systemMessage string
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			systemMessage = messages[0].Content
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{ToolCalls: []ToolCall{{
+					Type:     ToolTypeFunction,
+					Function: &ToolCallFunction{Name: "finish_test", Arguments: map[string]any{"verdict": "success", "reasoning": "done"}},
+				}}}}},
+			}, nil
+		},
+	}
+
+	_, result, err := NewTestingAgent(mockLLM, WithJudgeStrictness(JudgeStrictnessStrict)).GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, nil, false, true)
+	require.NoError(t, err)
+	assert.Contains(t, systemMessage, "<strictness>\n"+strictnessInstructions[JudgeStrictnessStrict]+"\n</strictness>")
+	assert.Equal(t, JudgeStrictnessStrict, result.JudgeStrictness)
+
+	_, result, err = NewTestingAgent(mockLLM).GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, nil, false, true)
+	require.NoError(t, err)
+	assert.NotContains(t, systemMessage, "<strictness>")
+	assert.Equal(t, JudgeStrictnessBalanced, result.JudgeStrictness)
+
+	_, _, err = NewTestingAgent(mockLLM, WithJudgeStrictness("harsh")).GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, nil, false, true)
+	assert.EqualError(t, err, `unknown judge strictness "harsh"`)
+}
@@ -0,0 +1,391 @@
+package scenario
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSkipped is the error of the scenarios of a suite skipped once it failed fast, see
+// WithFailFast.
+var ErrSkipped = errors.New("scenario skipped after the suite failed fast")
+
+// SuiteOption configures a suite created with NewSuite.
+type SuiteOption func(*Suite)
+
+// Suite runs scenarios in parallel and reports on them as a whole.
+type Suite struct {
+	scenarios   []Scenario
+	concurrency int
+	progress    []func(AggregateSummary)
+	failFast    *FailFast
+	schedule    Schedule
+	previous    []*Result
+	shard       *suiteShard
+}
+
+// NewSuite creates a new suite running the scenarios, by default one at a time.
+func NewSuite(scenarios []Scenario, opts ...SuiteOption) *Suite {
+	s := &Suite{
+		scenarios:   scenarios,
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithConcurrency sets how many scenarios of the suite run at the same time, all of them when
+// 0 or less.
+func WithConcurrency(n int) SuiteOption {
+	return func(s *Suite) {
+		s.concurrency = n
+	}
+}
+
+// WithSuiteProgress calls fn with the updated summary of the suite every time a scenario
+// completes, see Aggregator.Subscribe. Calls are serialized.
+func WithSuiteProgress(fn func(AggregateSummary)) SuiteOption {
+	return func(s *Suite) {
+		s.progress = append(s.progress, fn)
+	}
+}
+
+// FailFast is the failure threshold stopping a suite run, see WithFailFast. Failures include
+// the scenarios failing with an error.
+type FailFast struct {
+	// MaxFailures is the number of failed scenarios stopping the suite, 0 for no limit.
+	MaxFailures int
+
+	// MaxFailureRate is the ratio of the scenarios of the suite that failed stopping the suite,
+	// e.g. 0.1 stops a suite of 50 scenarios at its fifth failure, 0 for no limit.
+	MaxFailureRate float64
+
+	// AgentUnavailable stops the suite as soon as a scenario fails with ErrAgentUnavailable,
+	// see RetryPolicy.MaxIdenticalAgentErrors.
+	AgentUnavailable bool
+}
+
+// reached reports whether the failures of the summary reach the threshold.
+func (f FailFast) reached(summary AggregateSummary) bool {
+	if f.MaxFailures > 0 && summary.Failed >= f.MaxFailures {
+		return true
+	}
+	return f.MaxFailureRate > 0 && summary.Total > 0 && float64(summary.Failed)/float64(summary.Total) >= f.MaxFailureRate
+}
+
+// WithFailFast stops the suite once its failures reach the threshold, e.g. for pre-merge
+// checks that do not need the whole suite once the first scenarios fail. The scenarios not
+// started yet are skipped and the running ones are cancelled, their errors wrapping ErrSkipped.
+func WithFailFast(threshold FailFast) SuiteOption {
+	return func(s *Suite) {
+		s.failFast = &threshold
+	}
+}
+
+// Schedule is the order in which a suite starts its scenarios, see WithSchedule.
+type Schedule int
+
+const (
+	// ScheduleInOrder starts the scenarios in the order of the suite.
+	ScheduleInOrder Schedule = iota
+
+	// ScheduleFailedFirst starts the scenarios that failed in the previous run first, then the
+	// ones without a previous result, then the ones that passed.
+	ScheduleFailedFirst
+
+	// ScheduleShortestFirst starts the scenarios that were the fastest in the previous run
+	// first, the ones without a previous result last.
+	ScheduleShortestFirst
+)
+
+// WithSchedule sets the order in which the suite starts its scenarios from the results of a
+// previous run, e.g. to get the failures early in CI along with WithFailFast. Previous results
+// are matched to the scenarios by ID, see WithID, a scenario with several of them counting as
+// failed if any failed and taking the longest duration. Scenarios keep their order in the suite
+// when the schedule does not tell them apart, and their results are still reported in the order
+// of the suite.
+func WithSchedule(schedule Schedule, previous []*Result) SuiteOption {
+	return func(s *Suite) {
+		s.schedule = schedule
+		s.previous = previous
+	}
+}
+
+// order returns the indices of the scenarios in the order they are started.
+func (s *Suite) order(scenarios []Scenario) []int {
+	order := make([]int, len(scenarios))
+	for i := range order {
+		order[i] = i
+	}
+	if s.schedule == ScheduleInOrder {
+		return order
+	}
+
+	type previousRun struct {
+		failed   bool
+		duration time.Duration
+	}
+	previous := map[string]previousRun{}
+	for _, result := range s.previous {
+		run := previous[result.ScenarioID]
+		run.failed = run.failed || !result.Success
+		run.duration = max(run.duration, result.TotalDurationNSec)
+		previous[result.ScenarioID] = run
+	}
+	// Lower ranks start first
+	ranks := make([]int64, len(scenarios))
+	for i, sc := range scenarios {
+		run, ok := previousRun{}, false
+		if base, isScenario := sc.(*scenario); isScenario {
+			run, ok = previous[base.scenarioID()]
+		}
+		switch {
+		case s.schedule == ScheduleFailedFirst && !ok:
+			ranks[i] = 1
+		case s.schedule == ScheduleFailedFirst && run.failed:
+			ranks[i] = 0
+		case s.schedule == ScheduleFailedFirst:
+			ranks[i] = 2
+		case !ok:
+			ranks[i] = math.MaxInt64
+		default:
+			ranks[i] = int64(run.duration)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(ranks[a], ranks[b])
+	})
+	return order
+}
+
+// suiteShard is the shard of a suite run, see WithShard.
+type suiteShard struct {
+	index int
+	count int
+}
+
+// WithShard runs only the scenarios of the shard index, from 0 to count-1, e.g. to spread a
+// suite over count CI jobs or machines, each running the suite with its own index. Scenarios are
+// assigned to the shards by a hash of their ID, see WithID, so every scenario runs in exactly
+// one shard whatever the order of the suite, and the repetitions of a scenario run in the same
+// shard. Scenarios not created with NewScenario are assigned by their index in the suite.
+// Combine the results of the shards with MergeSuiteResults.
+func WithShard(index, count int) SuiteOption {
+	return func(s *Suite) {
+		s.shard = &suiteShard{index: index, count: count}
+	}
+}
+
+// includes reports whether the scenario at the index of the suite belongs to the shard.
+func (s *suiteShard) includes(sc Scenario, index int) bool {
+	if s == nil {
+		return true
+	}
+	key := fmt.Sprintf("#%d", index)
+	if base, ok := sc.(*scenario); ok {
+		key = base.scenarioID()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()%uint64(s.count) == uint64(s.index)
+}
+
+// SuiteResult is the aggregate report of a suite run.
+type SuiteResult struct {
+	// Results are the results of the scenarios, in the order of the suite, nil for the
+	// scenarios that failed with an error and the scenarios of other shards, see WithShard.
+	Results []*Result
+
+	// Errors are the errors of the scenarios, in the order of the suite, nil for the scenarios
+	// that completed and the scenarios of other shards.
+	Errors []error
+
+	// Passed is the number of successful scenarios.
+	Passed int
+
+	// Failed is the number of unsuccessful scenarios, including the ones that failed with an
+	// error.
+	Failed int
+
+	// Errored is the number of scenarios that failed with an error, not counting the skipped
+	// ones.
+	Errored int
+
+	// Skipped is the number of scenarios skipped once the suite failed fast, see WithFailFast.
+	Skipped int
+
+	// TotalDuration is the wall-clock duration of the suite run.
+	TotalDuration time.Duration
+}
+
+// Scenarios returns an iterator over the results of the scenarios that completed, with their
+// indices in the suite.
+func (r *SuiteResult) Scenarios() iter.Seq2[int, *Result] {
+	return func(yield func(int, *Result) bool) {
+		for i, result := range r.Results {
+			if result == nil {
+				continue
+			}
+			if !yield(i, result) {
+				return
+			}
+		}
+	}
+}
+
+// MergeSuiteResults merges the results of the shards of a suite run with WithShard into the
+// result of the whole suite, e.g. on the CI job collecting the results of the shard jobs. The
+// shards must run the same suite, their results being matched by index. The duration of the
+// merged result is the one of the slowest shard.
+func MergeSuiteResults(shards ...*SuiteResult) (*SuiteResult, error) {
+	merged := &SuiteResult{}
+	for n, shard := range shards {
+		if n == 0 {
+			merged.Results = make([]*Result, len(shard.Results))
+			merged.Errors = make([]error, len(shard.Errors))
+		}
+		if len(shard.Results) != len(merged.Results) || len(shard.Errors) != len(merged.Errors) {
+			return nil, fmt.Errorf("shard %d has %d scenarios, expected %d", n, len(shard.Results), len(merged.Results))
+		}
+		for i := range shard.Results {
+			if shard.Results[i] == nil && shard.Errors[i] == nil {
+				continue
+			}
+			if merged.Results[i] != nil || merged.Errors[i] != nil {
+				return nil, fmt.Errorf("scenario %d ran in more than one shard", i)
+			}
+			merged.Results[i], merged.Errors[i] = shard.Results[i], shard.Errors[i]
+		}
+		merged.Passed += shard.Passed
+		merged.Failed += shard.Failed
+		merged.Errored += shard.Errored
+		merged.Skipped += shard.Skipped
+		merged.TotalDuration = max(merged.TotalDuration, shard.TotalDuration)
+	}
+
+	return merged, nil
+}
+
+// Run runs the scenarios of the suite, starting them in the order of its schedule. The errors
+// of the scenarios are recorded in the suite result and joined in the returned error, except
+// for the skipped scenarios. A scenario created with NewScenario added more than once, e.g. to
+// repeat it, runs its repetitions on copies of the scenario, which share its agent, testing
+// agent and stores: with a concurrency above 1, they must be safe for concurrent use, or the
+// repetitions must be built as separate scenarios, e.g. with an agent each.
+func (s *Suite) Run(ctx context.Context) (*SuiteResult, error) {
+	if s.shard != nil && (s.shard.index < 0 || s.shard.index >= s.shard.count) {
+		return nil, fmt.Errorf("invalid shard %d of %d", s.shard.index, s.shard.count)
+	}
+
+	start := time.Now()
+	order := s.order(s.scenarios)
+	order = slices.DeleteFunc(order, func(i int) bool {
+		return !s.shard.includes(s.scenarios[i], i)
+	})
+	aggregator := NewAggregator(len(order))
+	var progressMu sync.Mutex
+	for _, fn := range s.progress {
+		aggregator.Subscribe(func(AggregateSummary) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			// The latest summary, for the calls to report an increasing progress
+			fn(aggregator.Summary())
+		})
+	}
+
+	concurrency := s.concurrency
+	if concurrency <= 0 || concurrency > len(order) {
+		concurrency = len(order)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	result := &SuiteResult{
+		Results: make([]*Result, len(s.scenarios)),
+		Errors:  make([]error, len(s.scenarios)),
+	}
+	// Copies are made before any scenario starts running
+	scenarios := slices.Clone(s.scenarios)
+	seen := map[*scenario]bool{}
+	for i, sc := range scenarios {
+		if base, ok := sc.(*scenario); ok {
+			if seen[base] {
+				scenarios[i] = base.clone()
+			}
+			seen[base] = true
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failedFast atomic.Bool
+	if s.failFast != nil {
+		aggregator.Subscribe(func(summary AggregateSummary) {
+			if s.failFast.reached(summary) {
+				failedFast.Store(true)
+				cancel()
+			}
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, i := range order {
+		sc := scenarios[i]
+		sem <- struct{}{}
+		if failedFast.Load() {
+			<-sem
+			result.Errors[i] = fmt.Errorf("scenario %d: %w", i, ErrSkipped)
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			scenarioResult, err := sc.Run(runCtx)
+			if err != nil && failedFast.Load() && errors.Is(err, context.Canceled) {
+				result.Errors[i] = fmt.Errorf("scenario %d: %w", i, errors.Join(ErrSkipped, err))
+				return
+			}
+			if err != nil {
+				result.Errors[i] = fmt.Errorf("scenario %d: %w", i, err)
+				if s.failFast != nil && s.failFast.AgentUnavailable && errors.Is(err, ErrAgentUnavailable) {
+					failedFast.Store(true)
+					cancel()
+				}
+				aggregator.Add(&Result{Success: false})
+				return
+			}
+			result.Results[i] = scenarioResult
+			aggregator.Add(scenarioResult)
+		}()
+	}
+	wg.Wait()
+
+	summary := aggregator.Summary()
+	result.Passed = summary.Passed
+	result.Failed = summary.Failed
+	var errs []error
+	for _, err := range result.Errors {
+		switch {
+		case errors.Is(err, ErrSkipped):
+			result.Skipped++
+		case err != nil:
+			result.Errored++
+			errs = append(errs, err)
+		}
+	}
+	result.TotalDuration = time.Since(start)
+
+	return result, errors.Join(errs...)
+}
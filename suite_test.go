@@ -0,0 +1,271 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuite_Run(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	newScenario := func(id string, success bool, agentErr error) Scenario {
+		agent := &mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if agentErr != nil {
+				return nil, agentErr
+			}
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+		}}
+		testingAgent := &mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				if firstMessage {
+					msg := "User message"
+					return &msg, nil, nil
+				}
+				return nil, &Result{Success: success}, nil
+			},
+		}
+		return NewScenario(WithID(id), WithAgent(agent), WithTestingAgent(testingAgent))
+	}
+	var summaries []AggregateSummary
+	suite := NewSuite(
+		[]Scenario{
+			newScenario("a", true, nil),
+			newScenario("b", false, nil),
+			newScenario("c", true, errors.New("agent down")),
+			newScenario("d", true, nil),
+		},
+		WithConcurrency(2),
+		WithSuiteProgress(func(summary AggregateSummary) {
+			summaries = append(summaries, summary)
+		}),
+	)
+
+	result, err := suite.Run(context.Background())
+
+	require.ErrorContains(t, err, "scenario 2: failed to run agent: agent down")
+	assert.Equal(t, 2, result.Passed)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, 1, result.Errored)
+	assert.Equal(t, int32(2), maxRunning.Load())
+	assert.GreaterOrEqual(t, result.TotalDuration, 20*time.Millisecond)
+	assert.Nil(t, result.Results[2])
+	require.Len(t, summaries, 4)
+	assert.Equal(t, 4, summaries[3].Completed)
+
+	var ids []string
+	for i, r := range result.Scenarios() {
+		assert.NotNil(t, result.Results[i])
+		ids = append(ids, r.ScenarioID)
+	}
+	assert.Equal(t, []string{"a", "b", "d"}, ids)
+}
+
+func TestSuite_Run_SameScenarioTwice(t *testing.T) {
+	sc := NewScenario(
+		WithAgent(&mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			time.Sleep(10 * time.Millisecond)
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response to: " + message}}, nil
+		}}),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+
+	result, err := NewSuite([]Scenario{sc, sc, sc}, WithConcurrency(0)).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Passed)
+	for _, r := range result.Results {
+		assert.Len(t, r.Conversation, 2, "the runs do not share their conversation")
+	}
+	assert.NotEqual(t, result.Results[0].RunID, result.Results[1].RunID)
+}
+
+func TestSuite_Run_FailFast(t *testing.T) {
+	var started atomic.Int32
+	// The failing scenario waits for the slow one to be running, to fail while it runs
+	slowStarted := make(chan struct{})
+	newScenario := func(success bool, agentDelay time.Duration) Scenario {
+		agent := &mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			started.Add(1)
+			if !success {
+				<-slowStarted
+			}
+			if agentDelay > 0 {
+				close(slowStarted)
+			}
+			select {
+			case <-time.After(agentDelay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+		}}
+		testingAgent := &mockTestingAgent{
+			generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+				if firstMessage {
+					msg := "User message"
+					return &msg, nil, nil
+				}
+				return nil, &Result{Success: success}, nil
+			},
+		}
+		return NewScenario(WithAgent(agent), WithTestingAgent(testingAgent))
+	}
+
+	suite := NewSuite(
+		[]Scenario{
+			newScenario(false, 0),
+			newScenario(true, time.Minute),
+			newScenario(true, 0),
+			newScenario(true, 0),
+		},
+		WithConcurrency(2),
+		WithFailFast(FailFast{MaxFailures: 1}),
+	)
+
+	result, err := suite.Run(context.Background())
+
+	require.NoError(t, err, "skipped scenarios are not errors of the suite")
+	assert.Equal(t, 0, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 0, result.Errored)
+	assert.Equal(t, 3, result.Skipped)
+	assert.Equal(t, int32(2), started.Load(), "the scenarios not started yet are skipped")
+	assert.ErrorIs(t, result.Errors[1], ErrSkipped, "the running scenarios are cancelled")
+	assert.ErrorIs(t, result.Errors[2], ErrSkipped)
+	assert.ErrorIs(t, result.Errors[3], ErrSkipped)
+	assert.Less(t, result.TotalDuration, time.Minute)
+}
+
+func TestFailFast_reached(t *testing.T) {
+	assert.False(t, FailFast{}.reached(AggregateSummary{Total: 10, Failed: 10}))
+	assert.True(t, FailFast{MaxFailures: 2}.reached(AggregateSummary{Total: 10, Failed: 2}))
+	assert.False(t, FailFast{MaxFailureRate: 0.3}.reached(AggregateSummary{Total: 10, Failed: 2}))
+	assert.True(t, FailFast{MaxFailureRate: 0.3}.reached(AggregateSummary{Total: 10, Failed: 3}))
+}
+
+func TestSuite_Run_Schedule(t *testing.T) {
+	var started []string
+	newScenario := func(id string) Scenario {
+		return NewScenario(
+			WithID(id),
+			WithAgent(&mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+				started = append(started, id)
+				return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+			}}),
+			WithTestingAgent(&mockTestingAgent{}),
+		)
+	}
+	previous := []*Result{
+		{ScenarioID: "a", Success: true, TotalDurationNSec: 30 * time.Millisecond},
+		{ScenarioID: "b", Success: false, TotalDurationNSec: 20 * time.Millisecond},
+		{ScenarioID: "c", Success: true, TotalDurationNSec: 10 * time.Millisecond},
+	}
+
+	for _, tt := range []struct {
+		schedule Schedule
+		want     []string
+	}{
+		{ScheduleInOrder, []string{"a", "b", "c", "d"}},
+		{ScheduleFailedFirst, []string{"b", "d", "a", "c"}},
+		{ScheduleShortestFirst, []string{"c", "b", "a", "d"}},
+	} {
+		started = nil
+		scenarios := []Scenario{newScenario("a"), newScenario("b"), newScenario("c"), newScenario("d")}
+
+		result, err := NewSuite(scenarios, WithSchedule(tt.schedule, previous)).Run(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, started)
+		assert.Equal(t, "a", result.Results[0].ScenarioID, "results are in the order of the suite")
+	}
+}
+
+func TestSuite_Run_FailFastAgentUnavailable(t *testing.T) {
+	calls := 0
+	agent := &mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+		calls++
+		return nil, errors.New("connection refused")
+	}}
+	newScenario := func() Scenario {
+		return NewScenario(
+			WithAgent(agent),
+			WithTestingAgent(&mockTestingAgent{}),
+			WithRetryPolicy(RetryPolicy{
+				MaxAttempts:             5,
+				MaxIdenticalAgentErrors: 2,
+				RetryableErrors:         func(err error) bool { return true },
+			}),
+		)
+	}
+
+	result, err := NewSuite(
+		[]Scenario{newScenario(), newScenario(), newScenario()},
+		WithFailFast(FailFast{AgentUnavailable: true}),
+	).Run(context.Background())
+
+	require.ErrorIs(t, err, ErrAgentUnavailable)
+	assert.Equal(t, 2, calls, "the agent is not called again once unavailable")
+	assert.Equal(t, 1, result.Errored)
+	assert.Equal(t, 2, result.Skipped)
+}
+
+func TestSuite_Run_Shard(t *testing.T) {
+	var runs sync.Map
+	newScenario := func(id string) Scenario {
+		return NewScenario(
+			WithID(id),
+			WithAgent(&mockAgent{runFunc: func(ctx context.Context, message string) ([]Message, error) {
+				count, _ := runs.LoadOrStore(id, new(atomic.Int32))
+				count.(*atomic.Int32).Add(1)
+				return []Message{{Role: MessageRoleAssistant, Content: "Agent response"}}, nil
+			}}),
+			WithTestingAgent(&mockTestingAgent{}),
+		)
+	}
+	repeated := newScenario("repeated")
+	scenarios := []Scenario{repeated, repeated}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		scenarios = append(scenarios, newScenario(id))
+	}
+
+	var shards []*SuiteResult
+	for index := range 3 {
+		result, err := NewSuite(scenarios, WithShard(index, 3), WithConcurrency(0)).Run(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, result.Results[0] == nil, result.Results[1] == nil, "repetitions run in the same shard")
+		shards = append(shards, result)
+	}
+
+	merged, err := MergeSuiteResults(shards...)
+
+	require.NoError(t, err)
+	assert.Equal(t, len(scenarios), merged.Passed)
+	for i, result := range merged.Results {
+		require.NotNil(t, result, "scenario %d runs in a shard", i)
+	}
+	runs.Range(func(id, count any) bool {
+		want := int32(1)
+		if id == "repeated" {
+			want = 2
+		}
+		assert.Equal(t, want, count.(*atomic.Int32).Load(), "scenario %s runs once", id)
+		return true
+	})
+
+	_, err = MergeSuiteResults(shards[0], shards[0])
+	assert.ErrorContains(t, err, "ran in more than one shard")
+	_, err = NewSuite(scenarios, WithShard(3, 3)).Run(context.Background())
+	assert.EqualError(t, err, "invalid shard 3 of 3")
+}
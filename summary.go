@@ -0,0 +1,87 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// summarizerSystemMessage is the system message of the model keeping the running summary.
+const summarizerSystemMessage = `You keep the running summary of a conversation between a user and an AI agent, read by the user to go on with the conversation.
+Update the summary with the new messages. Keep the facts, the requests and the answers of both sides, the details the user gave and the commitments of the agent. Be concise, write in the third person and only return the summary.`
+
+// simulatorConversation returns the conversation shown to the testing agent to generate the
+// message of the simulated user. With WithRunningSummary, once the conversation exceeds the
+// token budget, its oldest messages are folded into the running summary, leaving the most
+// recent messages within half of the budget.
+func (s *scenario) simulatorConversation(ctx context.Context) ([]Message, error) {
+	conversation := s.judgedConversation()
+	if s.summarizer == nil {
+		return conversation, nil
+	}
+	if s.summarized > len(conversation) {
+		// Turns were redone, start the summary over
+		s.summary, s.summarized = "", 0
+	}
+
+	if ConversationStats(conversation[s.summarized:]).EstimatedTokens > s.summaryBudget {
+		keep := len(conversation)
+		for keep > s.summarized && ConversationStats(conversation[keep-1:]).EstimatedTokens <= s.summaryBudget/2 {
+			keep--
+		}
+		keep = min(keep, len(conversation)-1)
+		// Tool results are summarized with the tool calls they answer
+		for keep < len(conversation)-1 && conversation[keep].Role == MessageRoleTool {
+			keep++
+		}
+		if keep > s.summarized {
+			summary, err := s.summarize(ctx, conversation[s.summarized:keep])
+			if err != nil {
+				return nil, err
+			}
+			s.summary, s.summarized = summary, keep
+		}
+	}
+	if s.summarized == 0 {
+		return conversation, nil
+	}
+
+	return append([]Message{{
+		Role:    MessageRoleSystem,
+		Content: "<summary>\n" + s.summary + "\n</summary>\nThis is the summary of the earlier turns of the conversation, the latest turns follow.",
+	}}, conversation[s.summarized:]...), nil
+}
+
+// summarize asks the summarizer for the running summary updated with the messages.
+func (s *scenario) summarize(ctx context.Context, messages []Message) (string, error) {
+	var b strings.Builder
+	if s.summary != "" {
+		b.WriteString("<summary>\n" + s.summary + "\n</summary>\n")
+	}
+	b.WriteString("<messages>\n")
+	for _, message := range messages {
+		content := message.Content
+		for _, toolCall := range message.ToolCalls {
+			if toolCall.Function != nil {
+				content += fmt.Sprintf(" [calls %s]", toolCall.Function.Name)
+			}
+		}
+		fmt.Fprintf(&b, "%s: %s\n", message.Role, content)
+	}
+	b.WriteString("</messages>")
+
+	maxTokens := int64(s.summaryBudget / 2)
+	response, err := s.summarizer.Completion(ContextWithComponent(ctx, ComponentSummarizer), []Message{
+		{Role: MessageRoleSystem, Content: summarizerSystemMessage},
+		{Role: MessageRoleUser, Content: b.String()},
+	}, nil, &maxTokens, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize the conversation: %w", err)
+	}
+	if len(response.Choices) == 0 || response.Choices[0].Message.Content == "" {
+		return "", errors.New("failed to summarize the conversation: empty summary")
+	}
+
+	return response.Choices[0].Message.Content, nil
+}
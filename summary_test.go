@@ -0,0 +1,101 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_RunningSummary(t *testing.T) {
+	var summarized []string
+	summarizer := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			summarized = append(summarized, messages[1].Content)
+			assert.Equal(t, int64(75), *maxTokens)
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{Content: fmt.Sprintf("summary %d", len(summarized))},
+			}}}, nil
+		},
+	}
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, Content: strings.Repeat("a", 400)}}, nil
+		},
+	}
+	var simulated [][]Message
+	var judged []Message
+	testingAgent := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if lastMessage {
+				judged = conversation
+				return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+			}
+			simulated = append(simulated, conversation)
+			msg := "hi"
+			return &msg, nil, nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(testingAgent),
+		WithMaxTurns(4),
+		WithRunningSummary(summarizer, 150),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Len(t, judged, 8, "the verdict is given on the whole conversation")
+
+	require.Len(t, simulated, 4)
+	assert.Empty(t, simulated[0])
+	assert.Len(t, simulated[1], 2)
+	require.Len(t, simulated[2], 2)
+	assert.Equal(t, MessageRoleSystem, simulated[2][0].Role)
+	assert.Contains(t, simulated[2][0].Content, "<summary>\nsummary 1\n</summary>")
+	assert.Equal(t, strings.Repeat("a", 400), simulated[2][1].Content)
+	require.Len(t, simulated[3], 2)
+	assert.Contains(t, simulated[3][0].Content, "<summary>\nsummary 2\n</summary>")
+
+	require.Len(t, summarized, 2)
+	assert.True(t, strings.HasPrefix(summarized[0], "<messages>\nuser: hi\nassistant: aaaa"))
+	assert.True(t, strings.HasPrefix(summarized[1], "<summary>\nsummary 1\n</summary>\n<messages>\n"))
+}
+
+func TestScenario_Run_RunningSummaryEarlyVerdict(t *testing.T) {
+	summarizer := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{Content: "summary"},
+			}}}, nil
+		},
+	}
+	agent := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{{Role: MessageRoleAssistant, Content: strings.Repeat("a", 400)}}, nil
+		},
+	}
+	testingAgent := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if len(conversation) > 0 && conversation[0].Role == MessageRoleSystem || lastMessage {
+				return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+			}
+			msg := "hi"
+			return &msg, nil, nil
+		},
+	}
+
+	result, err := NewScenario(
+		WithAgent(agent),
+		WithTestingAgent(testingAgent),
+		WithRunningSummary(summarizer, 150),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, result.Conversation, 4)
+	assert.Equal(t, MessageRoleUser, result.Conversation[0].Role)
+}
@@ -0,0 +1,142 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data the Go template placeholders of the description, strategy and
+// criteria are resolved against at the start of every run, e.g. "{{.Fixture.OrderID}}" or
+// "{{.Env.STORE_NAME}}". Referencing a missing key fails the run before the agent is called.
+type TemplateData struct {
+	// ScenarioID is the identifier of the scenario, see Result.ScenarioID.
+	ScenarioID string
+
+	// Seed is the seed of the run.
+	Seed int64
+
+	// Env is the environment variables of the process.
+	Env map[string]string
+
+	// Fixture is the fixtures declared with WithFixture.
+	Fixture map[string]any
+}
+
+// scenarioTemplates are the templates of the texts of a scenario using placeholders.
+type scenarioTemplates struct {
+	// description is the raw description, scenario IDs are generated from it
+	description string
+
+	// strategy is the raw strategy
+	strategy string
+
+	descriptionTemplate     *template.Template
+	strategyTemplate        *template.Template
+	successCriteriaTemplate []*template.Template
+	failureCriteriaTemplate []*template.Template
+}
+
+// parseTemplates parses the texts of the scenario as templates when any of them has a
+// placeholder.
+func (s *scenario) parseTemplates() error {
+	texts := append([]string{s.description, s.strategy}, s.successCriteria...)
+	texts = append(texts, s.failureCriteria...)
+	if !strings.Contains(strings.Join(texts, "\n"), "{{") {
+		return nil
+	}
+
+	parse := func(name, text string) (*template.Template, error) {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %w", name, err)
+		}
+		return tmpl, nil
+	}
+	parseAll := func(name string, texts []string) ([]*template.Template, error) {
+		templates := make([]*template.Template, len(texts))
+		for i, text := range texts {
+			tmpl, err := parse(fmt.Sprintf("%s %d", name, i), text)
+			if err != nil {
+				return nil, err
+			}
+			templates[i] = tmpl
+		}
+		return templates, nil
+	}
+
+	templates := &scenarioTemplates{description: s.description, strategy: s.strategy}
+	var err error
+	if templates.descriptionTemplate, err = parse("description", s.description); err != nil {
+		return err
+	}
+	if templates.strategyTemplate, err = parse("strategy", s.strategy); err != nil {
+		return err
+	}
+	if templates.successCriteriaTemplate, err = parseAll("success criterion", s.successCriteria); err != nil {
+		return err
+	}
+	if templates.failureCriteriaTemplate, err = parseAll("failure criterion", s.failureCriteria); err != nil {
+		return err
+	}
+	s.templates = templates
+
+	return nil
+}
+
+// resolveTemplates resolves the placeholders of the texts of the scenario for the run.
+func (s *scenario) resolveTemplates() error {
+	if s.templates == nil {
+		return nil
+	}
+
+	data := TemplateData{
+		ScenarioID: s.scenarioID(),
+		Seed:       s.runSeed,
+		Env:        map[string]string{},
+		Fixture:    s.fixtures,
+	}
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		data.Env[name] = value
+	}
+	if data.Fixture == nil {
+		data.Fixture = map[string]any{}
+	}
+
+	execute := func(tmpl *template.Template) (string, error) {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, data); err != nil {
+			return "", fmt.Errorf("failed to resolve %s template: %w", tmpl.Name(), err)
+		}
+		return sb.String(), nil
+	}
+	executeAll := func(templates []*template.Template) ([]string, error) {
+		texts := make([]string, len(templates))
+		for i, tmpl := range templates {
+			text, err := execute(tmpl)
+			if err != nil {
+				return nil, err
+			}
+			texts[i] = text
+		}
+		return texts, nil
+	}
+
+	var err error
+	if s.description, err = execute(s.templates.descriptionTemplate); err != nil {
+		return err
+	}
+	if s.strategy, err = execute(s.templates.strategyTemplate); err != nil {
+		return err
+	}
+	if s.successCriteria, err = executeAll(s.templates.successCriteriaTemplate); err != nil {
+		return err
+	}
+	if s.failureCriteria, err = executeAll(s.templates.failureCriteriaTemplate); err != nil {
+		return err
+	}
+
+	return nil
+}
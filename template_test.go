@@ -0,0 +1,77 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_Templates(t *testing.T) {
+	t.Setenv("STORE_NAME", "Acme")
+	ctx := context.Background()
+	var gotDescription, gotStrategy string
+	var gotSuccessCriteria, gotFailureCriteria []string
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			gotDescription, gotStrategy = description, strategy
+			gotSuccessCriteria, gotFailureCriteria = successCriteria, failureCriteria
+			if firstMessage {
+				msg := "where is my order?"
+				return &msg, nil, nil
+			}
+			return nil, NewSuccessPartialResult(conversation, "done", successCriteria), nil
+		},
+	}
+	mockAgentInst := &mockFixtureLoader{}
+
+	s := NewScenario(
+		WithDescription("User asks {{.Env.STORE_NAME}} about order {{.Fixture.OrderID}}"),
+		WithStrategy("Mention order {{.Fixture.OrderID}}"),
+		WithSuccessCriteria("Agent gives the status of order {{.Fixture.OrderID}}"),
+		WithFailureCriteria("Agent mentions an order other than {{.Fixture.OrderID}}"),
+		WithFixture("OrderID", "A-42"),
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithSeed(3),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "User asks Acme about order A-42", gotDescription)
+	assert.Contains(t, gotStrategy, "Mention order A-42")
+	assert.Equal(t, []string{"Agent gives the status of order A-42"}, gotSuccessCriteria)
+	assert.Equal(t, []string{"Agent mentions an order other than A-42"}, gotFailureCriteria)
+	assert.Equal(t, generateScenarioID("User asks {{.Env.STORE_NAME}} about order {{.Fixture.OrderID}}"), result.ScenarioID, "the ID is generated from the raw description")
+}
+
+func TestScenario_Run_TemplateErrors(t *testing.T) {
+	ctx := context.Background()
+	runs := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			runs++
+			return []Message{{Role: MessageRoleAssistant, Content: "Hello"}}, nil
+		},
+	}
+
+	_, err := NewScenario(
+		WithDescription("User asks about order {{.Fixture.OrderID"),
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+	).Run(ctx)
+	assert.ErrorContains(t, err, "failed to parse description template")
+
+	_, err = NewScenario(
+		WithDescription("User asks about order {{.Fixture.OrderID}}"),
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+	).Run(ctx)
+	assert.ErrorContains(t, err, "failed to resolve description template")
+	assert.ErrorContains(t, err, `map has no entry for key "OrderID"`)
+
+	assert.Zero(t, runs, "the agent is not called")
+}
@@ -2,8 +2,12 @@ package scenario
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/langwatch/scenario-go/internal/ptr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -80,8 +84,9 @@ func TestTestingAgent_GenerateNextMessage_Success(t *testing.T) {
 					Function: &ToolCallFunction{
 						Name: "finish_test",
 						Arguments: map[string]interface{}{
-							"verdict":   "success",
-							"reasoning": "All criteria met",
+							"verdict":    "success",
+							"reasoning":  "All criteria met",
+							"confidence": 0.8,
 							"details": map[string]interface{}{
 								"met_criteria":       []string{"success1"},
 								"unmet_criteria":     []string{},
@@ -129,6 +134,8 @@ func TestTestingAgent_GenerateNextMessage_Success(t *testing.T) {
 	assert.Contains(t, result.MetCriteria, "success1")
 	assert.Empty(t, result.UnmetCriteria)
 	assert.Empty(t, result.TriggeredFailures)
+	require.NotNil(t, result.Confidence)
+	assert.Equal(t, 0.8, *result.Confidence)
 }
 
 func TestTestingAgent_GenerateNextMessage_Failure(t *testing.T) {
@@ -350,7 +357,7 @@ func TestTestingAgent_GenerateNextMessage_Error_InvalidToolCall(t *testing.T) {
 	)
 
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "tool call is not a function")
+	assert.Contains(t, err.Error(), "no content returned in choice")
 	assert.Nil(t, msg)
 	assert.Nil(t, result)
 }
@@ -405,3 +412,530 @@ func TestTestingAgent_GenerateNextMessage_Error_InvalidFinishTestParams(t *testi
 	assert.Nil(t, msg)
 	assert.Nil(t, result)
 }
+
+func TestTestingAgent_GenerateNextMessage_LooseVerdictSchema(t *testing.T) {
+	ctx := context.Background()
+	schema := SchemaPresetLoose
+	schema.ToolName = "submit_verdict"
+	schema.SuccessVerdict = "pass"
+
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			require.Len(t, tools, 1)
+			assert.Equal(t, "submit_verdict", tools[0].Function.Name)
+			assert.False(t, tools[0].Function.Strict)
+			assert.NotContains(t, tools[0].Function.Parameters["properties"], "details")
+			assert.Contains(t, messages[0].Content, "use the submit_verdict tool")
+
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{
+					{
+						Message: LLMCompletionResponseChoiceMessage{
+							ToolCalls: []ToolCall{
+								{
+									Type: ToolTypeFunction,
+									Function: &ToolCallFunction{
+										Name: "submit_verdict",
+										Arguments: map[string]interface{}{
+											"verdict":      "pass",
+											"reasoning":    "All criteria met",
+											"met_criteria": []any{"success1"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	agent := NewTestingAgent(mockLLM, WithVerdictSchema(schema))
+	msg, result, err := agent.GenerateNextMessage(
+		ctx,
+		"Test description",
+		"Test strategy",
+		[]string{"success1"},
+		[]string{"failure1"},
+		[]Message{},
+		false,
+		true,
+	)
+
+	require.NoError(t, err)
+	require.Nil(t, msg)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{"success1"}, result.MetCriteria)
+}
+
+func TestTestingAgent_GenerateNextMessage_MultipleToolCalls(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name            string
+		message         LLMCompletionResponseChoiceMessage
+		expectedMessage *string
+		expectedSuccess bool
+	}{
+		{
+			name: "prefers finish_test over other tool calls",
+			message: LLMCompletionResponseChoiceMessage{
+				Content: "let me check",
+				ToolCalls: []ToolCall{
+					{Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "search"}},
+					{Type: "invalid_type"},
+					{Type: ToolTypeFunction, Function: &ToolCallFunction{
+						Name: "finish_test",
+						Arguments: map[string]interface{}{
+							"verdict":   "success",
+							"reasoning": "All criteria met",
+							"details": map[string]interface{}{
+								"met_criteria":       []string{"success1"},
+								"unmet_criteria":     []string{},
+								"triggered_failures": []string{},
+							},
+						},
+					}},
+				},
+			},
+			expectedSuccess: true,
+		},
+		{
+			name: "falls back to content when only unknown tools are called",
+			message: LLMCompletionResponseChoiceMessage{
+				Content: "hello there",
+				ToolCalls: []ToolCall{
+					{Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "search"}},
+				},
+			},
+			expectedMessage: ptr.Ptr("hello there"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLLM := &mockLLMCompletion{
+				completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+					return &LLMCompletionResponse{
+						Choices: []LLMCompletionResponseChoice{{Message: tt.message}},
+					}, nil
+				},
+			}
+
+			agent := NewTestingAgent(mockLLM)
+			msg, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"success1"}, []string{"failure1"}, []Message{}, false, false)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMessage, msg)
+			if tt.expectedMessage == nil {
+				require.NotNil(t, result)
+				assert.Equal(t, tt.expectedSuccess, result.Success)
+			}
+		})
+	}
+}
+
+func TestTestingAgent_GenerateNextMessage_RoleMapping(t *testing.T) {
+	ctx := context.Background()
+	var sent []Message
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			sent = messages
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "next"}}},
+			}, nil
+		},
+	}
+
+	conversation := []Message{
+		{Role: MessageRoleDeveloper, Content: "respond in french"},
+		{Role: MessageRoleUser, Content: "hi"},
+		{Role: MessageRoleAssistant, Content: "bonjour"},
+	}
+
+	agent := NewTestingAgent(mockLLM)
+	_, _, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, conversation, false, true)
+
+	require.NoError(t, err)
+	var roles []MessageRole
+	for _, message := range sent {
+		roles = append(roles, message.Role)
+	}
+	assert.Equal(t, []MessageRole{
+		MessageRoleSystem,
+		MessageRoleUser,      // the agent greeting
+		MessageRoleUser,      // the instructions of the agent under test
+		MessageRoleAssistant, // the simulated user
+		MessageRoleUser,      // the agent under test
+		MessageRoleUser,      // the finish test instructions
+	}, roles)
+	assert.Equal(t, `<context_message role="developer">respond in french</context_message>`, sent[2].Content)
+	assert.Equal(t, MessageRoleUser, conversation[1].Role, "the conversation of the caller is not modified")
+	assert.Equal(t, MessageRoleDeveloper, conversation[0].Role, "the conversation of the caller is not modified")
+
+	for _, blind := range []bool{false, true} {
+		var opts []TestingAgentOption
+		if blind {
+			opts = append(opts, WithBlindSimulator())
+		}
+		conversation := []Message{
+			{Role: MessageRoleSystem, Content: "you are a travel agent"},
+			{Role: MessageRoleUser, Content: "hi"},
+			{Role: MessageRoleAssistant, Content: "where to?"},
+		}
+		_, _, err := NewTestingAgent(mockLLM, opts...).GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, conversation, false, false)
+
+		require.NoError(t, err)
+		assert.Contains(t, sent[0].Content, "<context_message>", "blind: %v", blind)
+		for _, message := range sent[1:] {
+			assert.NotContains(t, []MessageRole{MessageRoleSystem, MessageRoleDeveloper}, message.Role, "the simulator does not receive the instructions of the agent as its own, blind: %v", blind)
+		}
+	}
+}
+
+func TestTestingAgent_GenerateNextMessage_TextVerdictProtocol(t *testing.T) {
+	ctx := context.Background()
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			assert.Empty(t, tools)
+			assert.Nil(t, toolChoice)
+			assert.Contains(t, messages[0].Content, "<verdict>")
+
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{
+					Content: "Here is my verdict:\n<verdict>\n```json\n{\"verdict\": \"failure\", \"reasoning\": \"meat\", \"unmet_criteria\": [\"vegetarian\"]}\n```\n</verdict>",
+				}}},
+			}, nil
+		},
+	}
+
+	agent := NewTestingAgent(mockLLM, WithVerdictProtocol(VerdictProtocolText))
+	msg, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"vegetarian"}, nil, nil, false, true)
+
+	require.NoError(t, err)
+	assert.Nil(t, msg)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Equal(t, "meat", result.Reasoning)
+	assert.Equal(t, []string{"vegetarian"}, result.UnmetCriteria)
+	assert.Equal(t, []string{}, result.MetCriteria)
+}
+
+func TestTestingAgent_GenerateNextMessage_TextVerdictProtocol_NextMessage(t *testing.T) {
+	ctx := context.Background()
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "any veggie recipes"}}},
+			}, nil
+		},
+	}
+
+	agent := NewTestingAgent(mockLLM, WithVerdictProtocol(VerdictProtocolText))
+	msg, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, nil, true, false)
+
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	require.NotNil(t, msg)
+	assert.Equal(t, "any veggie recipes", *msg)
+}
+
+func TestTestingAgent_GenerateNextMessage_ToolCallingUnsupportedFallback(t *testing.T) {
+	newRunContext := func() context.Context {
+		return withRunInfo(context.Background(), runInfo{textVerdictFallback: &atomic.Bool{}})
+	}
+	ctx := newRunContext()
+	var calls []bool
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			calls = append(calls, len(tools) > 0)
+			if len(tools) > 0 {
+				return nil, errors.New("400 Bad Request: llama3 does not support tools")
+			}
+
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{
+					Content: `<verdict>{"verdict": "success", "reasoning": "all good", "met_criteria": ["vegetarian"]}`,
+				}}},
+			}, nil
+		},
+	}
+
+	agent := NewTestingAgent(mockLLM)
+	_, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"vegetarian"}, nil, nil, false, true)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{"vegetarian"}, result.MetCriteria)
+
+	_, _, err = agent.GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"vegetarian"}, nil, nil, false, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, calls, "the text protocol is kept for the rest of the run")
+
+	_, _, err = agent.GenerateNextMessage(newRunContext(), "Test description", "Test strategy", []string{"vegetarian"}, nil, nil, false, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false, true, false}, calls, "other runs try tool calling again")
+}
+
+func TestTestingAgent_GenerateNextMessage_TextVerdictProtocol_MissingVerdict(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		replies []string
+		wantErr string
+	}{
+		{
+			name:    "retried",
+			replies: []string{"I think the agent did fine.", `<verdict>{"verdict": "success", "reasoning": "all good", "met_criteria": ["vegetarian"]}</verdict>`},
+		},
+		{
+			name:    "still missing",
+			replies: []string{"I think the agent did fine.", "Really, it did fine."},
+			wantErr: "testing agent gave no verdict on the last message",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls [][]Message
+			mockLLM := &mockLLMCompletion{
+				completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+					calls = append(calls, messages)
+					return &LLMCompletionResponse{
+						Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: tt.replies[len(calls)-1]}}},
+					}, nil
+				},
+			}
+
+			agent := NewTestingAgent(mockLLM, WithVerdictProtocol(VerdictProtocolText))
+			msg, result, err := agent.GenerateNextMessage(context.Background(), "Test description", "Test strategy", []string{"vegetarian"}, nil, nil, false, true)
+
+			assert.Nil(t, msg, "the reply is not passed off as a message of the user")
+			require.Len(t, calls, 2)
+			retry := calls[1]
+			assert.Equal(t, Message{Role: MessageRoleAssistant, Content: tt.replies[0]}, retry[len(retry)-2])
+			assert.Contains(t, retry[len(retry)-1].Content, "<missing_verdict>")
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, result)
+			assert.True(t, result.Success)
+		})
+	}
+}
+
+func TestExtractTextVerdict(t *testing.T) {
+	for _, content := range []string{
+		`no verdict here`,
+		`<verdict>not json</verdict>`,
+		`<verdict>{"reasoning": "missing verdict"}</verdict>`,
+	} {
+		_, ok := extractTextVerdict(content)
+		assert.False(t, ok, content)
+	}
+
+	args, ok := extractTextVerdict("<verdict>{\"verdict\": \"a\"}</verdict> then <verdict>{\"verdict\": \"b\"}")
+	require.True(t, ok)
+	assert.Equal(t, "b", args["verdict"], "the last verdict block is used")
+}
+
+func TestTestingAgent_GenerateNextMessage_UserStyle(t *testing.T) {
+	ctx := context.Background()
+	var systemMessage string
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			systemMessage = messages[0].Content
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "Good morning"}}},
+			}, nil
+		},
+	}
+
+	agent := NewTestingAgent(mockLLM, WithUserStyle(UserStyle{Register: RegisterFormal, Dialect: "British English"}))
+	_, _, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", nil, nil, nil, true, false)
+
+	require.NoError(t, err)
+	assert.Contains(t, systemMessage, registerInstructions[RegisterFormal]+"\nWrite in British English.")
+	assert.NotContains(t, systemMessage, "all lowercase")
+}
+
+func TestTestingAgent_GenerateNextMessage_BlindSimulator(t *testing.T) {
+	ctx := context.Background()
+	type call struct {
+		criteria bool
+		tools    bool
+	}
+	var calls []call
+	verdict := false
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			calls = append(calls, call{criteria: strings.Contains(messages[0].Content, "secret criterion"), tools: len(tools) > 0})
+			if len(tools) > 0 && verdict {
+				return &LLMCompletionResponse{
+					Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{ToolCalls: []ToolCall{{
+						Type:     ToolTypeFunction,
+						Function: &ToolCallFunction{Name: "finish_test", Arguments: map[string]any{"verdict": "success", "reasoning": "done"}},
+					}}}}},
+				}, nil
+			}
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{Content: "next"}}},
+			}, nil
+		},
+	}
+	agent := NewTestingAgent(mockLLM, WithBlindSimulator())
+	criteria := []string{"secret criterion"}
+
+	msg, _, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", criteria, nil, nil, true, false)
+	require.NoError(t, err)
+	assert.Equal(t, "next", *msg)
+	assert.Equal(t, []call{{criteria: false, tools: false}}, calls, "the first message is not judged")
+
+	calls = nil
+	msg, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", criteria, nil, nil, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "next", *msg)
+	assert.Equal(t, []call{{criteria: true, tools: true}, {criteria: false, tools: false}}, calls, "the conversation is judged, then the next message is generated blind")
+
+	calls = nil
+	msg, result, err = agent.GenerateNextMessage(withJudgeSkipped(ctx), "Test description", "Test strategy", criteria, nil, nil, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Equal(t, "next", *msg)
+	assert.Equal(t, []call{{criteria: false, tools: false}}, calls, "a turn not sampled for judging is not judged")
+
+	calls = nil
+	verdict = true
+	msg, result, err = agent.GenerateNextMessage(ctx, "Test description", "Test strategy", criteria, nil, nil, false, false)
+	require.NoError(t, err)
+	assert.Nil(t, msg)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, []call{{criteria: true, tools: true}}, calls)
+}
+
+func TestTestingAgent_GenerateNextMessage_Evidence(t *testing.T) {
+	ctx := context.Background()
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{ToolCalls: []ToolCall{{
+					Type: ToolTypeFunction,
+					Function: &ToolCallFunction{Name: "finish_test", Arguments: map[string]any{
+						"verdict":   "failure",
+						"reasoning": "The recipe has meat",
+						"details": map[string]any{
+							"met_criteria":       []any{"recipe"},
+							"unmet_criteria":     []any{"vegetarian"},
+							"triggered_failures": []any{},
+							"evidence": []any{
+								map[string]any{"criterion": "recipe", "message_indices": []any{1.0}},
+								map[string]any{"criterion": "vegetarian", "message_indices": []any{1.0, 7.0, -1.0}},
+							},
+						},
+					}},
+				}}}}},
+			}, nil
+		},
+	}
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "dinner recipe"},
+		{Role: MessageRoleAssistant, Content: "Chicken curry"},
+	}
+
+	agent := NewTestingAgent(mockLLM)
+	_, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"recipe", "vegetarian"}, nil, conversation, false, true)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, map[string][]int{"recipe": {1}, "vegetarian": {1}}, result.Evidence, "indices outside of the conversation are dropped")
+	assert.Equal(t, []CriterionResult{
+		{Criterion: "recipe", Status: CriterionMet, Evidence: []int{1}},
+		{Criterion: "vegetarian", Status: CriterionUnmet, Evidence: []int{1}},
+	}, result.CriterionResults())
+}
+
+func TestTestingAgent_GenerateNextMessage_UserSelfReport(t *testing.T) {
+	ctx := context.Background()
+	var systemMessage string
+	var verdictParameters map[string]any
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			systemMessage = messages[0].Content
+			verdictParameters = tools[0].Function.Parameters
+			return &LLMCompletionResponse{
+				Choices: []LLMCompletionResponseChoice{{Message: LLMCompletionResponseChoiceMessage{ToolCalls: []ToolCall{{
+					Type: ToolTypeFunction,
+					Function: &ToolCallFunction{Name: "finish_test", Arguments: map[string]any{
+						"verdict":   "success",
+						"reasoning": "all criteria met",
+						"user_self_report": map[string]any{
+							"goal_achieved": false,
+							"satisfaction":  2.0,
+							"comment":       "polite, but i still don't know when my order arrives",
+						},
+					}},
+				}}}}},
+			}, nil
+		},
+	}
+
+	agent := NewTestingAgent(mockLLM, WithUserSelfReport())
+	_, result, err := agent.GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"Agent is polite"}, nil, nil, false, true)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Success)
+	assert.Equal(t, &UserSelfReport{GoalAchieved: false, Satisfaction: 2, Comment: "polite, but i still don't know when my order arrives"}, result.UserSelfReport)
+	assert.Contains(t, systemMessage, "user_self_report")
+	assert.Contains(t, verdictParameters["properties"], "user_self_report")
+	assert.Contains(t, verdictParameters["required"], "user_self_report")
+
+	_, result, err = NewTestingAgent(mockLLM).GenerateNextMessage(ctx, "Test description", "Test strategy", []string{"Agent is polite"}, nil, nil, false, true)
+	require.NoError(t, err)
+	assert.NotContains(t, systemMessage, "user_self_report")
+	assert.NotContains(t, verdictParameters["properties"], "user_self_report")
+}
+
+func TestTestingAgent_GenerateNextMessage_ToolCalls(t *testing.T) {
+	ctx := context.Background()
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "refund my order 42"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{{
+			ID:       "call_1",
+			Type:     ToolTypeFunction,
+			Function: &ToolCallFunction{Name: "refund", Arguments: map[string]any{"order_id": "42"}},
+		}}},
+		{Role: MessageRoleTool, ToolCallID: "call_1", Content: "refunded"},
+		{Role: MessageRoleAssistant, Content: "Your order was refunded."},
+	}
+
+	var sent []Message
+	mockLLM := &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			sent = messages
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{Content: "thanks"},
+			}}}, nil
+		},
+	}
+
+	_, _, err := NewTestingAgent(mockLLM).GenerateNextMessage(ctx, "description", "strategy", []string{"the agent called the refund tool with the right order id"}, nil, conversation, false, false)
+
+	require.NoError(t, err)
+	assert.Contains(t, sent[0].Content, "between <tool_call> and <tool_result> tags")
+	require.Len(t, sent, 6, "messages are kept in place for the evidence indices")
+	assert.Equal(t, Message{Role: MessageRoleUser, Content: `<tool_call id="call_1" name="refund">{"order_id":"42"}</tool_call>`}, sent[3])
+	assert.Equal(t, Message{Role: MessageRoleUser, Content: `<tool_result id="call_1" name="refund">refunded</tool_result>`}, sent[4])
+	assert.Equal(t, MessageRoleUser, sent[5].Role)
+	assert.Equal(t, MessageRoleTool, conversation[2].Role, "the conversation is not modified")
+
+	_, _, err = NewTestingAgent(mockLLM).GenerateNextMessage(ctx, "description", "strategy", []string{"greets"}, nil, conversation[:1], false, false)
+
+	require.NoError(t, err)
+	assert.NotContains(t, sent[0].Content, "<tool_call>")
+}
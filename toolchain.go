@@ -0,0 +1,57 @@
+package scenario
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidateToolCallChain checks that every tool message of the conversation is the result of a
+// tool call of the assistant message it follows, and that every tool call has a result before
+// the conversation moves on, as providers require when the conversation is sent back to them.
+func ValidateToolCallChain(conversation []Message) error {
+	var pending []string
+	for i, message := range conversation {
+		if message.Role == MessageRoleTool {
+			index := slices.Index(pending, message.ToolCallID)
+			if index < 0 {
+				return fmt.Errorf("tool message %d answers unknown or already answered tool call %q", i, message.ToolCallID)
+			}
+			pending = slices.Delete(pending, index, index+1)
+			continue
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("message %d follows tool calls without results: %s", i, strings.Join(pending, ", "))
+		}
+		if message.Role == MessageRoleAssistant {
+			for _, toolCall := range message.ToolCalls {
+				pending = append(pending, toolCall.ID)
+			}
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("conversation ends with tool calls without results: %s", strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// hasToolMessages reports whether the conversation contains tool messages, agents returning
+// them are expected to return complete tool call chains.
+func hasToolMessages(conversation []Message) bool {
+	return slices.ContainsFunc(conversation, func(message Message) bool {
+		return message.Role == MessageRoleTool
+	})
+}
+
+// answeredToolCalls returns the IDs of the tool calls of the conversation with a result.
+func answeredToolCalls(conversation []Message) map[string]bool {
+	answered := map[string]bool{}
+	for _, message := range conversation {
+		if message.Role == MessageRoleTool {
+			answered[message.ToolCallID] = true
+		}
+	}
+	return answered
+}
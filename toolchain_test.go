@@ -0,0 +1,82 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateToolCallChain(t *testing.T) {
+	lookup := ToolCall{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "lookup_order"}}
+	track := ToolCall{ID: "call_2", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "track_parcel"}}
+	user := Message{Role: MessageRoleUser, Content: "where is my order?"}
+	calls := Message{Role: MessageRoleAssistant, ToolCalls: []ToolCall{lookup, track}}
+	lookupResult := Message{Role: MessageRoleTool, Content: "shipped", ToolCallID: "call_1"}
+	trackResult := Message{Role: MessageRoleTool, Content: "in transit", ToolCallID: "call_2"}
+	answer := Message{Role: MessageRoleAssistant, Content: "it is in transit"}
+
+	tests := []struct {
+		name         string
+		conversation []Message
+		wantErr      string
+	}{
+		{
+			name:         "complete chain",
+			conversation: []Message{user, calls, trackResult, lookupResult, answer},
+		},
+		{
+			name:         "unknown tool call",
+			conversation: []Message{user, lookupResult, answer},
+			wantErr:      `tool message 1 answers unknown or already answered tool call "call_1"`,
+		},
+		{
+			name:         "answered twice",
+			conversation: []Message{user, calls, lookupResult, lookupResult, trackResult, answer},
+			wantErr:      `tool message 3 answers unknown or already answered tool call "call_1"`,
+		},
+		{
+			name:         "missing result",
+			conversation: []Message{user, calls, lookupResult, answer},
+			wantErr:      "message 3 follows tool calls without results: call_2",
+		},
+		{
+			name:         "ends with tool calls",
+			conversation: []Message{user, calls},
+			wantErr:      "conversation ends with tool calls without results: call_1, call_2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToolCallChain(tt.conversation)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestScenario_Run_BrokenToolCallChain(t *testing.T) {
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{
+				{Role: MessageRoleTool, Content: "shipped", ToolCallID: "call_1"},
+				{Role: MessageRoleAssistant, Content: "it shipped"},
+			}, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+
+	_, err := s.Run(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "agent returned a broken tool call chain")
+}
@@ -0,0 +1,104 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// topicClassifierSystemMessage is the system message of the model classifying the turns
+// against the allowed topics.
+const topicClassifierSystemMessage = `You check that a conversation between a user and an AI agent stays on the topics the agent is meant to handle.
+Classify the latest turn of the conversation against the allowed topics. Small talk, greetings and clarifications about an allowed topic are on topic.
+Reply with "on-topic", or "off-topic: " followed by the topic of the turn in a few words.`
+
+// TopicGuard configures the topic drift guard of WithAllowedTopics.
+type TopicGuard struct {
+	// Topics are the allowed topics, e.g. "orders and deliveries" or "billing".
+	Topics []string
+
+	// Classifier is the model classifying every turn against the allowed topics, usually a
+	// cheap model.
+	Classifier LLMCompletion
+
+	// MaxOffTopicTurns is the number of consecutive off-topic turns that is a drift, 1 when
+	// zero.
+	MaxOffTopicTurns int
+
+	// Warn records a drift as a diagnostic of the result instead of failing the scenario.
+	Warn bool
+}
+
+// classify asks the classifier whether the turn is on the allowed topics, returning the topic
+// of an off-topic turn.
+func (g TopicGuard) classify(ctx context.Context, conversation []Message, turn []Message) (string, bool, error) {
+	var b strings.Builder
+	b.WriteString("<allowed_topics>\n")
+	for _, topic := range g.Topics {
+		b.WriteString("- " + topic + "\n")
+	}
+	b.WriteString("</allowed_topics>\n<conversation>\n")
+	for _, message := range conversation {
+		if message.Content != "" {
+			fmt.Fprintf(&b, "%s: %s\n", message.Role, message.Content)
+		}
+	}
+	b.WriteString("</conversation>\n<latest_turn>\n")
+	for _, message := range turn {
+		if message.Content != "" {
+			fmt.Fprintf(&b, "%s: %s\n", message.Role, message.Content)
+		}
+	}
+	b.WriteString("</latest_turn>")
+
+	response, err := g.Classifier.Completion(ctx, []Message{
+		{Role: MessageRoleSystem, Content: topicClassifierSystemMessage},
+		{Role: MessageRoleUser, Content: b.String()},
+	}, nil, nil, nil, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to classify the topic of the turn: %w", err)
+	}
+	if len(response.Choices) == 0 {
+		return "", false, errors.New("failed to classify the topic of the turn: no choices returned")
+	}
+
+	content := strings.TrimSpace(response.Choices[0].Message.Content)
+	if topic, ok := strings.CutPrefix(strings.ToLower(content), "off-topic"); ok {
+		return strings.TrimSpace(strings.TrimPrefix(topic, ":")), false, nil
+	}
+	return "", true, nil
+}
+
+// checkTopic classifies the turn starting at the given index of the conversation with the
+// topic guard, returning the triggered failure when the conversation drifted off the allowed
+// topics and the drift fails the scenario.
+func (s *scenario) checkTopic(ctx context.Context, turn, start int) (string, error) {
+	if s.topicGuard == nil {
+		return "", nil
+	}
+
+	topic, onTopic, err := s.topicGuard.classify(withAuditScope(ctx, ComponentGuardrail, turn), s.conversation[:start], s.conversation[start:])
+	if err != nil {
+		return "", err
+	}
+	if onTopic {
+		s.offTopicTurns = 0
+		return "", nil
+	}
+
+	s.offTopicTurns++
+	if s.offTopicTurns != max(s.topicGuard.MaxOffTopicTurns, 1) {
+		return "", nil
+	}
+	drift := fmt.Sprintf("The conversation drifted off the allowed topics for %d consecutive turns (%s)", s.offTopicTurns, topic)
+	if !s.topicGuard.Warn {
+		return drift, nil
+	}
+	RecordDiagnostic(withAuditScope(ctx, ComponentGuardrail, turn), Diagnostic{
+		Code:    DiagnosticTopicDrift,
+		Message: drift,
+	})
+
+	return "", nil
+}
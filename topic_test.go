@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// topicClassifier returns a classifier replying with the given classifications in order.
+func topicClassifier(t *testing.T, classifications ...string) *mockLLMCompletion {
+	calls := 0
+	return &mockLLMCompletion{
+		completionFunc: func(ctx context.Context, messages []Message, temperature *float64, maxTokens *int64, tools []Tool, toolChoice *string) (*LLMCompletionResponse, error) {
+			require.Less(t, calls, len(classifications))
+			assert.Contains(t, messages[1].Content, "<allowed_topics>\n- orders\n</allowed_topics>")
+			scope, _ := ctx.Value(auditScopeContextKey{}).(auditScope)
+			assert.Equal(t, ComponentGuardrail, scope.component)
+			classification := classifications[calls]
+			calls++
+			return &LLMCompletionResponse{Choices: []LLMCompletionResponseChoice{{
+				Message: LLMCompletionResponseChoiceMessage{Content: classification},
+			}}}, nil
+		},
+	}
+}
+
+// chattyTestingAgent is a testing agent talking until the last turn.
+func chattyTestingAgent() *mockTestingAgent {
+	return &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if lastMessage {
+				return nil, NewSuccessPartialResult(conversation, "ok", nil), nil
+			}
+			msg := "hi"
+			return &msg, nil, nil
+		},
+	}
+}
+
+func TestScenario_Run_TopicDrift(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(chattyTestingAgent()),
+		WithMaxTurns(5),
+		WithAllowedTopics(TopicGuard{
+			Topics:           []string{"orders"},
+			Classifier:       topicClassifier(t, "off-topic: weather", "on-topic", "Off-topic: weather", "off-topic: sports"),
+			MaxOffTopicTurns: 2,
+		}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Len(t, result.Conversation, 8)
+	require.Len(t, result.TriggeredFailures, 1)
+	assert.Contains(t, result.TriggeredFailures[0], "for 2 consecutive turns (sports)")
+}
+
+func TestScenario_Run_TopicDriftWarn(t *testing.T) {
+	result, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(chattyTestingAgent()),
+		WithMaxTurns(3),
+		WithAllowedTopics(TopicGuard{
+			Topics:     []string{"orders"},
+			Classifier: topicClassifier(t, "off-topic: weather", "off-topic: weather", "off-topic: weather"),
+			Warn:       true,
+		}),
+	).Run(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	require.Len(t, result.Diagnostics, 1, "a drift is reported once")
+	assert.Equal(t, DiagnosticTopicDrift, result.Diagnostics[0].Code)
+	assert.Equal(t, ComponentGuardrail, result.Diagnostics[0].Component)
+	assert.Equal(t, 0, result.Diagnostics[0].Turn)
+}
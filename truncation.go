@@ -0,0 +1,90 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeepLastAgentMessages returns a truncation policy keeping only the last n messages of every
+// run of consecutive assistant messages, e.g. the final answer of a turn after intermediate
+// reasoning dumps. The omitted messages are replaced with a note saying how many were omitted.
+func KeepLastAgentMessages(n int) Normalizer {
+	return func(conversation []Message) []Message {
+		kept := make([]Message, 0, len(conversation))
+		for start := 0; start < len(conversation); {
+			end := start
+			for end < len(conversation) && conversation[end].Role == MessageRoleAssistant {
+				end++
+			}
+			if end == start {
+				kept = append(kept, conversation[start])
+				start++
+				continue
+			}
+
+			if omitted := end - start - max(n, 0); omitted > 0 {
+				kept = append(kept, Message{
+					Role:    MessageRoleAssistant,
+					Content: fmt.Sprintf("[%d intermediate messages omitted]", omitted),
+				})
+				start += omitted
+			}
+			kept = append(kept, conversation[start:end]...)
+			start = end
+		}
+		return kept
+	}
+}
+
+// CollapseToolCalls is a truncation policy collapsing every run of consecutive assistant
+// messages made only of tool calls, and of the tool messages with their results, into a single
+// message listing the tools called.
+func CollapseToolCalls(conversation []Message) []Message {
+	collapsed := make([]Message, 0, len(conversation))
+	var tools []string
+	flush := func() {
+		if len(tools) > 0 {
+			collapsed = append(collapsed, Message{
+				Role:    MessageRoleAssistant,
+				Content: fmt.Sprintf("[called tools: %s]", strings.Join(tools, ", ")),
+			})
+			tools = nil
+		}
+	}
+	for _, message := range conversation {
+		if message.Role == MessageRoleTool && len(tools) > 0 {
+			continue
+		}
+		if message.Role != MessageRoleAssistant || message.Content != "" || len(message.ToolCalls) == 0 {
+			flush()
+			collapsed = append(collapsed, message)
+			continue
+		}
+		for _, toolCall := range message.ToolCalls {
+			if toolCall.Function != nil {
+				tools = append(tools, toolCall.Function.Name)
+			}
+		}
+	}
+	flush()
+	return collapsed
+}
+
+// TruncateLongMessages returns a truncation policy cutting the content of assistant messages
+// to maxChars characters, noting how many characters were cut.
+func TruncateLongMessages(maxChars int) Normalizer {
+	return func(conversation []Message) []Message {
+		truncated := make([]Message, len(conversation))
+		for i, message := range conversation {
+			if message.Role == MessageRoleAssistant {
+				if length := utf8.RuneCountInString(message.Content); length > maxChars {
+					runes := []rune(message.Content)
+					message.Content = fmt.Sprintf("%s… [%d characters truncated]", string(runes[:maxChars]), length-maxChars)
+				}
+			}
+			truncated[i] = message
+		}
+		return truncated
+	}
+}
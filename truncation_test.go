@@ -0,0 +1,101 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeepLastAgentMessages(t *testing.T) {
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, Content: "thinking 1"},
+		{Role: MessageRoleAssistant, Content: "thinking 2"},
+		{Role: MessageRoleAssistant, Content: "it ships tomorrow"},
+		{Role: MessageRoleUser, Content: "thanks"},
+		{Role: MessageRoleAssistant, Content: "you're welcome"},
+	}
+
+	assert.Equal(t, []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, Content: "[2 intermediate messages omitted]"},
+		{Role: MessageRoleAssistant, Content: "it ships tomorrow"},
+		{Role: MessageRoleUser, Content: "thanks"},
+		{Role: MessageRoleAssistant, Content: "you're welcome"},
+	}, KeepLastAgentMessages(1)(conversation))
+	assert.Equal(t, conversation, KeepLastAgentMessages(3)(conversation))
+}
+
+func TestCollapseToolCalls(t *testing.T) {
+	lookup := ToolCall{ID: "call_1", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "lookup_order"}}
+	track := ToolCall{ID: "call_2", Type: ToolTypeFunction, Function: &ToolCallFunction{Name: "track_parcel"}}
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{lookup}},
+		{Role: MessageRoleTool, Content: `{"status": "shipped"}`, ToolCallID: "call_1"},
+		{Role: MessageRoleAssistant, ToolCalls: []ToolCall{track, lookup}},
+		{Role: MessageRoleAssistant, Content: "it ships tomorrow", ToolCalls: []ToolCall{track}},
+	}
+
+	assert.Equal(t, []Message{
+		{Role: MessageRoleUser, Content: "where is my order?"},
+		{Role: MessageRoleAssistant, Content: "[called tools: lookup_order, track_parcel, lookup_order]"},
+		{Role: MessageRoleAssistant, Content: "it ships tomorrow", ToolCalls: []ToolCall{track}},
+	}, CollapseToolCalls(conversation))
+}
+
+func TestTruncateLongMessages(t *testing.T) {
+	conversation := []Message{
+		{Role: MessageRoleUser, Content: "a long question from the user"},
+		{Role: MessageRoleAssistant, Content: "héllo world"},
+		{Role: MessageRoleAssistant, Content: "ok"},
+	}
+
+	assert.Equal(t, []Message{
+		{Role: MessageRoleUser, Content: "a long question from the user"},
+		{Role: MessageRoleAssistant, Content: "héllo… [6 characters truncated]"},
+		{Role: MessageRoleAssistant, Content: "ok"},
+	}, TruncateLongMessages(5)(conversation))
+}
+
+func TestScenario_Run_WithTruncation(t *testing.T) {
+	ctx := context.Background()
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			return []Message{
+				{Role: MessageRoleAssistant, Content: "step 1"},
+				{Role: MessageRoleAssistant, Content: "step 2"},
+				{Role: MessageRoleAssistant, Content: "done"},
+			}, nil
+		},
+	}
+	var judged []Message
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "go"
+				return &msg, nil, nil
+			}
+			judged = conversation
+			result := NewSuccessPartialResult(conversation, "done", nil)
+			result.Evidence = map[string][]int{"done": {2}}
+			return nil, result, nil
+		},
+	}
+
+	s := NewScenario(
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithTruncation(KeepLastAgentMessages(1)),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	assert.Len(t, judged, 3)
+	assert.Len(t, result.Conversation, 4, "the full conversation is kept")
+	assert.Equal(t, judged, result.JudgedConversation)
+	assert.Equal(t, "done", result.JudgedMessages()[result.Evidence["done"][0]].Content)
+}
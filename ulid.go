@@ -0,0 +1,37 @@
+package scenario
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// crockfordBase32 is the alphabet of ULIDs.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID for the given time: 26 characters of Crockford base32 encoding a 48-bit
+// millisecond timestamp followed by 80 random bits, so IDs sort by creation time.
+func newULID(t time.Time) string {
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := range 6 {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	_, _ = rand.Read(id[6:])
+
+	// 128 bits are encoded as 26 characters of 5 bits, the first one holding only 3 bits
+	var encoded [26]byte
+	for i := range encoded {
+		bit := 128 - 5*(26-i)
+		var value byte
+		for b := range 5 {
+			if bit+b < 0 {
+				continue
+			}
+			value <<= 1
+			value |= id[(bit+b)/8] >> (7 - (bit+b)%8) & 1
+		}
+		encoded[i] = crockfordBase32[value]
+	}
+
+	return string(encoded[:])
+}
@@ -0,0 +1,41 @@
+package scenario
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewULID(t *testing.T) {
+	at := time.UnixMilli(1469918176385)
+
+	id := newULID(at)
+
+	assert.Len(t, id, 26)
+	assert.Equal(t, "01ARYZ6S41", id[:10], "the timestamp is encoded in the first 10 characters")
+	assert.Regexp(t, `^[0-9A-HJKMNP-TV-Z]{26}$`, id)
+	assert.NotEqual(t, id, newULID(at))
+	assert.Less(t, id, newULID(at.Add(time.Millisecond)))
+}
+
+func TestScenario_Run_RunID(t *testing.T) {
+	ctx := context.Background()
+	s := NewScenario(
+		WithID("billing/refund-happy-path"),
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(&mockTestingAgent{}),
+	)
+
+	first, err := s.Run(ctx)
+	require.NoError(t, err)
+	second, err := s.Run(ctx)
+	require.NoError(t, err)
+
+	assert.Len(t, first.RunID, 26)
+	assert.NotEqual(t, first.RunID, second.RunID)
+	assert.Equal(t, filepath.Join("artifacts", "billing", "refund-happy-path", first.RunID), first.RunDir("artifacts"))
+}
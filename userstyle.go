@@ -0,0 +1,74 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UserRegister is how the simulated user writes.
+type UserRegister string
+
+const (
+	// RegisterSearchQuery is the default register, very short lowercase inputs as typed in a
+	// search engine or a chatbot.
+	RegisterSearchQuery UserRegister = "search_query"
+
+	// RegisterCasual is relaxed, conversational writing with full sentences.
+	RegisterCasual UserRegister = "casual"
+
+	// RegisterFormal is polite, professional writing, as in a B2B or enterprise context.
+	RegisterFormal UserRegister = "formal"
+
+	// RegisterTerse is the bare minimum of words, without greetings or pleasantries.
+	RegisterTerse UserRegister = "terse"
+
+	// RegisterVerbose is long messages with context, background and asides.
+	RegisterVerbose UserRegister = "verbose"
+)
+
+// registerInstructions are the instructions given to the testing agent for each register.
+var registerInstructions = map[UserRegister]string{
+	RegisterSearchQuery: "Approach this naturally, as a human user would, with very short inputs, few words, all lowercase, imperative, not periods, like when they google or talk to chatgpt.",
+	RegisterCasual:      "Approach this naturally, as a human user would, writing relaxed, conversational full sentences.",
+	RegisterFormal:      "Approach this as a professional user would, writing polite, formal, well punctuated sentences, as in an email to a business partner.",
+	RegisterTerse:       "Approach this as a busy user would, using the bare minimum of words, without greetings or pleasantries.",
+	RegisterVerbose:     "Approach this as a talkative user would, writing long messages that give context, background and the occasional aside.",
+}
+
+// UserStyle configures how the simulated user writes.
+type UserStyle struct {
+	// Register is how the user writes, RegisterSearchQuery when empty.
+	Register UserRegister
+
+	// Dialect is the regional variety or slang the user writes in, e.g. "British English" or
+	// "Australian slang".
+	Dialect string
+
+	// TypoRate is the share of words, from 0 to 1, the user misspells.
+	TypoRate float64
+}
+
+// instructions returns the instructions given to the testing agent for the style.
+func (s UserStyle) instructions() (string, error) {
+	register := s.Register
+	if register == "" {
+		register = RegisterSearchQuery
+	}
+	instructions, ok := registerInstructions[register]
+	if !ok {
+		return "", fmt.Errorf("unknown user register %q", register)
+	}
+	if s.TypoRate < 0 || s.TypoRate > 1 {
+		return "", fmt.Errorf("typo rate %v is not between 0 and 1", s.TypoRate)
+	}
+
+	lines := []string{instructions}
+	if s.Dialect != "" {
+		lines = append(lines, fmt.Sprintf("Write in %s.", s.Dialect))
+	}
+	if s.TypoRate > 0 {
+		lines = append(lines, fmt.Sprintf("Make typos as a real user would, misspelling about %.0f%% of your words.", s.TypoRate*100))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,26 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserStyle_Instructions(t *testing.T) {
+	instructions, err := UserStyle{}.instructions()
+	require.NoError(t, err)
+	assert.Equal(t, registerInstructions[RegisterSearchQuery], instructions)
+
+	instructions, err = UserStyle{Register: RegisterFormal, Dialect: "British English", TypoRate: 0.05}.instructions()
+	require.NoError(t, err)
+	assert.Equal(t, registerInstructions[RegisterFormal]+"\n"+
+		"Write in British English.\n"+
+		"Make typos as a real user would, misspelling about 5% of your words.", instructions)
+
+	_, err = UserStyle{Register: "shouting"}.instructions()
+	assert.EqualError(t, err, `unknown user register "shouting"`)
+
+	_, err = UserStyle{TypoRate: 2}.instructions()
+	assert.EqualError(t, err, "typo rate 2 is not between 0 and 1")
+}
@@ -0,0 +1,153 @@
+package scenario
+
+// VerdictSchema configures the tool the testing agent calls to give its final verdict.
+type VerdictSchema struct {
+	// ToolName is the name of the verdict tool.
+	ToolName string
+
+	// Strict is whether the verdict tool is strict, some proxies reject strict tools.
+	Strict bool
+
+	// SuccessVerdict is the verdict enum value for a successful test.
+	SuccessVerdict string
+
+	// FailureVerdict is the verdict enum value for a failed test.
+	FailureVerdict string
+
+	// InconclusiveVerdict is the verdict enum value for an inconclusive test.
+	InconclusiveVerdict string
+
+	// Parameters builds the JSON schema of the verdict tool parameters from the verdict enum values.
+	Parameters func(verdicts []string) map[string]any
+}
+
+var (
+	// SchemaPresetOpenAIStrict is the default verdict schema, a strict tool with nested
+	// criteria details, as supported by OpenAI structured outputs.
+	SchemaPresetOpenAIStrict = VerdictSchema{
+		ToolName:            "finish_test",
+		Strict:              true,
+		SuccessVerdict:      "success",
+		FailureVerdict:      "failure",
+		InconclusiveVerdict: "inconclusive",
+		Parameters:          strictVerdictParameters,
+	}
+
+	// SchemaPresetLoose is a simplified, non-strict verdict schema with flat criteria
+	// fields, for providers and models that struggle with the strict schema.
+	SchemaPresetLoose = VerdictSchema{
+		ToolName:            "finish_test",
+		Strict:              false,
+		SuccessVerdict:      "success",
+		FailureVerdict:      "failure",
+		InconclusiveVerdict: "inconclusive",
+		Parameters:          looseVerdictParameters,
+	}
+)
+
+// tool returns the verdict tool definition.
+func (v VerdictSchema) tool() Tool {
+	return Tool{
+		Type: ToolTypeFunction,
+		Function: &ToolFunction{
+			Name:        v.ToolName,
+			Description: "Complete the test with a final verdict",
+			Strict:      v.Strict,
+			Parameters:  v.Parameters([]string{v.SuccessVerdict, v.FailureVerdict, v.InconclusiveVerdict}),
+		},
+	}
+}
+
+func strictVerdictParameters(verdicts []string) map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"verdict": map[string]any{
+				"type":        "string",
+				"enum":        verdicts,
+				"description": "The final verdict of the test",
+			},
+			"reasoning": map[string]any{
+				"type":        "string",
+				"description": "Explanation of why this verdict was reached",
+			},
+			"confidence": confidenceProperty(),
+			"details": map[string]any{
+				"type":                 "object",
+				"properties":           criteriaProperties(),
+				"required":             []string{"met_criteria", "unmet_criteria", "triggered_failures", "evidence"},
+				"additionalProperties": false,
+				"description":          "Detailed information about criteria evaluation",
+			},
+		},
+		"required":             []string{"verdict", "reasoning", "confidence", "details"},
+		"additionalProperties": false,
+	}
+}
+
+func looseVerdictParameters(verdicts []string) map[string]any {
+	properties := criteriaProperties()
+	properties["verdict"] = map[string]any{
+		"type":        "string",
+		"enum":        verdicts,
+		"description": "The final verdict of the test",
+	}
+	properties["reasoning"] = map[string]any{
+		"type":        "string",
+		"description": "Explanation of why this verdict was reached",
+	}
+	properties["confidence"] = confidenceProperty()
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"verdict", "reasoning"},
+	}
+}
+
+func criteriaProperties() map[string]any {
+	return map[string]any{
+		"evidence": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"criterion": map[string]any{
+						"type":        "string",
+						"description": "The criterion, as listed in met_criteria, unmet_criteria or triggered_failures",
+					},
+					"message_indices": map[string]any{
+						"type":        "array",
+						"items":       map[string]any{"type": "integer"},
+						"description": "Zero-based indices of the messages of the conversation supporting the evaluation of the criterion",
+					},
+				},
+				"required":             []string{"criterion", "message_indices"},
+				"additionalProperties": false,
+			},
+			"description": "The messages supporting the evaluation of every criterion listed",
+		},
+		"met_criteria": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "List of success criteria that have been met",
+		},
+		"unmet_criteria": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "List of success criteria that have not been met",
+		},
+		"triggered_failures": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "List of failure criteria that have been triggered",
+		},
+	}
+}
+
+func confidenceProperty() map[string]any {
+	return map[string]any{
+		"type":        "number",
+		"description": "Confidence in the verdict, from 0 (a guess on a borderline conversation) to 1 (certain)",
+	}
+}
@@ -0,0 +1,50 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// voteOnVerdict samples the verdict of the testing agent until WithJudgeVotes votes are cast,
+// the given verdict being the first vote, and returns the first verdict of the majority with the
+// verdicts of the minority in DissentingVerdicts. A tie fails the scenario.
+func (s *scenario) voteOnVerdict(ctx context.Context, result *Result) (*Result, error) {
+	if s.judgeVotes <= 1 {
+		return result, nil
+	}
+
+	turn := len(s.turns)
+	verdicts := []*Result{result}
+	for len(verdicts) < s.judgeVotes {
+		_, verdict, err := s.generate(s.judgeContext(ctx, turn, true), s.turnStrategy(turn), s.judgedConversation(), false, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample verdict %d of %d: %w", len(verdicts)+1, s.judgeVotes, err)
+		}
+		if verdict == nil {
+			return nil, errors.New("testing agent did not give a verdict when sampling votes")
+		}
+		verdicts = append(verdicts, verdict)
+	}
+
+	successes := 0
+	for _, verdict := range verdicts {
+		if verdict.Success {
+			successes++
+		}
+	}
+	success := successes*2 > len(verdicts)
+
+	var majority *Result
+	var dissenting []*Result
+	for _, verdict := range verdicts {
+		if verdict.Success == success && majority == nil {
+			majority = verdict
+		} else if verdict.Success != success {
+			dissenting = append(dissenting, verdict)
+		}
+	}
+	majority.DissentingVerdicts = dissenting
+
+	return majority, nil
+}
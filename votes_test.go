@@ -0,0 +1,79 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVotingTestingAgent returns a testing agent sending a first message, then giving the
+// verdicts in order.
+func newVotingTestingAgent(verdicts ...bool) *mockTestingAgent {
+	votes := 0
+	return &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			if firstMessage {
+				msg := "hi"
+				return &msg, nil, nil
+			}
+			if votes == len(verdicts) {
+				return nil, nil, errors.New("judge unavailable")
+			}
+			success := verdicts[votes]
+			votes++
+			reasoning := fmt.Sprintf("vote %d", votes)
+			if success {
+				return nil, NewSuccessPartialResult(conversation, reasoning, []string{"greets"}), nil
+			}
+			return nil, NewFailurePartialResult(conversation, reasoning, nil, []string{"greets"}, nil), nil
+		},
+	}
+}
+
+func TestScenario_Run_JudgeVotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		verdicts   []bool
+		success    bool
+		reasoning  string
+		dissenting []string
+	}{
+		{"unanimous", []bool{true, true, true}, true, "vote 1", nil},
+		{"majority success", []bool{false, true, true}, true, "vote 2", []string{"vote 1"}},
+		{"majority failure", []bool{true, false, false}, false, "vote 2", []string{"vote 1"}},
+		{"tie", []bool{true, false}, false, "vote 2", []string{"vote 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewScenario(
+				WithAgent(&mockAgent{}),
+				WithTestingAgent(newVotingTestingAgent(tt.verdicts...)),
+				WithJudgeVotes(len(tt.verdicts)),
+			).Run(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.success, result.Success)
+			assert.Equal(t, tt.reasoning, result.Reasoning)
+			var dissenting []string
+			for _, verdict := range result.DissentingVerdicts {
+				dissenting = append(dissenting, verdict.Reasoning)
+			}
+			assert.Equal(t, tt.dissenting, dissenting)
+		})
+	}
+}
+
+func TestScenario_Run_JudgeVotesError(t *testing.T) {
+	_, err := NewScenario(
+		WithAgent(&mockAgent{}),
+		WithTestingAgent(newVotingTestingAgent(true, true)),
+		WithJudgeVotes(3),
+	).Run(context.Background())
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "failed to sample verdict 3 of 3")
+}
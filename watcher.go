@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+)
+
+// WatchAction is what a watcher does when a message matches its pattern.
+type WatchAction int
+
+const (
+	// WatchLog logs the matching message with the log package.
+	WatchLog WatchAction = iota
+
+	// WatchTag adds the pattern of the watcher to Result.Tags.
+	WatchTag
+
+	// WatchFail fails the scenario immediately, the watcher being recorded as a triggered failure.
+	WatchFail
+)
+
+// watcher is a pattern evaluated on every message appended to the conversation.
+type watcher struct {
+	pattern *regexp.Regexp
+	action  WatchAction
+}
+
+// String describes the watcher, it is used as the triggered failure of WatchFail watchers.
+func (w watcher) String() string {
+	return fmt.Sprintf("a message matches watcher %q", w.pattern)
+}
+
+// watchAppended evaluates the watchers on the messages appended to the conversation since the
+// last call, returning the watchers failing the scenario.
+func (s *scenario) watchAppended() []string {
+	messages := s.conversation[s.watched:]
+	s.watched = len(s.conversation)
+
+	var failures []string
+	for _, message := range messages {
+		for _, w := range s.watchers {
+			if !w.pattern.MatchString(message.Content) {
+				continue
+			}
+
+			switch w.action {
+			case WatchLog:
+				log.Printf("scenario %s: %s message matches watcher %q: %s", s.scenarioID(), message.Role, w.pattern, message.Content)
+			case WatchTag:
+				if !slices.Contains(s.tags, w.pattern.String()) {
+					s.tags = append(s.tags, w.pattern.String())
+				}
+			case WatchFail:
+				if !slices.Contains(failures, w.String()) {
+					failures = append(failures, w.String())
+				}
+			}
+		}
+	}
+
+	return failures
+}
+
+// watchFailureResult creates the result of a scenario failed by watchers.
+func watchFailureResult(conversation []Message, failures []string) *Result {
+	return NewFailurePartialResult(
+		conversation,
+		"A message matched a fail fast watcher.",
+		[]string{},
+		[]string{},
+		failures,
+	)
+}
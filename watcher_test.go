@@ -0,0 +1,55 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenario_Run_Watchers(t *testing.T) {
+	ctx := context.Background()
+	var output bytes.Buffer
+	writer := log.Writer()
+	log.SetOutput(&output)
+	t.Cleanup(func() { log.SetOutput(writer) })
+
+	turn := 0
+	mockAgentInst := &mockAgent{
+		runFunc: func(ctx context.Context, message string) ([]Message, error) {
+			turn++
+			if turn == 2 {
+				return []Message{{Role: MessageRoleAssistant, Content: "panic: runtime error\ngoroutine 1 [running]"}}, nil
+			}
+			return []Message{{Role: MessageRoleAssistant, Content: "As an AI language model, I recommend a salad."}}, nil
+		},
+	}
+	mockTestingAgentInst := &mockTestingAgent{
+		generateNextMessageFunc: func(ctx context.Context, description string, strategy string, successCriteria []string, failureCriteria []string, conversation []Message, firstMessage bool, lastMessage bool) (*string, *Result, error) {
+			msg := "any recipes"
+			return &msg, nil, nil
+		},
+	}
+
+	s := NewScenario(
+		WithID("watchers"),
+		WithAgent(mockAgentInst),
+		WithTestingAgent(mockTestingAgentInst),
+		WithWatcher(`(?i)as an ai language model`, WatchTag),
+		WithWatcher(`salad`, WatchLog),
+		WithWatcher(`goroutine \d+`, WatchFail),
+	)
+
+	result, err := s.Run(ctx)
+
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{`a message matches watcher "goroutine \\d+"`}, result.TriggeredFailures)
+	assert.Equal(t, []string{`(?i)as an ai language model`}, result.Tags)
+	assert.Len(t, result.Conversation, 4, "the scenario fails as soon as the message is appended")
+	assert.Contains(t, output.String(), `scenario watchers: assistant message matches watcher "salad": As an AI language model, I recommend a salad.`)
+}